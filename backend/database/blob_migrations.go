@@ -0,0 +1,105 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"path"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// legacyUploadPrefix starts every file path recorded before uploads moved to the blob store. The
+// rest of such a path is the object key, so the ./uploads directory works unchanged as the root of
+// the local blob store, or can be copied into a bucket as it is.
+const legacyUploadPrefix = "uploads/"
+
+// blobKeyTables lists the tables whose file_path column became storage_key.
+var blobKeyTables = []interface{}{&models.Attachment{}, &models.SupplierDocument{}}
+
+// RenameFilePathColumns renames the file_path columns of attachments and supplier documents to
+// storage_key and turns the stored paths into blob store keys. It runs before AutoMigrate, which
+// would otherwise add an empty storage_key column next to the old one.
+func RenameFilePathColumns(db *gorm.DB) error {
+	migrator := db.Migrator()
+	for _, model := range blobKeyTables {
+		if !migrator.HasTable(model) || !migrator.HasColumn(model, "file_path") || migrator.HasColumn(model, "storage_key") {
+			continue
+		}
+		if err := migrator.RenameColumn(model, "file_path", "storage_key"); err != nil {
+			return err
+		}
+		err := db.Model(model).Where("storage_key LIKE ?", legacyUploadPrefix+"%").
+			Update("storage_key", gorm.Expr("substr(storage_key, ?)", len(legacyUploadPrefix)+1)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateLegacyBidItemFiles turns bid item files that were saved straight to ./uploads/bids/ into
+// attachments of their bid, pointing the item's URL at /files/{id}. URLs of the older
+// /attachments/{id}/download form are moved to /files/{id} too.
+func MigrateLegacyBidItemFiles(db *gorm.DB) error {
+	for _, column := range []string{"specification_sheet_url", "item_image_url"} {
+		err := db.Model(&models.BidItem{}).Where(column+" LIKE ?", "/attachments/%/download").
+			UpdateColumn(column, gorm.Expr("replace(replace("+column+", '/attachments/', '/files/'), '/download', '')")).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var items []models.BidItem
+	err := db.Where("specification_sheet_url LIKE ? OR item_image_url LIKE ?", legacyUploadPrefix+"%", legacyUploadPrefix+"%").
+		Find(&items).Error
+	if err != nil || len(items) == 0 {
+		return err
+	}
+
+	log.Printf("Migrating files of %d bid items to attachments...", len(items))
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			files := []struct {
+				column       string
+				documentType string
+				url          *string
+			}{
+				{"specification_sheet_url", models.AttachmentTypeSpecificationSheet, item.SpecificationSheetURL},
+				{"item_image_url", models.AttachmentTypeItemImage, item.ItemImageURL},
+			}
+			for _, file := range files {
+				if file.url == nil || !strings.HasPrefix(*file.url, legacyUploadPrefix) {
+					continue
+				}
+				documentType := file.documentType
+				attachment := models.Attachment{
+					EntityType:   models.AttachmentEntityBid,
+					EntityID:     item.BidID,
+					FileName:     path.Base(*file.url),
+					StorageKey:   strings.TrimPrefix(*file.url, legacyUploadPrefix),
+					ContentType:  mime.TypeByExtension(path.Ext(*file.url)),
+					DocumentType: &documentType,
+				}
+				if attachment.ContentType == "" {
+					attachment.ContentType = "application/octet-stream"
+				}
+				if info, err := os.Stat(*file.url); err == nil {
+					attachment.Size = info.Size()
+				}
+				if err := tx.Create(&attachment).Error; err != nil {
+					return err
+				}
+				url := fmt.Sprintf("/files/%d", attachment.ID)
+				if err := tx.Model(&models.BidItem{}).Where("id = ?", item.ID).UpdateColumn(file.column, url).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
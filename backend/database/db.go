@@ -75,13 +75,47 @@ func SetupDatabaseSchema() {
 
 	err := dbInstance.AutoMigrate(
 		&models.User{}, // Re-enabled AutoMigrate for User model
+		&models.Supplier{},
+		&models.SupplierCategory{},
+		&models.SupplierDocument{},
+		&models.SupplierDebarment{},
 		&models.Requisition{},
 		&models.RequisitionItem{},
+		&models.ApprovalChain{},
+		&models.ApprovalChainLevel{},
+		&models.ApprovalStep{},
 		&models.Tender{}, // Add Tender model for auto-migration
+		&models.TenderItem{},
+		&models.TenderEvaluationCriterion{},
+		&models.TenderAddendum{},
+		&models.TenderAddendumAcknowledgement{},
+		&models.TenderInterest{},
+		&models.TenderClarification{},
 		&models.Bid{},
 		&models.BidItem{},
+		&models.TenderSealingKey{},
+		&models.BidOpening{},
+		&models.BidOpeningAttendee{},
+		&models.BidOpeningRegisterEntry{},
+		&models.BidEvaluationResult{},
+		&models.EvaluationPanel{},
+		&models.UserEvaluationPanelMembership{},
+		&models.ConflictOfInterestDeclaration{},
+		&models.EvaluationPanelRecommendation{},
+		&models.EvaluationPanelSignature{},
+		&models.PurchaseOrder{},
+		&models.PurchaseOrderItem{},
+		&models.Delivery{},
+		&models.SupplierRating{},
+		&models.Invoice{},
+		&models.InvoiceItem{},
+		&models.Asset{},
+		&models.Attachment{},
+		&models.NumberSequence{},
 		&models.PasswordReset{}, // Add PasswordReset model for auto-migration
 		&models.Session{},       // Add Session model for auto-migration
+		&models.ScheduledJob{},
+		&models.Report{},
 	)
 	if err != nil {
 		// If models.User was the only thing being migrated and it's commented out,
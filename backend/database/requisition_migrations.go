@@ -0,0 +1,144 @@
+package database
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// legacyApprovalColumns are the fixed two-admin approval columns replaced by approval steps.
+var legacyApprovalColumns = []string{"approver_one_id", "approved_one_at", "approver_two_id", "approved_two_at"}
+
+// legacyRequisition reads a requisition as it was stored before approval chains.
+type legacyRequisition struct {
+	ID            int64
+	Status        string
+	ApproverOneID *int64
+	ApprovedOneAt *time.Time
+	ApproverTwoID *int64
+	ApprovedTwoAt *time.Time
+	UpdatedAt     time.Time
+}
+
+// MigrateRequisitionApprovals moves requisitions onto approval chains. When no chain exists yet it
+// seeds a default chain of two admin levels that matches every requisition, which is what the
+// fixed two-admin approval did. Requisitions stored before chains get approval steps for their
+// recorded approvals, are routed to the default chain at the level they had reached and have their
+// total value worked out; the old approver columns are then dropped. Everything happens in one
+// transaction.
+func MigrateRequisitionApprovals(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		defaultChain, err := seedDefaultApprovalChain(tx)
+		if err != nil {
+			return err
+		}
+
+		if !tx.Migrator().HasColumn(&models.Requisition{}, "approver_one_id") {
+			return nil
+		}
+
+		var requisitions []legacyRequisition
+		if err := tx.Table("requisitions").Find(&requisitions).Error; err != nil {
+			return err
+		}
+		log.Printf("Migrating %d requisitions to approval chains...", len(requisitions))
+		for _, requisition := range requisitions {
+			approvals := []struct {
+				approverID *int64
+				at         *time.Time
+			}{
+				{requisition.ApproverOneID, requisition.ApprovedOneAt},
+				{requisition.ApproverTwoID, requisition.ApprovedTwoAt},
+			}
+			for i, approval := range approvals {
+				if approval.approverID == nil {
+					continue
+				}
+				decidedAt := requisition.UpdatedAt
+				if approval.at != nil {
+					decidedAt = *approval.at
+				}
+				step := models.ApprovalStep{
+					RequisitionID: requisition.ID,
+					Level:         i + 1,
+					ApproverID:    *approval.approverID,
+					Decision:      models.ApprovalDecisionApproved,
+					DecidedAt:     decidedAt.UTC(),
+				}
+				if err := tx.Create(&step).Error; err != nil {
+					return err
+				}
+			}
+
+			updates := map[string]interface{}{"approval_chain_id": defaultChain.ID, "current_approval_level": 0}
+			switch requisition.Status {
+			case "pending_approval_1", "submitted_for_approval":
+				updates["status"] = models.RequisitionStatusPendingApproval
+				updates["current_approval_level"] = 1
+			case "pending_approval_2":
+				updates["status"] = models.RequisitionStatusPendingApproval
+				updates["current_approval_level"] = 2
+			case string(models.RequisitionStatusDraft):
+				delete(updates, "approval_chain_id")
+			}
+			if err := tx.Table("requisitions").Where("id = ?", requisition.ID).UpdateColumns(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Exec(`UPDATE requisitions SET total_value = COALESCE((SELECT SUM(quantity * COALESCE(estimated_unit_price, 0)
+			+ COALESCE(freight_cost, 0) + COALESCE(insurance_cost, 0) + COALESCE(installation_cost, 0))
+			FROM requisition_items WHERE requisition_items.requisition_id = requisitions.id), 0)`).Error; err != nil {
+			return err
+		}
+
+		// Dropped in place rather than through the migrator, which rebuilds the table without its
+		// other indexes. SQLite refuses to drop an indexed column, so its index goes first.
+		for _, column := range legacyApprovalColumns {
+			if err := tx.Exec("DROP INDEX IF EXISTS idx_requisitions_" + column).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("ALTER TABLE requisitions DROP COLUMN " + column).Error; err != nil {
+				return err
+			}
+		}
+		log.Println("Requisition approval migration completed.")
+		return nil
+	})
+}
+
+// seedDefaultApprovalChain returns the lowest-priority chain, creating the default chain when
+// there are none.
+func seedDefaultApprovalChain(tx *gorm.DB) (*models.ApprovalChain, error) {
+	var chain models.ApprovalChain
+	err := tx.Order("priority DESC, id DESC").First(&chain).Error
+	if err == nil {
+		return &chain, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	adminRole := "admin"
+	firstName, secondName := "First approval", "Second approval"
+	description := "Two approvals by different admins; applies when no other chain matches."
+	chain = models.ApprovalChain{
+		Name:        "Default approval",
+		Description: &description,
+		Priority:    1000,
+		IsActive:    true,
+		Levels: []models.ApprovalChainLevel{
+			{Level: 1, Name: &firstName, ApproverRole: &adminRole},
+			{Level: 2, Name: &secondName, ApproverRole: &adminRole},
+		},
+	}
+	if err := tx.Create(&chain).Error; err != nil {
+		return nil, err
+	}
+	log.Printf("Seeded default approval chain %d.", chain.ID)
+	return &chain, nil
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// supplierOwnedTables lists the tables whose supplier_id used to hold the ID of the supplier user
+// and now holds the ID of the supplier organisation.
+var supplierOwnedTables = []string{
+	"bids",
+	"conflict_of_interest_declarations",
+	"purchase_orders",
+	"invoices",
+	"assets",
+}
+
+// MigrateSupplierOrganisations moves existing supplier users onto supplier organisations.
+// It only runs while the suppliers table is still empty: every supplier user gets an organisation
+// named after them, and supplier_id columns that held user IDs are rewritten to the new
+// organisation IDs. Everything happens in one transaction.
+func MigrateSupplierOrganisations(db *gorm.DB) error {
+	var organisations int64
+	if err := db.Model(&models.Supplier{}).Count(&organisations).Error; err != nil {
+		return err
+	}
+	if organisations > 0 {
+		return nil
+	}
+
+	var supplierUsers []models.User
+	if err := db.Where("LOWER(role) = ? AND supplier_id IS NULL", "supplier").Order("id ASC").Find(&supplierUsers).Error; err != nil {
+		return err
+	}
+	if len(supplierUsers) == 0 {
+		return nil
+	}
+
+	log.Printf("Migrating %d supplier users to supplier organisations...", len(supplierUsers))
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, user := range supplierUsers {
+			email := user.Email
+			supplier := models.Supplier{
+				Name:          user.Username,
+				ContactPerson: &user.Username,
+				Email:         &email,
+				Phone:         user.ContactNumber,
+				Status:        models.SupplierStatusActive,
+			}
+			if err := tx.Create(&supplier).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("supplier_id", supplier.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		// Each table is rewritten in a single statement so a new organisation ID that happens to
+		// equal another user's ID is never remapped twice.
+		for _, table := range supplierOwnedTables {
+			if err := tx.Exec(`UPDATE ` + table + ` SET supplier_id = (SELECT users.supplier_id FROM users WHERE users.id = ` + table + `.supplier_id)
+				WHERE supplier_id IN (SELECT id FROM users WHERE supplier_id IS NOT NULL)`).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec(`UPDATE bids SET submitted_by_id = (SELECT users.id FROM users WHERE users.supplier_id = bids.supplier_id ORDER BY users.id LIMIT 1)
+			WHERE submitted_by_id IS NULL`).Error; err != nil {
+			return err
+		}
+		log.Println("Supplier organisation migration completed.")
+		return nil
+	})
+}
@@ -0,0 +1,484 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// approvalChainRoles are the roles a chain level may name as its approvers. Requesters are left
+// out, since a requester would then approve their own colleagues' requisitions by role alone.
+var approvalChainRoles = []string{"admin", "approver", "procurement_officer", "evaluator"}
+
+// errApprovalChainInUse stops a change to the levels of a chain with requisitions awaiting approval.
+var errApprovalChainInUse = errors.New("approval chain has requisitions awaiting approval")
+
+// ApprovalChainHandler holds dependencies for managing requisition approval chains.
+type ApprovalChainHandler struct {
+	DB *gorm.DB
+}
+
+// NewApprovalChainHandler creates a new ApprovalChainHandler with the given DB connection.
+func NewApprovalChainHandler(db *gorm.DB) *ApprovalChainHandler {
+	return &ApprovalChainHandler{DB: db}
+}
+
+// ApprovalChainLevelInput is one level of an approval chain in a request body. Levels are
+// numbered in the order given.
+type ApprovalChainLevelInput struct {
+	Name            *string `json:"name"`
+	ApproverRole    *string `json:"approver_role"`
+	ApproverUserIDs []int64 `json:"approver_user_ids"`
+}
+
+// ApprovalChainInput is the request body for creating or replacing an approval chain.
+type ApprovalChainInput struct {
+	Name            string                    `json:"name"`
+	Description     *string                   `json:"description"`
+	Priority        *int                      `json:"priority"`
+	RequisitionType *string                   `json:"requisition_type"`
+	Department      *string                   `json:"department"`
+	MinTotalValue   *float64                  `json:"min_total_value"`
+	MaxTotalValue   *float64                  `json:"max_total_value"`
+	IsActive        *bool                     `json:"is_active"`
+	Levels          []ApprovalChainLevelInput `json:"levels"`
+}
+
+// toChain validates the input and builds the chain it describes. Named approvers must be
+// existing users.
+func (input *ApprovalChainInput) toChain(db *gorm.DB) (*models.ApprovalChain, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if input.MinTotalValue != nil && *input.MinTotalValue < 0 {
+		return nil, fmt.Errorf("min_total_value cannot be negative")
+	}
+	if input.MinTotalValue != nil && input.MaxTotalValue != nil && *input.MaxTotalValue <= *input.MinTotalValue {
+		return nil, fmt.Errorf("max_total_value must be greater than min_total_value")
+	}
+	if len(input.Levels) == 0 {
+		return nil, fmt.Errorf("at least one level is required")
+	}
+
+	chain := &models.ApprovalChain{
+		Name:            name,
+		Description:     trimmedOrNil(input.Description),
+		Priority:        100,
+		RequisitionType: trimmedOrNil(input.RequisitionType),
+		Department:      trimmedOrNil(input.Department),
+		MinTotalValue:   input.MinTotalValue,
+		MaxTotalValue:   input.MaxTotalValue,
+		IsActive:        true,
+	}
+	if input.Priority != nil {
+		chain.Priority = *input.Priority
+	}
+	if input.IsActive != nil {
+		chain.IsActive = *input.IsActive
+	}
+
+	for i, levelInput := range input.Levels {
+		level := models.ApprovalChainLevel{Level: i + 1, Name: trimmedOrNil(levelInput.Name)}
+		if role := trimmedOrNil(levelInput.ApproverRole); role != nil {
+			if !hasRole(&models.User{Role: *role}, approvalChainRoles...) {
+				return nil, fmt.Errorf("level %d: approver_role must be one of %s", level.Level, strings.Join(approvalChainRoles, ", "))
+			}
+			lowered := strings.ToLower(*role)
+			level.ApproverRole = &lowered
+		}
+		seen := map[int64]bool{}
+		for _, userID := range levelInput.ApproverUserIDs {
+			if !seen[userID] {
+				seen[userID] = true
+				level.ApproverUserIDs = append(level.ApproverUserIDs, userID)
+			}
+		}
+		if level.ApproverRole == nil && len(level.ApproverUserIDs) == 0 {
+			return nil, fmt.Errorf("level %d: an approver_role or approver_user_ids is required", level.Level)
+		}
+		if len(level.ApproverUserIDs) > 0 {
+			var found int64
+			if err := db.Model(&models.User{}).Where("id IN ? AND LOWER(role) <> ?", level.ApproverUserIDs, "supplier").Count(&found).Error; err != nil {
+				return nil, err
+			}
+			if found != int64(len(level.ApproverUserIDs)) {
+				return nil, fmt.Errorf("level %d: approver_user_ids must name existing staff users", level.Level)
+			}
+		}
+		chain.Levels = append(chain.Levels, level)
+	}
+	return chain, nil
+}
+
+// requireAdmin makes sure the current user is an admin. It writes the error response itself.
+func (h *ApprovalChainHandler) requireAdmin(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return nil, false
+	}
+	if !hasRole(currentUser, "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only admins can manage approval chains.")
+		return nil, false
+	}
+	return currentUser, true
+}
+
+// levelsByNumber orders the levels of a preloaded chain.
+func levelsByNumber(db *gorm.DB) *gorm.DB {
+	return db.Order("level ASC")
+}
+
+// ListApprovalChains returns every approval chain with its levels, in the order requisitions are
+// routed to them.
+// GET /api/approval-chains
+func (h *ApprovalChainHandler) ListApprovalChains(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	var chains []models.ApprovalChain
+	if err := h.DB.Preload("Levels", levelsByNumber).Order("priority ASC, id ASC").Find(&chains).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve approval chains: "+err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, chains)
+}
+
+// GetApprovalChain returns an approval chain with its levels.
+// GET /api/approval-chains/{id}
+func (h *ApprovalChainHandler) GetApprovalChain(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	chainID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	var chain models.ApprovalChain
+	if err := h.DB.Preload("Levels", levelsByNumber).First(&chain, chainID).Error; err != nil {
+		respondWithLookupError(w, err, "Approval chain")
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, chain)
+}
+
+// CreateApprovalChain adds an approval chain. Priority defaults to 100 and lower priorities are
+// tried first; routing rules left unset match every requisition.
+// POST /api/approval-chains
+func (h *ApprovalChainHandler) CreateApprovalChain(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	var input ApprovalChainInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	chain, err := input.toChain(h.DB)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid approval chain: "+err.Error())
+		return
+	}
+
+	// IsActive has no column default, so a chain created inactive stays inactive
+	if err := h.DB.Create(chain).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to create approval chain: "+err.Error())
+		return
+	}
+	log.Printf("ApprovalChain: ChainID %d '%s' with %d levels created by UserID %d", chain.ID, chain.Name, len(chain.Levels), currentUser.ID)
+	RespondWithJSON(w, http.StatusCreated, chain)
+}
+
+// UpdateApprovalChain replaces an approval chain's rules and levels. Requisitions already routed
+// to the chain keep it; its levels cannot change while any of them await approval.
+// PUT /api/approval-chains/{id}
+func (h *ApprovalChainHandler) UpdateApprovalChain(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	chainID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	var existing models.ApprovalChain
+	if err := h.DB.Preload("Levels", levelsByNumber).First(&existing, chainID).Error; err != nil {
+		respondWithLookupError(w, err, "Approval chain")
+		return
+	}
+	var input ApprovalChainInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	chain, err := input.toChain(h.DB)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid approval chain: "+err.Error())
+		return
+	}
+	chain.ID = existing.ID
+	chain.CreatedAt = existing.CreatedAt
+
+	levelsChanged := !sameApprovalLevels(existing.Levels, chain.Levels)
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if levelsChanged {
+			var pending int64
+			if err := tx.Model(&models.Requisition{}).Where("approval_chain_id = ? AND status = ?", chain.ID, models.RequisitionStatusPendingApproval).Count(&pending).Error; err != nil {
+				return err
+			}
+			if pending > 0 {
+				return errApprovalChainInUse
+			}
+			if err := tx.Where("chain_id = ?", chain.ID).Delete(&models.ApprovalChainLevel{}).Error; err != nil {
+				return err
+			}
+			for i := range chain.Levels {
+				chain.Levels[i].ChainID = chain.ID
+				if err := tx.Create(&chain.Levels[i]).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Omit("Levels").Save(chain).Error
+	})
+	if errors.Is(err, errApprovalChainInUse) {
+		RespondWithError(w, http.StatusConflict, "The levels of this chain cannot change while requisitions await approval on it. Create a new chain or deactivate this one instead.")
+		return
+	}
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update approval chain: "+err.Error())
+		return
+	}
+
+	if err := h.DB.Preload("Levels", levelsByNumber).First(chain, chain.ID).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Approval chain updated but could not be reloaded: "+err.Error())
+		return
+	}
+	log.Printf("ApprovalChain: ChainID %d updated by UserID %d", chain.ID, currentUser.ID)
+	RespondWithJSON(w, http.StatusOK, chain)
+}
+
+// sameApprovalLevels reports whether two ordered sets of levels are identical.
+func sameApprovalLevels(a, b []models.ApprovalChainLevel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, _ := json.Marshal(models.ApprovalChainLevel{Level: a[i].Level, Name: a[i].Name, ApproverRole: a[i].ApproverRole, ApproverUserIDs: a[i].ApproverUserIDs})
+		y, _ := json.Marshal(models.ApprovalChainLevel{Level: b[i].Level, Name: b[i].Name, ApproverRole: b[i].ApproverRole, ApproverUserIDs: b[i].ApproverUserIDs})
+		if string(x) != string(y) {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteApprovalChain removes an approval chain no requisition has been routed to. Chains with
+// history are deactivated instead.
+// DELETE /api/approval-chains/{id}
+func (h *ApprovalChainHandler) DeleteApprovalChain(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	chainID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	var chain models.ApprovalChain
+	if err := h.DB.First(&chain, chainID).Error; err != nil {
+		respondWithLookupError(w, err, "Approval chain")
+		return
+	}
+	var routed int64
+	if err := h.DB.Model(&models.Requisition{}).Where("approval_chain_id = ?", chain.ID).Count(&routed).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check approval chain usage: "+err.Error())
+		return
+	}
+	if routed > 0 {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Approval chain has been used by %d requisitions; deactivate it instead.", routed))
+		return
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("chain_id = ?", chain.ID).Delete(&models.ApprovalChainLevel{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&chain).Error
+	})
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to delete approval chain: "+err.Error())
+		return
+	}
+	log.Printf("ApprovalChain: ChainID %d deleted by UserID %d", chain.ID, currentUser.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requisitionTotalValue works out the estimated value of a requisition's items: quantity times
+// estimated unit price plus freight, insurance and installation.
+func requisitionTotalValue(items []models.RequisitionItem) float64 {
+	total := 0.0
+	for _, item := range items {
+		if item.EstimatedUnitPrice != nil {
+			total += item.Quantity * *item.EstimatedUnitPrice
+		}
+		for _, cost := range []*float64{item.FreightCost, item.InsuranceCost, item.InstallationCost} {
+			if cost != nil {
+				total += *cost
+			}
+		}
+	}
+	return total
+}
+
+// chainMatchesRequisition reports whether a chain's routing rules match a requisition.
+func chainMatchesRequisition(chain *models.ApprovalChain, requisition *models.Requisition) bool {
+	if chain.RequisitionType != nil && !strings.EqualFold(*chain.RequisitionType, requisition.Type) {
+		return false
+	}
+	if chain.Department != nil && (requisition.Department == nil || !strings.EqualFold(*chain.Department, strings.TrimSpace(*requisition.Department))) {
+		return false
+	}
+	if chain.MinTotalValue != nil && requisition.TotalValue < *chain.MinTotalValue {
+		return false
+	}
+	if chain.MaxTotalValue != nil && requisition.TotalValue >= *chain.MaxTotalValue {
+		return false
+	}
+	return true
+}
+
+// routeRequisition picks the approval chain for a requisition: the first active chain with levels,
+// by priority, whose rules match its type, department and total value. It returns nil when no
+// chain applies.
+func routeRequisition(db *gorm.DB, requisition *models.Requisition) (*models.ApprovalChain, error) {
+	var chains []models.ApprovalChain
+	if err := db.Preload("Levels", levelsByNumber).Where("is_active = ?", true).Order("priority ASC, id ASC").Find(&chains).Error; err != nil {
+		return nil, err
+	}
+	for i := range chains {
+		if len(chains[i].Levels) > 0 && chainMatchesRequisition(&chains[i], requisition) {
+			return &chains[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// pendingApprovalLevel returns the chain level awaiting a decision on a requisition, or nil when
+// the requisition is not pending approval.
+func pendingApprovalLevel(db *gorm.DB, requisition *models.Requisition) (*models.ApprovalChainLevel, error) {
+	if requisition.Status != models.RequisitionStatusPendingApproval || requisition.ApprovalChainID == nil {
+		return nil, nil
+	}
+	var level models.ApprovalChainLevel
+	err := db.Where("chain_id = ? AND level = ?", *requisition.ApprovalChainID, requisition.CurrentApprovalLevel).First(&level).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &level, nil
+}
+
+// checkApprover reports why a user may not decide a requisition at the given level, or "" when
+// they may. Any user with the level's role or named on the level may decide, but nobody decides
+// their own requisition or approves more than one level of the same requisition.
+func checkApprover(db *gorm.DB, user *models.User, requisition *models.Requisition, level *models.ApprovalChainLevel) (string, error) {
+	if requisition.UserID == user.ID {
+		return "You cannot approve or reject your own requisition.", nil
+	}
+	if !levelApprover(user, level) {
+		return fmt.Sprintf("You are not an approver for level %d of this requisition.", level.Level), nil
+	}
+
+	var approvedBefore int64
+	err := db.Model(&models.ApprovalStep{}).
+		Where("requisition_id = ? AND approver_id = ? AND decision = ?", requisition.ID, user.ID, models.ApprovalDecisionApproved).
+		Count(&approvedBefore).Error
+	if err != nil {
+		return "", err
+	}
+	if approvedBefore > 0 {
+		return "You have already approved an earlier level of this requisition; another approver must decide this level.", nil
+	}
+	return "", nil
+}
+
+// levelApprover reports whether a user has the level's role or is named on it.
+func levelApprover(user *models.User, level *models.ApprovalChainLevel) bool {
+	if level.ApproverRole != nil && hasRole(user, *level.ApproverRole) {
+		return true
+	}
+	for _, userID := range level.ApproverUserIDs {
+		if userID == user.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// requisitionsAwaitingDecision returns the IDs of the requisitions a user may approve or reject
+// now. It applies the same rules as checkApprover to every pending requisition, using one query
+// each for the requisitions, their chains' levels and the user's earlier approvals.
+func requisitionsAwaitingDecision(db *gorm.DB, user *models.User) (map[int64]bool, error) {
+	var pending []models.Requisition
+	err := db.Select("id", "user_id", "approval_chain_id", "current_approval_level").
+		Where("status = ? AND approval_chain_id IS NOT NULL AND user_id <> ?", models.RequisitionStatusPendingApproval, user.ID).
+		Find(&pending).Error
+	if err != nil || len(pending) == 0 {
+		return map[int64]bool{}, err
+	}
+
+	chainIDs := make([]int64, 0, len(pending))
+	for _, requisition := range pending {
+		chainIDs = append(chainIDs, *requisition.ApprovalChainID)
+	}
+	var levels []models.ApprovalChainLevel
+	if err := db.Where("chain_id IN ?", chainIDs).Find(&levels).Error; err != nil {
+		return nil, err
+	}
+	type levelKey struct{ chainID, level int64 }
+	levelsByKey := make(map[levelKey]*models.ApprovalChainLevel, len(levels))
+	for i := range levels {
+		levelsByKey[levelKey{levels[i].ChainID, int64(levels[i].Level)}] = &levels[i]
+	}
+
+	var approvedIDs []int64
+	err = db.Model(&models.ApprovalStep{}).
+		Where("approver_id = ? AND decision = ?", user.ID, models.ApprovalDecisionApproved).
+		Distinct().Pluck("requisition_id", &approvedIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	approvedBefore := make(map[int64]bool, len(approvedIDs))
+	for _, id := range approvedIDs {
+		approvedBefore[id] = true
+	}
+
+	awaiting := map[int64]bool{}
+	for _, requisition := range pending {
+		level := levelsByKey[levelKey{*requisition.ApprovalChainID, int64(requisition.CurrentApprovalLevel)}]
+		if level != nil && !approvedBefore[requisition.ID] && levelApprover(user, level) {
+			awaiting[requisition.ID] = true
+		}
+	}
+	return awaiting, nil
+}
+
+// canDecideRequisition reports whether a user may approve or reject a requisition now.
+func canDecideRequisition(db *gorm.DB, user *models.User, requisition *models.Requisition) (bool, error) {
+	level, err := pendingApprovalLevel(db, requisition)
+	if err != nil || level == nil {
+		return false, err
+	}
+	denial, err := checkApprover(db, user, requisition, level)
+	return err == nil && denial == "", err
+}
@@ -0,0 +1,502 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+	"procurement/services"
+)
+
+// AssetHandler holds dependencies for asset register handlers.
+type AssetHandler struct {
+	DB *gorm.DB
+}
+
+// NewAssetHandler creates a new AssetHandler with the given DB connection.
+func NewAssetHandler(db *gorm.DB) *AssetHandler {
+	return &AssetHandler{DB: db}
+}
+
+// assetDate is a date in an asset request body. It accepts the plain YYYY-MM-DD sent by date
+// inputs as well as an RFC 3339 timestamp; an empty string clears the date.
+type assetDate struct {
+	time *time.Time
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *assetDate) UnmarshalJSON(data []byte) error {
+	var value *string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("date must be a string: %w", err)
+	}
+	d.time = nil
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	parsed, err := time.Parse("2006-01-02", trimmed)
+	if err != nil {
+		if parsed, err = time.Parse(time.RFC3339, trimmed); err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", trimmed)
+		}
+	}
+	d.time = &parsed
+	return nil
+}
+
+// assetInput is the request body for creating or updating an asset.
+type assetInput struct {
+	models.Asset
+	PurchaseDate assetDate `json:"purchase_date"`
+}
+
+// decodeAssetInput reads an asset from a request body.
+func decodeAssetInput(r *http.Request) (models.Asset, error) {
+	var input assetInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		return models.Asset{}, err
+	}
+	asset := input.Asset
+	asset.PurchaseDate = input.PurchaseDate.time
+	return asset, nil
+}
+
+// validateAsset normalises and checks the register and depreciation fields of an asset.
+func validateAsset(asset *models.Asset) error {
+	asset.AmrID = strings.TrimSpace(asset.AmrID)
+	if asset.AmrID == "" {
+		return fmt.Errorf("amr_id is required")
+	}
+	if asset.EmrID != nil && strings.TrimSpace(*asset.EmrID) == "" {
+		asset.EmrID = nil
+	}
+	if strings.TrimSpace(asset.Description) == "" {
+		return fmt.Errorf("description is required")
+	}
+
+	asset.Status = strings.ToLower(strings.TrimSpace(asset.Status))
+	switch asset.Status {
+	case "":
+		asset.Status = models.AssetStatusActive
+	case models.AssetStatusActive, models.AssetStatusInactive, models.AssetStatusMaintenance, models.AssetStatusDisposed:
+	default:
+		return fmt.Errorf("status must be one of 'active', 'inactive', 'maintenance' or 'disposed'")
+	}
+
+	if asset.PurchasePrice != nil && *asset.PurchasePrice < 0 {
+		return fmt.Errorf("purchase_price cannot be negative")
+	}
+	if asset.CapitalizedValue != nil && *asset.CapitalizedValue < 0 {
+		return fmt.Errorf("capitalized_value cannot be negative")
+	}
+	if asset.DepreciationUsefulLife != nil && *asset.DepreciationUsefulLife <= 0 {
+		return fmt.Errorf("depreciation_useful_life must be a positive number of years")
+	}
+	if asset.DepreciationAnnualRate != nil && (*asset.DepreciationAnnualRate <= 0 || *asset.DepreciationAnnualRate > 100) {
+		return fmt.Errorf("depreciation_annual_rate must be a percentage between 0 and 100")
+	}
+	if asset.DepreciationMethod != nil {
+		method := strings.ToLower(strings.TrimSpace(*asset.DepreciationMethod))
+		switch method {
+		case "":
+			asset.DepreciationMethod = nil
+		case models.DepreciationMethodStraightLine:
+			if asset.DepreciationUsefulLife == nil {
+				return fmt.Errorf("straight-line depreciation needs depreciation_useful_life")
+			}
+		case models.DepreciationMethodReducingBalance:
+			if asset.DepreciationAnnualRate == nil {
+				return fmt.Errorf("reducing-balance depreciation needs depreciation_annual_rate")
+			}
+		default:
+			return fmt.Errorf("depreciation_method must be 'straight_line' or 'reducing_balance'")
+		}
+		if asset.DepreciationMethod != nil {
+			asset.DepreciationMethod = &method
+		}
+	}
+	return nil
+}
+
+// resolveAssetLinks checks the procurement records an asset points at. When a GRN is given the
+// purchase order and requisition are taken from it, and the purchase date, price and supplier
+// default to what was ordered and received. It returns the requisition item the asset was
+// received against, if any, and writes the error response itself on failure.
+func (h *AssetHandler) resolveAssetLinks(w http.ResponseWriter, asset *models.Asset) (*int64, bool) {
+	lookup := func(dest interface{}, id int64, entity string) bool {
+		if err := h.DB.First(dest, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Linked %s %d not found.", entity, id))
+			} else {
+				RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve linked "+entity+": "+err.Error())
+			}
+			return false
+		}
+		return true
+	}
+
+	var requisitionItemID *int64
+	if asset.DeliveryID != nil {
+		var delivery models.Delivery
+		if !lookup(&delivery, *asset.DeliveryID, "GRN") {
+			return nil, false
+		}
+		if asset.PurchaseOrderID != nil && *asset.PurchaseOrderID != delivery.PurchaseOrderID {
+			RespondWithError(w, http.StatusBadRequest, "The GRN does not belong to the given purchase order.")
+			return nil, false
+		}
+		asset.PurchaseOrderID = &delivery.PurchaseOrderID
+		if asset.PurchaseDate == nil {
+			asset.PurchaseDate = &delivery.DeliveryDate
+		}
+
+		if delivery.PurchaseOrderItemID != nil {
+			var orderItem models.PurchaseOrderItem
+			if !lookup(&orderItem, *delivery.PurchaseOrderItemID, "purchase order item") {
+				return nil, false
+			}
+			if asset.PurchasePrice == nil {
+				asset.PurchasePrice = &orderItem.UnitPrice
+			}
+			if orderItem.RequisitionItemID != nil {
+				var reqItem models.RequisitionItem
+				if !lookup(&reqItem, *orderItem.RequisitionItemID, "requisition item") {
+					return nil, false
+				}
+				if asset.RequisitionID != nil && *asset.RequisitionID != reqItem.RequisitionID {
+					RespondWithError(w, http.StatusBadRequest, "The GRN was not raised against the given requisition.")
+					return nil, false
+				}
+				asset.RequisitionID = &reqItem.RequisitionID
+				requisitionItemID = &reqItem.ID
+			}
+		}
+	}
+
+	if asset.PurchaseOrderID != nil {
+		var order models.PurchaseOrder
+		if !lookup(&order, *asset.PurchaseOrderID, "purchase order") {
+			return nil, false
+		}
+		if asset.SupplierID == nil {
+			asset.SupplierID = &order.SupplierID
+		}
+	}
+	if asset.RequisitionID != nil {
+		var requisition models.Requisition
+		if !lookup(&requisition, *asset.RequisitionID, "requisition") {
+			return nil, false
+		}
+	}
+	if asset.CapitalizedValue == nil && asset.PurchasePrice != nil {
+		value := *asset.PurchasePrice
+		asset.CapitalizedValue = &value
+	}
+	return requisitionItemID, true
+}
+
+// checkAssetRegisterNumbers makes sure the AMR and EMR numbers are not used by another asset.
+func (h *AssetHandler) checkAssetRegisterNumbers(w http.ResponseWriter, asset *models.Asset) bool {
+	var count int64
+	query := h.DB.Model(&models.Asset{}).Where("id <> ?", asset.ID)
+	if asset.EmrID != nil {
+		query = query.Where("amr_id = ? OR emr_id = ?", asset.AmrID, *asset.EmrID)
+	} else {
+		query = query.Where("amr_id = ?", asset.AmrID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check asset register numbers: "+err.Error())
+		return false
+	}
+	if count > 0 {
+		RespondWithError(w, http.StatusConflict, "Another asset already uses this AMR or EMR number.")
+		return false
+	}
+	return true
+}
+
+// ListAssets returns the asset register. Optional ?status= and ?category= query parameters filter it.
+// GET /api/assets
+func (h *AssetHandler) ListAssets(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if hasRole(currentUser, "supplier") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Suppliers do not have access to the asset register.")
+		return
+	}
+
+	query := h.DB.Model(&models.Asset{})
+	if status := strings.TrimSpace(r.URL.Query().Get("status")); status != "" {
+		query = query.Where("status = ?", strings.ToLower(status))
+	}
+	if category := strings.TrimSpace(r.URL.Query().Get("category")); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var assets []models.Asset
+	if err := query.Order("amr_id ASC").Find(&assets).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve assets: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, assets)
+}
+
+// GetAsset returns a single asset.
+// GET /api/assets/{id}
+func (h *AssetHandler) GetAsset(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if hasRole(currentUser, "supplier") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Suppliers do not have access to the asset register.")
+		return
+	}
+	assetID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var asset models.Asset
+	if err := h.DB.First(&asset, assetID).Error; err != nil {
+		respondWithLookupError(w, err, "Asset")
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, asset)
+}
+
+// CreateAsset adds an asset to the register. When it is created from a GRN, the requisition
+// item it was requested on is pointed at the new asset.
+// POST /api/assets
+func (h *AssetHandler) CreateAsset(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and admins can register assets.")
+		return
+	}
+
+	asset, err := decodeAssetInput(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	asset.ID = 0
+	if err := validateAsset(&asset); err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	requisitionItemID, ok := h.resolveAssetLinks(w, &asset)
+	if !ok {
+		return
+	}
+	if !h.checkAssetRegisterNumbers(w, &asset) {
+		return
+	}
+	asset.CreatedByUserID = &currentUser.ID
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&asset).Error; err != nil {
+			return err
+		}
+		if requisitionItemID == nil {
+			return nil
+		}
+		return tx.Model(&models.RequisitionItem{}).
+			Where("id = ? AND amr_id IS NULL", *requisitionItemID).
+			Update("amr_id", asset.ID).Error
+	})
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to create asset: "+err.Error())
+		return
+	}
+	log.Printf("CreateAsset: Registered AssetID %d (%s) by UserID %d", asset.ID, asset.AmrID, currentUser.ID)
+
+	RespondWithJSON(w, http.StatusCreated, asset)
+}
+
+// UpdateAsset changes an asset's register, depreciation and linkage details.
+// PUT /api/assets/{id}
+func (h *AssetHandler) UpdateAsset(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and admins can change assets.")
+		return
+	}
+	assetID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var asset models.Asset
+	if err := h.DB.First(&asset, assetID).Error; err != nil {
+		respondWithLookupError(w, err, "Asset")
+		return
+	}
+
+	input, err := decodeAssetInput(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	input.ID = asset.ID
+	if err := validateAsset(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, ok := h.resolveAssetLinks(w, &input); !ok {
+		return
+	}
+	if !h.checkAssetRegisterNumbers(w, &input) {
+		return
+	}
+
+	input.CreatedByUserID = asset.CreatedByUserID
+	input.CreatedAt = asset.CreatedAt
+	if err := h.DB.Save(&input).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update asset: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, input)
+}
+
+// DeleteAsset removes an asset from the register and clears requisition items pointing at it.
+// DELETE /api/assets/{id}
+func (h *AssetHandler) DeleteAsset(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and admins can delete assets.")
+		return
+	}
+	assetID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var deleted int64
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RequisitionItem{}).Where("amr_id = ?", assetID).Update("amr_id", nil).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Asset{}, assetID)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to delete asset: "+err.Error())
+		return
+	}
+	if deleted == 0 {
+		RespondWithError(w, http.StatusNotFound, "Asset not found")
+		return
+	}
+	log.Printf("DeleteAsset: Deleted AssetID %d by UserID %d", assetID, currentUser.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AssetDepreciation is the response of the asset depreciation endpoint.
+type AssetDepreciation struct {
+	AssetID                 int64                         `json:"asset_id"`
+	Method                  string                        `json:"method"`
+	Cost                    float64                       `json:"cost"`
+	UsefulLifeYears         *int                          `json:"useful_life_years,omitempty"`
+	AnnualRatePercent       *float64                      `json:"annual_rate_percent,omitempty"`
+	StartDate               time.Time                     `json:"start_date"`
+	AsOf                    time.Time                     `json:"as_of"`
+	AccumulatedDepreciation float64                       `json:"accumulated_depreciation"`
+	NetBookValue            float64                       `json:"net_book_value"`
+	Schedule                []services.DepreciationPeriod `json:"schedule"`
+}
+
+// GetAssetDepreciation returns the month-by-month depreciation schedule of an asset and its net
+// book value on the ?as_of= date (YYYY-MM-DD, default today). Depreciation runs on the capitalized
+// value, or the purchase price when there is none, from the purchase date.
+// GET /api/assets/{id}/depreciation
+func (h *AssetHandler) GetAssetDepreciation(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if hasRole(currentUser, "supplier") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Suppliers do not have access to the asset register.")
+		return
+	}
+	assetID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	asOf := time.Now().UTC()
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			RespondWithError(w, http.StatusBadRequest, "Invalid as_of date, expected YYYY-MM-DD: "+err.Error())
+			return
+		}
+		asOf = parsed
+	}
+
+	var asset models.Asset
+	if err := h.DB.First(&asset, assetID).Error; err != nil {
+		respondWithLookupError(w, err, "Asset")
+		return
+	}
+	if asset.DepreciationMethod == nil {
+		RespondWithError(w, http.StatusConflict, "This asset has no depreciation method set.")
+		return
+	}
+
+	var cost float64
+	switch {
+	case asset.CapitalizedValue != nil:
+		cost = *asset.CapitalizedValue
+	case asset.PurchasePrice != nil:
+		cost = *asset.PurchasePrice
+	}
+	start := asset.CreatedAt
+	if asset.PurchaseDate != nil {
+		start = *asset.PurchaseDate
+	}
+
+	schedule, err := services.DepreciationSchedule(*asset.DepreciationMethod, cost, asset.DepreciationUsefulLife, asset.DepreciationAnnualRate, start)
+	if err != nil {
+		RespondWithError(w, http.StatusConflict, "Cannot calculate depreciation: "+err.Error())
+		return
+	}
+	accumulated, netBookValue := services.NetBookValueAt(schedule, cost, asOf)
+
+	RespondWithJSON(w, http.StatusOK, AssetDepreciation{
+		AssetID:                 asset.ID,
+		Method:                  *asset.DepreciationMethod,
+		Cost:                    cost,
+		UsefulLifeYears:         asset.DepreciationUsefulLife,
+		AnnualRatePercent:       asset.DepreciationAnnualRate,
+		StartDate:               start,
+		AsOf:                    asOf,
+		AccumulatedDepreciation: accumulated,
+		NetBookValue:            netBookValue,
+		Schedule:                schedule,
+	})
+}
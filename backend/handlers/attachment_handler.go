@@ -0,0 +1,493 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+	"procurement/services"
+)
+
+const (
+	maxAttachmentSize   = 10 * 1024 * 1024 // 10 MB per file
+	maxAttachmentUpload = 50 * 1024 * 1024 // 50 MB per request
+)
+
+// allowedAttachmentTypes are the sniffed content types accepted as they are.
+var allowedAttachmentTypes = map[string]bool{
+	"application/pdf":           true,
+	"image/png":                 true,
+	"image/jpeg":                true,
+	"image/gif":                 true,
+	"image/webp":                true,
+	"text/plain; charset=utf-8": true,
+}
+
+// zipOfficeTypes maps extensions of Office Open XML files, which sniff as zip, to their content type.
+var zipOfficeTypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".zip":  "application/zip",
+}
+
+// oleOfficeTypes maps extensions of legacy Office files, which share the OLE container signature,
+// to their content type.
+var oleOfficeTypes = map[string]string{
+	".doc": "application/msword",
+	".xls": "application/vnd.ms-excel",
+	".ppt": "application/vnd.ms-powerpoint",
+}
+
+// oleSignature opens every OLE compound file.
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// attachmentEntityNames names each entity type in error messages.
+var attachmentEntityNames = map[string]string{
+	models.AttachmentEntityRequisition:   "Requisition",
+	models.AttachmentEntityTender:        "Tender",
+	models.AttachmentEntityBid:           "Bid",
+	models.AttachmentEntityPurchaseOrder: "Purchase order",
+	models.AttachmentEntityInvoice:       "Invoice",
+}
+
+// attachmentRejectedError reports an upload refused because of its size or content; it maps to a 400.
+type attachmentRejectedError struct{ msg string }
+
+func (e *attachmentRejectedError) Error() string { return e.msg }
+
+// AttachmentHandler holds dependencies for the attachment handlers.
+type AttachmentHandler struct {
+	DB               *gorm.DB
+	Blobs            services.BlobStore
+	SignatureService services.SignatureService
+	Sealer           services.BidSealingService
+}
+
+// NewAttachmentHandler creates a new AttachmentHandler with the given DB connection, blob store,
+// the signature service used to sign file links and the service that seals bid documents.
+func NewAttachmentHandler(db *gorm.DB, blobs services.BlobStore, signatureService services.SignatureService, sealer services.BidSealingService) *AttachmentHandler {
+	return &AttachmentHandler{DB: db, Blobs: blobs, SignatureService: signatureService, Sealer: sealer}
+}
+
+// sniffAttachmentType works out the content type of an upload from its first bytes. The declared
+// extension is only trusted to tell apart container formats the sniffer cannot.
+func sniffAttachmentType(head []byte, fileName string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	sniffed := http.DetectContentType(head)
+	switch {
+	case sniffed == "application/zip":
+		if contentType, ok := zipOfficeTypes[ext]; ok {
+			return contentType, nil
+		}
+	case bytes.HasPrefix(head, oleSignature):
+		if contentType, ok := oleOfficeTypes[ext]; ok {
+			return contentType, nil
+		}
+	case allowedAttachmentTypes[sniffed]:
+		if ext == ".csv" {
+			return "text/csv", nil
+		}
+		return sniffed, nil
+	}
+	return "", &attachmentRejectedError{fmt.Sprintf("%q has a file type (%s) that is not allowed", fileName, sniffed)}
+}
+
+// checkAttachmentUpload enforces the size limit on an upload and returns its sniffed content type.
+func checkAttachmentUpload(header *multipart.FileHeader) (string, error) {
+	if header.Size > maxAttachmentSize {
+		return "", &attachmentRejectedError{fmt.Sprintf("%q exceeds the %dMB limit", header.Filename, maxAttachmentSize/1024/1024)}
+	}
+	if header.Size == 0 {
+		return "", &attachmentRejectedError{fmt.Sprintf("%q is empty", header.Filename)}
+	}
+	file, err := header.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %q: %w", header.Filename, err)
+	}
+	defer file.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", fmt.Errorf("failed to read %q: %w", header.Filename, err)
+	}
+	return sniffAttachmentType(head[:n], header.Filename)
+}
+
+// attachmentUpload is an uploaded file to store as an attachment of an entity.
+type attachmentUpload struct {
+	EntityType   string
+	EntityID     int64
+	Header       *multipart.FileHeader
+	DocumentType *string
+	Description  *string
+	UploadedByID int64
+	// Seal, when set, encrypts the content before it is stored and marks the attachment sealed.
+	Seal func(plaintext []byte) ([]byte, error)
+}
+
+// storeAttachment checks an upload, puts it in the blob store under
+// attachments/{entity_type}/{entity_id}/ and records it. The object is deleted again if the record
+// cannot be created.
+func storeAttachment(ctx context.Context, tx *gorm.DB, blobs services.BlobStore, upload attachmentUpload) (*models.Attachment, error) {
+	header := upload.Header
+	contentType, err := checkAttachmentUpload(header)
+	if err != nil {
+		return nil, err
+	}
+
+	key := newBlobKey(header.Filename, "attachments", upload.EntityType, strconv.FormatInt(upload.EntityID, 10))
+	if upload.Seal == nil {
+		err = putUploadedFile(ctx, blobs, key, header, contentType)
+	} else {
+		err = putSealedUploadedFile(ctx, blobs, key, header, upload.Seal)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := models.Attachment{
+		EntityType:       upload.EntityType,
+		EntityID:         upload.EntityID,
+		FileName:         header.Filename,
+		StorageKey:       key,
+		ContentType:      contentType,
+		Size:             header.Size,
+		DocumentType:     upload.DocumentType,
+		Description:      upload.Description,
+		UploadedByUserID: &upload.UploadedByID,
+		Sealed:           upload.Seal != nil,
+	}
+	if err := tx.Create(&attachment).Error; err != nil {
+		blobs.Delete(ctx, key)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// attachmentDownloadPath is the API path, relative to /api, that serves an attachment.
+func attachmentDownloadPath(attachment *models.Attachment) string {
+	return fmt.Sprintf("/files/%d", attachment.ID)
+}
+
+// attachmentAccess reports whether a user may read the attachments of an entity and whether they
+// may add to them, following the access rules of the entity itself. It returns
+// gorm.ErrRecordNotFound when the entity does not exist.
+func attachmentAccess(db *gorm.DB, user *models.User, entityType string, entityID int64) (canRead, canWrite bool, err error) {
+	switch entityType {
+	case models.AttachmentEntityRequisition:
+		var requisition models.Requisition
+		if err := db.Select("id", "user_id").First(&requisition, entityID).Error; err != nil {
+			return false, false, err
+		}
+		owner := requisition.UserID == user.ID
+		return owner || hasRole(user, "procurement_officer", "approver", "admin"),
+			owner || hasRole(user, "procurement_officer", "admin"), nil
+
+	case models.AttachmentEntityTender:
+		var tender models.Tender
+		if err := db.Select("id", "status").First(&tender, entityID).Error; err != nil {
+			return false, false, err
+		}
+		officer := hasRole(user, "procurement_officer", "admin")
+		return tenderVisibleTo(user, &tender), officer, nil
+
+	case models.AttachmentEntityBid:
+		var bid models.Bid
+		if err := db.Select("id", "tender_id", "supplier_id", "status").First(&bid, entityID).Error; err != nil {
+			return false, false, err
+		}
+		// Bid documents can only change while the tender takes bids, as the bid itself can; once
+		// it closes they are read-only, so nothing joins a bid after it is sealed or opened.
+		if actsForSupplier(user, bid.SupplierID) {
+			var tender models.Tender
+			if err := db.Select("id", "status", "closing_date").First(&tender, bid.TenderID).Error; err != nil {
+				return false, false, err
+			}
+			return true, bid.Status == "submitted" && tenderOpenForBids(&tender), nil
+		}
+		if hasRole(user, "procurement_officer", "admin") {
+			return true, false, nil
+		}
+		if hasRole(user, "evaluator") {
+			if err := checkPanelClearance(db, bid.TenderID, user.ID); err != nil {
+				var clearanceErr *panelClearanceError
+				if err == errNotPanelMember || errors.As(err, &clearanceErr) {
+					return false, false, nil
+				}
+				return false, false, err
+			}
+			return true, false, nil
+		}
+		return false, false, nil
+
+	case models.AttachmentEntityPurchaseOrder:
+		var order models.PurchaseOrder
+		if err := db.First(&order, entityID).Error; err != nil {
+			return false, false, err
+		}
+		issued := order.Status != models.PurchaseOrderStatusDraft
+		canRead = canViewPurchaseOrder(user, &order)
+		if !canRead && issued && hasRole(user, "requester") {
+			// The requester whose requisition the order fulfils receives its goods
+			if canRead, err = raisedRequisitionFor(db, user, &order); err != nil {
+				return false, false, err
+			}
+		}
+		canWrite = hasRole(user, "procurement_officer", "admin") || (actsForSupplier(user, order.SupplierID) && issued)
+		return canRead, canWrite, nil
+
+	case models.AttachmentEntityInvoice:
+		var invoice models.Invoice
+		if err := db.First(&invoice, entityID).Error; err != nil {
+			return false, false, err
+		}
+		canRead = canViewInvoice(user, &invoice)
+		return canRead, canRead, nil
+	}
+	return false, false, fmt.Errorf("unknown entity type %q", entityType)
+}
+
+// raisedRequisitionFor reports whether a user raised the requisition that a purchase order's
+// tender was created from.
+func raisedRequisitionFor(db *gorm.DB, user *models.User, order *models.PurchaseOrder) (bool, error) {
+	if order.TenderID == nil {
+		return false, nil
+	}
+	var count int64
+	err := db.Model(&models.Tender{}).
+		Joins("JOIN requisitions ON requisitions.id = tenders.requisition_id").
+		Where("tenders.id = ? AND requisitions.user_id = ?", *order.TenderID, user.ID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// parseAttachmentEntity reads and validates the entity_type and entity_id values of a request.
+func parseAttachmentEntity(entityTypeValue, entityIDValue string) (string, int64, error) {
+	entityType := strings.ToLower(strings.TrimSpace(entityTypeValue))
+	if _, ok := attachmentEntityNames[entityType]; !ok {
+		return "", 0, fmt.Errorf("entity_type must be one of requisition, tender, bid, purchase_order or invoice")
+	}
+	entityID, err := strconv.ParseInt(strings.TrimSpace(entityIDValue), 10, 64)
+	if err != nil || entityID <= 0 {
+		return "", 0, fmt.Errorf("entity_id must be a positive integer")
+	}
+	return entityType, entityID, nil
+}
+
+// authorizeAttachmentEntity checks the user's access to an entity's attachments, writing the
+// error response when access is refused. Entities the user cannot read are reported as not found.
+func authorizeAttachmentEntity(db *gorm.DB, w http.ResponseWriter, user *models.User, entityType string, entityID int64, write bool) bool {
+	canRead, canWrite, err := attachmentAccess(db, user, entityType, entityID)
+	if err != nil {
+		respondWithLookupError(w, err, attachmentEntityNames[entityType])
+		return false
+	}
+	if !canRead {
+		RespondWithError(w, http.StatusNotFound, "Attachments not found")
+		return false
+	}
+	if write && !canWrite {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: You cannot change the attachments of this "+strings.ToLower(attachmentEntityNames[entityType])+".")
+		return false
+	}
+	return true
+}
+
+// loadAttachment fetches the attachment from the {attachmentId} URL parameter and checks the
+// user's access to the entity it belongs to.
+func (h *AttachmentHandler) loadAttachment(w http.ResponseWriter, r *http.Request, user *models.User, write bool) (*models.Attachment, bool) {
+	attachmentID, ok := parseIDParam(w, r, "attachmentId")
+	if !ok {
+		return nil, false
+	}
+	var attachment models.Attachment
+	if err := h.DB.First(&attachment, attachmentID).Error; err != nil {
+		respondWithLookupError(w, err, "Attachment")
+		return nil, false
+	}
+	if !authorizeAttachmentEntity(h.DB, w, user, attachment.EntityType, attachment.EntityID, write) {
+		return nil, false
+	}
+	return &attachment, true
+}
+
+// ListAttachments returns attachments, newest first. With ?entity_type= and ?entity_id= it lists
+// one entity's attachments; without them procurement officers and admins see every attachment and
+// other users see the ones they uploaded. An optional ?document_type= filters further.
+// GET /api/attachments
+func (h *AttachmentHandler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+
+	query := h.DB.Model(&models.Attachment{})
+	entityTypeValue, entityIDValue := r.URL.Query().Get("entity_type"), r.URL.Query().Get("entity_id")
+	if entityTypeValue != "" || entityIDValue != "" {
+		entityType, entityID, err := parseAttachmentEntity(entityTypeValue, entityIDValue)
+		if err != nil {
+			RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !authorizeAttachmentEntity(h.DB, w, currentUser, entityType, entityID, false) {
+			return
+		}
+		query = query.Where("entity_type = ? AND entity_id = ?", entityType, entityID)
+	} else if !hasRole(currentUser, "procurement_officer", "admin") {
+		query = query.Where("uploaded_by_user_id = ?", currentUser.ID)
+	}
+	if documentType := strings.TrimSpace(r.URL.Query().Get("document_type")); documentType != "" {
+		query = query.Where("document_type = ?", documentType)
+	}
+
+	var attachments []models.Attachment
+	if err := query.Order("upload_date DESC, id DESC").Find(&attachments).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve attachments: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, attachments)
+}
+
+// UploadAttachments attaches one or more files to an entity. The multipart form carries
+// entity_type, entity_id, repeated "files" parts and optional document_type and description
+// values applied to every file. Each file is limited to 10MB and its type is sniffed from its content.
+// POST /api/attachments (multipart/form-data)
+func (h *AttachmentHandler) UploadAttachments(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentUpload)
+	if err := r.ParseMultipartForm(maxAttachmentUpload); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Failed to parse multipart form: "+err.Error())
+		return
+	}
+	entityType, entityID, err := parseAttachmentEntity(r.FormValue("entity_type"), r.FormValue("entity_id"))
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	files := r.MultipartForm.File["files"]
+	if len(files) == 0 {
+		RespondWithError(w, http.StatusBadRequest, "At least one file is required.")
+		return
+	}
+	if !authorizeAttachmentEntity(h.DB, w, currentUser, entityType, entityID, true) {
+		return
+	}
+	documentType := formValueOrNil(r, "document_type")
+	description := formValueOrNil(r, "description")
+	var seal func([]byte) ([]byte, error)
+	if entityType == models.AttachmentEntityBid {
+		if seal, err = bidAttachmentSealer(h.DB, h.Sealer, entityID); err != nil {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to prepare bid sealing: "+err.Error())
+			return
+		}
+	}
+
+	var attachments []models.Attachment
+	var stored []string
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		for _, header := range files {
+			attachment, err := storeAttachment(r.Context(), tx, h.Blobs, attachmentUpload{
+				EntityType:   entityType,
+				EntityID:     entityID,
+				Header:       header,
+				DocumentType: documentType,
+				Description:  description,
+				UploadedByID: currentUser.ID,
+				Seal:         seal,
+			})
+			if err != nil {
+				return err
+			}
+			stored = append(stored, attachment.StorageKey)
+			attachments = append(attachments, *attachment)
+		}
+		return nil
+	})
+	if err != nil {
+		for _, key := range stored {
+			h.Blobs.Delete(r.Context(), key)
+		}
+		var rejected *attachmentRejectedError
+		if errors.As(err, &rejected) {
+			RespondWithError(w, http.StatusBadRequest, rejected.Error())
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to store attachments: "+err.Error())
+		}
+		return
+	}
+	log.Printf("UploadAttachments: %d files attached to %s %d by UserID %d", len(attachments), entityType, entityID, currentUser.ID)
+
+	RespondWithJSON(w, http.StatusCreated, attachments)
+}
+
+// DownloadAttachment streams an attachment to a user who may read its entity. Range requests are
+// supported, so large PDFs can be viewed page by page.
+// GET /api/files/{attachmentId}
+// GET /api/attachments/{attachmentId}/download
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	attachment, ok := h.loadAttachment(w, r, currentUser, false)
+	if !ok {
+		return
+	}
+
+	h.serveAttachment(w, r, attachment)
+}
+
+// DeleteAttachment removes an attachment and its file. The uploader may delete their own
+// attachments while they can still change the entity; procurement officers and admins may delete
+// any, except a bid's documents, which only change as the bid itself can: by its supplier while
+// the tender takes bids.
+// DELETE /api/attachments/{attachmentId}
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	officer := hasRole(currentUser, "procurement_officer", "admin")
+	attachment, ok := h.loadAttachment(w, r, currentUser, false)
+	if !ok {
+		return
+	}
+	if !officer || attachment.EntityType == models.AttachmentEntityBid {
+		if !authorizeAttachmentEntity(h.DB, w, currentUser, attachment.EntityType, attachment.EntityID, true) {
+			return
+		}
+	}
+	uploader := attachment.UploadedByUserID != nil && *attachment.UploadedByUserID == currentUser.ID
+	if !officer && !uploader {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: You can only delete attachments you uploaded.")
+		return
+	}
+
+	if err := h.DB.Delete(&models.Attachment{}, attachment.ID).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to delete attachment: "+err.Error())
+		return
+	}
+	if err := h.Blobs.Delete(r.Context(), attachment.StorageKey); err != nil {
+		log.Printf("DeleteAttachment: AttachmentID %d deleted but its file could not be removed: %v", attachment.ID, err)
+	}
+	log.Printf("DeleteAttachment: AttachmentID %d (%s %d) deleted by UserID %d", attachment.ID, attachment.EntityType, attachment.EntityID, currentUser.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
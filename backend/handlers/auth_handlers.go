@@ -19,6 +19,7 @@ type AuthController struct {
 	TokenService   services.TokenService
 	PasswordService services.PasswordService
 	EmailService   services.EmailService
+	Blobs          services.BlobStore
 }
 
 // NewAuthController creates a new AuthController
@@ -36,18 +37,25 @@ func NewAuthController() (*AuthController, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	blobs, err := services.GetBlobStore()
+	if err != nil {
+		return nil, err
+	}
 	
 	return &AuthController{
 		DB:             db,
 		TokenService:   tokenService,
 		PasswordService: passwordService,
 		EmailService:   emailService,
+		Blobs:          blobs,
 	}, nil
 }
 
 // RegisterRoutes registers the authentication routes
 func (c *AuthController) RegisterRoutes(r chi.Router) {
 	r.Post("/auth/register", c.Register)
+	r.Post("/auth/register/supplier", c.RegisterSupplier)
 	r.Post("/auth/login", c.Login)
 	r.Post("/auth/password/change", c.ChangePassword)
 	r.Post("/auth/password/reset/request", c.RequestPasswordReset)
@@ -275,8 +283,8 @@ func (c *AuthController) RequestPasswordReset(w http.ResponseWriter, r *http.Req
 	passwordReset := models.PasswordReset{
 		UserID:    user.ID,
 		Token:     token,
-		ExpiresAt: time.Now().Add(1 * time.Hour),
-		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().UTC().Add(1 * time.Hour),
+		CreatedAt: time.Now().UTC(),
 	}
 	
 	if result := c.DB.Create(&passwordReset); result.Error != nil {
@@ -392,12 +400,16 @@ func (c *AuthController) Logout(w http.ResponseWriter, r *http.Request) {
 	// Extract token from "Bearer {token}"
 	tokenString := authHeader[7:]
 	
-	// Invalidate the current session
+	// Invalidate the current session. The record is kept until the token itself expires,
+	// after which the scheduler purges it.
 	session := models.Session{
 		UserID:  userID,
 		Token:   tokenString,
 		IsValid: false,
 	}
+	if claims, err := c.TokenService.ValidateToken(tokenString); err == nil && claims.ExpiresAt != nil {
+		session.ExpiresAt = claims.ExpiresAt.Time.UTC()
+	}
 	
 	// Update if exists, create if not
 	result := c.DB.Where("token = ?", tokenString).FirstOrCreate(&session)
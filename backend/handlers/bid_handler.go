@@ -2,13 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"os"
-	"path/filepath"
 	"procurement/models"
+	"procurement/services"
 	"strconv"
 	"strings"
 	"time"
@@ -19,17 +18,61 @@ import (
 
 // BidHandler handles HTTP requests for bids.
 type BidHandler struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	Blobs  services.BlobStore
+	Sealer services.BidSealingService
+	Signer services.SignatureService // Signs bid opening registers
 }
 
-// NewBidHandler creates a new BidHandler with the given database connection.
-func NewBidHandler(db *gorm.DB) *BidHandler {
-	return &BidHandler{DB: db}
+// NewBidHandler creates a new BidHandler with the given database connection, blob store, the
+// service that seals bids until bid opening and the service that signs opening registers.
+func NewBidHandler(db *gorm.DB, blobs services.BlobStore, sealer services.BidSealingService, signer services.SignatureService) *BidHandler {
+	return &BidHandler{DB: db, Blobs: blobs, Sealer: sealer, Signer: signer}
+}
+
+// inactiveBidStatuses are the statuses of bids that no longer take part in their tender. Withdrawn
+// and superseded bids are kept, with their items and documents, as the history of a supplier's bids.
+var inactiveBidStatuses = []string{"withdrawn", "superseded"}
+
+// isActiveBid reports whether a bid still takes part in its tender.
+func isActiveBid(bid *models.Bid) bool {
+	for _, status := range inactiveBidStatuses {
+		if bid.Status == status {
+			return false
+		}
+	}
+	return true
+}
+
+// activeBidOf returns the supplier's active bid on a tender, or nil if it has none.
+func activeBidOf(db *gorm.DB, tenderID, supplierID int64) (*models.Bid, error) {
+	var bid models.Bid
+	err := db.Where("tender_id = ? AND supplier_id = ? AND status NOT IN ?", tenderID, supplierID, inactiveBidStatuses).First(&bid).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bid, nil
 }
 
 // CreateBid handles the submission of a new bid for a tender.
+// A supplier has one active bid per tender; to change it, it is replaced or withdrawn.
 // POST /api/tenders/{tenderId}/bids
 func (h *BidHandler) CreateBid(w http.ResponseWriter, r *http.Request) {
+	h.submitBid(w, r, false)
+}
+
+// ReplaceBid submits a new version of the supplier's active bid before the closing date. It takes
+// the same form as CreateBid; the previous version is kept as 'superseded'.
+// PUT /api/tenders/{tenderId}/bids/{bidId}
+func (h *BidHandler) ReplaceBid(w http.ResponseWriter, r *http.Request) {
+	h.submitBid(w, r, true)
+}
+
+// submitBid creates a bid from the submitted form, superseding the bid named by {bidId} when replace is set.
+func (h *BidHandler) submitBid(w http.ResponseWriter, r *http.Request, replace bool) {
 	// Get authenticated user details from context
 	userIDFromContext, ok := r.Context().Value("userID").(int64)
 	if !ok {
@@ -48,6 +91,20 @@ func (h *BidHandler) CreateBid(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusForbidden, "Forbidden: Only suppliers can submit bids.")
 		return
 	}
+	supplier, ok := requireActiveSupplier(h.DB, w, &currentUser)
+	if !ok {
+		return
+	}
+	supplierID := supplier.ID
+	debarment, err := activeDebarment(h.DB, supplierID, time.Now())
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check supplier debarment: "+err.Error())
+		return
+	}
+	if debarment != nil {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Your organisation is "+debarmentMessage(debarment)+".")
+		return
+	}
 
 	// Get Tender ID from URL path parameter
 	tenderIDStr := chi.URLParam(r, "tenderId")
@@ -56,7 +113,7 @@ func (h *BidHandler) CreateBid(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusBadRequest, "Invalid tender ID format: "+err.Error())
 		return
 	}
-	log.Printf("CreateBid: Attempting to create bid for TenderID: %d by SupplierID: %d", tenderID, currentUser.ID)
+	log.Printf("CreateBid: Attempting to create bid for TenderID: %d by SupplierID: %d (UserID: %d)", tenderID, supplierID, currentUser.ID)
 
 	// Fetch the target tender to check its status
 	var tender models.Tender
@@ -79,7 +136,7 @@ func (h *BidHandler) CreateBid(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !strings.EqualFold(tenderStatus, "published") {
+	if !strings.EqualFold(tenderStatus, models.TenderStatusPublished) {
 		RespondWithError(w, http.StatusBadRequest, "Tender is not published and thus not open for bidding.")
 		return
 	}
@@ -89,10 +146,53 @@ func (h *BidHandler) CreateBid(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Printf("CreateBid: TenderID %d is open for bidding.", tenderID)
 
-	// Define max upload size (e.g., 10MB per file, overall 50MB)
-	const maxFileSize = 10 * 1024 * 1024 // 10 MB
+	// The supplier must have seen the tender as last amended
+	addendum, err := unacknowledgedAddendum(h.DB, tenderID, supplierID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check tender addenda: "+err.Error())
+		return
+	}
+	if addendum != nil {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Your organisation must acknowledge addendum %d to this tender before bidding.", addendum.Number))
+		return
+	}
+
+	// A replacement must name the supplier's active bid; otherwise there must not be one
+	var previous *models.Bid
+	if replace {
+		bidID, ok := parseIDParam(w, r, "bidId")
+		if !ok {
+			return
+		}
+		previous = &models.Bid{}
+		if err := h.DB.Where("id = ? AND tender_id = ?", bidID, tenderID).First(previous).Error; err != nil {
+			respondWithLookupError(w, err, "Bid")
+			return
+		}
+		if previous.SupplierID != supplierID {
+			RespondWithError(w, http.StatusForbidden, "Forbidden: You can only replace your own organisation's bids.")
+			return
+		}
+		if !isActiveBid(previous) {
+			RespondWithError(w, http.StatusConflict, fmt.Sprintf("Only an active bid can be replaced (current status: %s).", previous.Status))
+			return
+		}
+	} else {
+		activeBid, err := activeBidOf(h.DB, tenderID, supplierID)
+		if err != nil {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to check existing bids: "+err.Error())
+			return
+		}
+		if activeBid != nil {
+			RespondWithError(w, http.StatusConflict, fmt.Sprintf("Your organisation already has an active bid (ID %d) on this tender; replace or withdraw it instead.", activeBid.ID))
+			return
+		}
+	}
+
+	// Each file is size-checked by storeAttachment; the whole request is capped at maxAttachmentUpload
 	// r.ParseMultipartForm needs to be called before accessing form data
-	if err := r.ParseMultipartForm(50 * 1024 * 1024); err != nil { // 50MB total max size
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentUpload)
+	if err := r.ParseMultipartForm(maxAttachmentUpload); err != nil {
 		RespondWithError(w, http.StatusBadRequest, "Failed to parse multipart form: "+err.Error())
 		return
 	}
@@ -120,9 +220,21 @@ func (h *BidHandler) CreateBid(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Every bid item must price a line item of this tender with the same quantity and unit
+	var tenderItems []models.TenderItem
+	if err := h.DB.Where("tender_id = ?", tenderID).Find(&tenderItems).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve tender items: "+err.Error())
+		return
+	}
+	if err := matchBidItemsToTender(bidItems, tenderItems); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid bid items: "+err.Error())
+		return
+	}
+
 	// Populate bid details
 	bidInput.TenderID = tenderID
-	bidInput.SupplierID = currentUser.ID
+	bidInput.SupplierID = supplierID
+	bidInput.SubmittedByID = &currentUser.ID
 	// bidInput.Status is defaulted by model
 	// bidInput.BidAmount might be calculated or set based on items or a general field
 	// For now, let's assume it might still be a general field or we'll calculate it later.
@@ -148,12 +260,56 @@ func (h *BidHandler) CreateBid(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The amount, item prices and documents are sealed to the tender's key until bid opening;
+	// only the encrypted copy is stored
+	sealedContent := sealedBidContent{BidAmount: bidInput.BidAmount, ItemPrices: make(map[int64]float64, len(bidItems))}
+	bidInput.BidAmount = 0
+	bidInput.Sealed = true
+
 	// Start a transaction
 	tx := h.DB.Begin()
 	if tx.Error != nil {
 		RespondWithError(w, http.StatusInternalServerError, "Failed to start database transaction: "+tx.Error.Error())
 		return
 	}
+	sealingKey, err := tenderSealingPublicKey(tx, h.Sealer, tenderID)
+	if err != nil {
+		tx.Rollback()
+		RespondWithError(w, http.StatusInternalServerError, "Failed to prepare bid sealing: "+err.Error())
+		return
+	}
+	sealDocument := func(plaintext []byte) ([]byte, error) { return h.Sealer.Seal(sealingKey, plaintext) }
+
+	// Supersede the previous version, or make sure no other bid became active meanwhile
+	if previous != nil {
+		result := tx.Model(&models.Bid{}).Where("id = ? AND status = ?", previous.ID, previous.Status).Update("status", "superseded")
+		if result.Error != nil {
+			tx.Rollback()
+			RespondWithError(w, http.StatusInternalServerError, "Failed to supersede previous bid: "+result.Error.Error())
+			return
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			RespondWithError(w, http.StatusConflict, "The bid was changed by another request; reload it and try again.")
+			return
+		}
+		bidInput.ReplacesBidID = &previous.ID
+	} else if activeBid, err := activeBidOf(tx, tenderID, supplierID); err != nil || activeBid != nil {
+		tx.Rollback()
+		if err != nil {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to check existing bids: "+err.Error())
+		} else {
+			RespondWithError(w, http.StatusConflict, fmt.Sprintf("Your organisation already has an active bid (ID %d) on this tender; replace or withdraw it instead.", activeBid.ID))
+		}
+		return
+	}
+	var previousVersions int64
+	if err := tx.Model(&models.Bid{}).Where("tender_id = ? AND supplier_id = ?", tenderID, supplierID).Count(&previousVersions).Error; err != nil {
+		tx.Rollback()
+		RespondWithError(w, http.StatusInternalServerError, "Failed to number bid version: "+err.Error())
+		return
+	}
+	bidInput.Version = int(previousVersions) + 1
 
 	// Save the main Bid record first to get its ID
 	if err := tx.Create(&bidInput).Error; err != nil {
@@ -161,90 +317,77 @@ func (h *BidHandler) CreateBid(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusInternalServerError, "Failed to create bid (main record): "+err.Error())
 		return
 	}
-	log.Printf("CreateBid: Successfully created BidID: %d for TenderID: %d by SupplierID: %d (pre-items)", bidInput.ID, tenderID, currentUser.ID)
+	log.Printf("CreateBid: Successfully created BidID: %d for TenderID: %d by SupplierID: %d (pre-items)", bidInput.ID, tenderID, supplierID)
 
 	// Process and save BidItems and their files
 	for i := range bidItems {
 		bidItems[i].BidID = bidInput.ID // Link item to the created Bid
 
-		// Handle Specification Sheet File
-		specSheetKey := fmt.Sprintf("item_spec_sheet_%d", i)
-		file, header, err := r.FormFile(specSheetKey)
-		if err == nil { // File is present
-			defer file.Close()
-			if header.Size > maxFileSize {
-				tx.Rollback()
-				RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Specification sheet for item %d (%s) exceeds max size of %dMB", i+1, header.Filename, maxFileSize/1024/1024))
-				return
-			}
-			// Ensure directory exists: ./uploads/bids/{bid_id}/items/{item_index}/specs/
-			// Using item.ID might be problematic if it's not set yet, so use index or generate UUID for filename
-			filePath := filepath.Join(".", "uploads", "bids", strconv.FormatInt(bidInput.ID, 10), "items", strconv.Itoa(i), "specs", SanitizeFilename(header.Filename))
-			if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
-				tx.Rollback()
-				RespondWithError(w, http.StatusInternalServerError, "Failed to create directory for spec sheet: "+err.Error())
-				return
-			}
-			dst, err := os.Create(filePath)
-			if err != nil {
-				tx.Rollback()
-				RespondWithError(w, http.StatusInternalServerError, "Failed to create file for spec sheet: "+err.Error())
-				return
-			}
-			defer dst.Close()
-			if _, err := io.Copy(dst, file); err != nil {
-				tx.Rollback()
-				RespondWithError(w, http.StatusInternalServerError, "Failed to save spec sheet: "+err.Error())
-				return
-			}
-			bidItems[i].SpecificationSheetURL = &filePath
-		} else if err != http.ErrMissingFile {
-			tx.Rollback()
-			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error processing spec sheet for item %d: %s", i+1, err.Error()))
-			return
+		// Specification sheets and item images are stored as attachments of the bid
+		itemFiles := []struct {
+			key          string
+			documentType string
+			url          **string
+		}{
+			{fmt.Sprintf("item_spec_sheet_%d", i), models.AttachmentTypeSpecificationSheet, &bidItems[i].SpecificationSheetURL},
+			{fmt.Sprintf("item_image_%d", i), models.AttachmentTypeItemImage, &bidItems[i].ItemImageURL},
 		}
-
-		// Handle Item Image File
-		itemImageKey := fmt.Sprintf("item_image_%d", i)
-		file, header, err = r.FormFile(itemImageKey)
-		if err == nil { // File is present
-			defer file.Close()
-			if header.Size > maxFileSize {
-				tx.Rollback()
-				RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Item image for item %d (%s) exceeds max size of %dMB", i+1, header.Filename, maxFileSize/1024/1024))
-				return
+		for _, itemFile := range itemFiles {
+			file, header, err := r.FormFile(itemFile.key)
+			if err == http.ErrMissingFile {
+				continue
 			}
-			filePath := filepath.Join(".", "uploads", "bids", strconv.FormatInt(bidInput.ID, 10), "items", strconv.Itoa(i), "images", SanitizeFilename(header.Filename))
-			if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
-				tx.Rollback()
-				RespondWithError(w, http.StatusInternalServerError, "Failed to create directory for item image: "+err.Error())
-				return
-			}
-			dst, err := os.Create(filePath)
 			if err != nil {
 				tx.Rollback()
-				RespondWithError(w, http.StatusInternalServerError, "Failed to create file for item image: "+err.Error())
+				RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error processing %s for item %d: %s", itemFile.key, i+1, err.Error()))
 				return
 			}
-			defer dst.Close()
-			if _, err := io.Copy(dst, file); err != nil {
+			file.Close()
+			description := fmt.Sprintf("Bid item %d", i+1)
+			attachment, err := storeAttachment(r.Context(), tx, h.Blobs, attachmentUpload{
+				EntityType:   models.AttachmentEntityBid,
+				EntityID:     bidInput.ID,
+				Header:       header,
+				DocumentType: &itemFile.documentType,
+				Description:  &description,
+				UploadedByID: currentUser.ID,
+				Seal:         sealDocument,
+			})
+			if err != nil {
 				tx.Rollback()
-				RespondWithError(w, http.StatusInternalServerError, "Failed to save item image: "+err.Error())
+				var rejected *attachmentRejectedError
+				if errors.As(err, &rejected) {
+					RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s for item %d: %s", itemFile.key, i+1, rejected.Error()))
+				} else {
+					RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to save %s for item %d: %s", itemFile.key, i+1, err.Error()))
+				}
 				return
 			}
-			bidItems[i].ItemImageURL = &filePath
-		} else if err != http.ErrMissingFile {
-			tx.Rollback()
-			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error processing item image for item %d: %s", i+1, err.Error()))
-			return
+			url := attachmentDownloadPath(attachment)
+			*itemFile.url = &url
 		}
 
-		// Save the BidItem
+		// Save the BidItem, keeping its price for the sealed content only
+		offeredUnitPrice := bidItems[i].OfferedUnitPrice
+		bidItems[i].OfferedUnitPrice = 0
 		if err := tx.Create(&bidItems[i]).Error; err != nil {
 			tx.Rollback()
 			RespondWithError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to save bid item %d: %s", i+1, err.Error()))
 			return
 		}
+		sealedContent.ItemPrices[bidItems[i].ID] = offeredUnitPrice
+	}
+
+	sealed, err := sealBidContent(h.Sealer, sealingKey, &sealedContent)
+	if err != nil {
+		tx.Rollback()
+		RespondWithError(w, http.StatusInternalServerError, "Failed to seal bid: "+err.Error())
+		return
+	}
+	if err := tx.Model(&models.Bid{}).Where("id = ?", bidInput.ID).UpdateColumn("sealed_content", sealed).Error; err != nil {
+		tx.Rollback()
+		RespondWithError(w, http.StatusInternalServerError, "Failed to save sealed bid: "+err.Error())
+		return
 	}
 
 	// Commit the transaction
@@ -253,22 +396,91 @@ func (h *BidHandler) CreateBid(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("CreateBid: Successfully created BidID: %d with %d items for TenderID: %d by SupplierID: %d", bidInput.ID, len(bidItems), tenderID, currentUser.ID)
+	log.Printf("CreateBid: Successfully created BidID: %d with %d items for TenderID: %d by SupplierID: %d", bidInput.ID, len(bidItems), tenderID, supplierID)
 
-	// Reload the bid with its items to return the full object
+	// Reload the bid with its items to return the full object. The supplier gets back the amounts
+	// it just submitted; they stay sealed in storage.
+	bidInput.BidAmount = sealedContent.BidAmount
 	var finalBid models.Bid
 	if err := h.DB.Preload("Items").First(&finalBid, bidInput.ID).Error; err != nil {
 	    log.Printf("Error reloading bid with items: %v. Returning bid without items.", err)
 	    RespondWithJSON(w, http.StatusCreated, bidInput) // Fallback to returning bidInput without items if reload fails
 	    return
 	}
+	finalBid.BidAmount = sealedContent.BidAmount
+	for i := range finalBid.Items {
+		finalBid.Items[i].OfferedUnitPrice = sealedContent.ItemPrices[finalBid.Items[i].ID]
+	}
 
 	RespondWithJSON(w, http.StatusCreated, finalBid)
 }
 
+// WithdrawBid withdraws the supplier's active bid before the tender's closing date. The bid is
+// kept, as 'withdrawn', and the supplier may submit a new one while the tender is open.
+// POST /api/tenders/{tenderId}/bids/{bidId}/withdraw
+func (h *BidHandler) WithdrawBid(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "supplier") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only suppliers can withdraw bids.")
+		return
+	}
+	tenderID, ok := parseIDParam(w, r, "tenderId")
+	if !ok {
+		return
+	}
+	bidID, ok := parseIDParam(w, r, "bidId")
+	if !ok {
+		return
+	}
+
+	var bid models.Bid
+	if err := h.DB.Where("id = ? AND tender_id = ?", bidID, tenderID).First(&bid).Error; err != nil {
+		respondWithLookupError(w, err, "Bid")
+		return
+	}
+	if !actsForSupplier(currentUser, bid.SupplierID) {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: You can only withdraw your own organisation's bids.")
+		return
+	}
+	var tender models.Tender
+	if err := h.DB.First(&tender, tenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return
+	}
+	if !tenderOpenForBids(&tender) {
+		RespondWithError(w, http.StatusConflict, "Bids can only be withdrawn while the tender is open, before its closing date.")
+		return
+	}
+	if !isActiveBid(&bid) {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Only an active bid can be withdrawn (current status: %s).", bid.Status))
+		return
+	}
+
+	now := time.Now().UTC()
+	result := h.DB.Model(&models.Bid{}).Where("id = ? AND status = ?", bid.ID, bid.Status).
+		Updates(map[string]interface{}{"status": "withdrawn", "withdrawn_at": now})
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to withdraw bid: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusConflict, "The bid was changed by another request; reload it and try again.")
+		return
+	}
+	log.Printf("WithdrawBid: BidID %d on TenderID %d withdrawn by UserID %d", bid.ID, tenderID, currentUser.ID)
+
+	bid.Status = "withdrawn"
+	bid.WithdrawnAt = &now
+	RespondWithJSON(w, http.StatusOK, bid)
+}
+
 // ListTenderBids handles listing all bids for a specific tender.
 // GET /api/tenders/{tenderId}/bids
-// Accessible by procurement officers.
+// Accessible by procurement officers and cleared evaluation panel members. Only active bids are
+// listed unless ?history=true, which adds withdrawn and superseded versions.
 func (h *BidHandler) ListTenderBids(w http.ResponseWriter, r *http.Request) {
 	// Get authenticated user details from context
 	userIDFromContext, ok := r.Context().Value("userID").(int64)
@@ -283,9 +495,9 @@ func (h *BidHandler) ListTenderBids(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Role check: Only procurement officers can list all bids for a tender
-	if !strings.EqualFold(currentUser.Role, "procurement_officer") {
-		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can view all bids for a tender.")
+	// Role check: Only procurement officers and evaluators can list all bids for a tender
+	if !hasRole(&currentUser, "procurement_officer", "evaluator") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and evaluators can view all bids for a tender.")
 		return
 	}
 
@@ -296,7 +508,7 @@ func (h *BidHandler) ListTenderBids(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusBadRequest, "Invalid tender ID format: "+err.Error())
 		return
 	}
-	log.Printf("ListTenderBids: ProcurementOfficerID: %d attempting to list bids for TenderID: %d", currentUser.ID, tenderID)
+	log.Printf("ListTenderBids: UserID: %d (Role: %s) attempting to list bids for TenderID: %d", currentUser.ID, currentUser.Role, tenderID)
 
 	// Optional: Verify tender exists (though listing bids for a non-existent tender will just return empty)
 	var tender models.Tender
@@ -309,15 +521,51 @@ func (h *BidHandler) ListTenderBids(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Panel members must have cleared their conflict-of-interest declarations
+	if !requirePanelClearance(h.DB, w, tenderID, &currentUser) {
+		return
+	}
+
+	// Bids stay sealed until the panel opens them at the bid opening
+	var sealedCount int64
+	if err := h.DB.Model(&models.Bid{}).Where("tender_id = ? AND sealed = ?", tenderID, true).Count(&sealedCount).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check sealed bids: "+err.Error())
+		return
+	}
+	if sealedCount > 0 {
+		RespondWithError(w, http.StatusForbidden, "Bids are sealed until the evaluation panel opens them at the bid opening.")
+		return
+	}
+
 	// Fetch bids for the tender, preloading supplier information
 	var bids []models.Bid
-	if err := h.DB.Preload("Supplier").Where("tender_id = ?", tenderID).Order("submission_date ASC").Find(&bids).Error; err != nil {
+	query := h.DB.Preload("Supplier").Where("tender_id = ?", tenderID)
+	if r.URL.Query().Get("history") != "true" {
+		query = query.Where("status NOT IN ?", inactiveBidStatuses)
+	}
+	if err := query.Order("submission_date ASC").Find(&bids).Error; err != nil {
 		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve bids: "+err.Error())
 		return
 	}
 	log.Printf("ListTenderBids: Found %d bids for TenderID: %d", len(bids), tenderID)
 
-	RespondWithJSON(w, http.StatusOK, bids)
+	// Attach each supplier's scorecard so evaluators can weigh past performance
+	supplierIDs := make([]int64, 0, len(bids))
+	for _, bid := range bids {
+		supplierIDs = append(supplierIDs, bid.SupplierID)
+	}
+	now := time.Now().UTC()
+	scorecards, err := supplierScorecards(h.DB, supplierIDs, now.AddDate(0, -defaultScorecardMonths, 0), now)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to compute supplier scorecards: "+err.Error())
+		return
+	}
+	tenderBids := make([]TenderBid, 0, len(bids))
+	for _, bid := range bids {
+		tenderBids = append(tenderBids, TenderBid{Bid: bid, SupplierScorecard: scorecards[bid.SupplierID]})
+	}
+
+	RespondWithJSON(w, http.StatusOK, tenderBids)
 }
 
 // ListMyBids handles listing all bids submitted by the authenticated supplier.
@@ -342,15 +590,19 @@ func (h *BidHandler) ListMyBids(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusForbidden, "Forbidden: Only suppliers can view their submitted bids.")
 		return
 	}
-	log.Printf("ListMyBids: SupplierID: %d attempting to list their bids", currentUser.ID)
+	supplierID, ok := requireSupplierOrganisation(w, &currentUser)
+	if !ok {
+		return
+	}
+	log.Printf("ListMyBids: SupplierID: %d attempting to list their bids", supplierID)
 
-	// Fetch bids submitted by the current supplier, preloading Tender information
+	// Fetch bids submitted by the current supplier organisation, preloading Tender information
 	var myBids []models.Bid
-	if err := h.DB.Preload("Tender").Where("supplier_id = ?", currentUser.ID).Order("submission_date DESC").Find(&myBids).Error; err != nil {
+	if err := h.DB.Preload("Tender").Where("supplier_id = ?", supplierID).Order("submission_date DESC").Find(&myBids).Error; err != nil {
 		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve your bids: "+err.Error())
 		return
 	}
-	log.Printf("ListMyBids: Found %d bids for SupplierID: %d", len(myBids), currentUser.ID)
+	log.Printf("ListMyBids: Found %d bids for SupplierID: %d", len(myBids), supplierID)
 
 	RespondWithJSON(w, http.StatusOK, myBids)
 }
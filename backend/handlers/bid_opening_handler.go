@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"procurement/models"
+)
+
+// errBidsAlreadyOpened is returned from the opening transaction when another request opened the bids first.
+var errBidsAlreadyOpened = errors.New("bids were opened concurrently")
+
+// errTenderNotClosed is returned from the opening transaction when the tender left the closed status underneath it.
+var errTenderNotClosed = errors.New("tender is no longer closed")
+
+// bidOpeningQuorum is the number of panel members that must join an opening: a majority of the panel.
+func bidOpeningQuorum(memberCount int64) int {
+	return int(memberCount/2) + 1
+}
+
+// registerPayload is the canonical text the register digest covers. It binds the opening time
+// to every entry, so neither can be altered afterwards without the digest failing to verify.
+func registerPayload(opening *models.BidOpening, entries []models.BidOpeningRegisterEntry) string {
+	var b strings.Builder
+	var openedAt int64
+	if opening.OpenedAt != nil {
+		openedAt = opening.OpenedAt.Unix()
+	}
+	fmt.Fprintf(&b, "opening:%d|tender:%d|opened_at:%d", opening.ID, opening.TenderID, openedAt)
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "|bid:%d|supplier:%d|name:%s|amount:%s|received:%d|documents:%s",
+			entry.BidID, entry.SupplierID, entry.SupplierName, strconv.FormatFloat(entry.BidAmount, 'f', -1, 64),
+			entry.ReceivedAt.Unix(), strings.Join(entry.Documents, ";"))
+	}
+	return b.String()
+}
+
+// startBidOpening returns the bid opening of a tender, starting it with the given panel when
+// there is none yet. The quorum is fixed from the panel's size when the opening starts.
+func startBidOpening(db *gorm.DB, tenderID, panelID int64) (*models.BidOpening, error) {
+	var memberCount int64
+	if err := db.Model(&models.UserEvaluationPanelMembership{}).Where("evaluation_panel_id = ?", panelID).Count(&memberCount).Error; err != nil {
+		return nil, err
+	}
+	opening := models.BidOpening{
+		TenderID:          tenderID,
+		EvaluationPanelID: panelID,
+		Status:            models.BidOpeningStatusAwaitingQuorum,
+		Quorum:            bidOpeningQuorum(memberCount),
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&opening).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("tender_id = ?", tenderID).First(&opening).Error; err != nil {
+		return nil, err
+	}
+	return &opening, nil
+}
+
+// loadBidOpening fetches a tender's bid opening with its attendees and register, or nil if no
+// panel member has started one.
+func loadBidOpening(db *gorm.DB, tenderID int64) (*models.BidOpening, error) {
+	var opening models.BidOpening
+	err := db.Preload("Attendees", func(db *gorm.DB) *gorm.DB { return db.Order("joined_at ASC") }).
+		Preload("Register", func(db *gorm.DB) *gorm.DB { return db.Order("received_at ASC, id ASC") }).
+		Where("tender_id = ?", tenderID).First(&opening).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &opening, nil
+}
+
+// buildOpeningRegister reads out the tender's active bids, now unsealed, for the opening register.
+func buildOpeningRegister(db *gorm.DB, opening *models.BidOpening) ([]models.BidOpeningRegisterEntry, error) {
+	var bids []models.Bid
+	if err := db.Preload("Supplier").Where("tender_id = ? AND status NOT IN ?", opening.TenderID, inactiveBidStatuses).
+		Order("submission_date ASC, id ASC").Find(&bids).Error; err != nil {
+		return nil, err
+	}
+	if len(bids) == 0 {
+		return nil, nil
+	}
+
+	bidIDs := make([]int64, 0, len(bids))
+	for _, bid := range bids {
+		if bid.Sealed {
+			return nil, fmt.Errorf("bid %d is still sealed", bid.ID)
+		}
+		bidIDs = append(bidIDs, bid.ID)
+	}
+	var attachments []models.Attachment
+	if err := db.Where("entity_type = ? AND entity_id IN ?", models.AttachmentEntityBid, bidIDs).
+		Order("id ASC").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	documents := make(map[int64][]string, len(bids))
+	for _, attachment := range attachments {
+		documentType := "document"
+		if attachment.DocumentType != nil && *attachment.DocumentType != "" {
+			documentType = *attachment.DocumentType
+		}
+		documents[attachment.EntityID] = append(documents[attachment.EntityID], documentType+": "+attachment.FileName)
+	}
+
+	entries := make([]models.BidOpeningRegisterEntry, 0, len(bids))
+	for _, bid := range bids {
+		supplierName := fmt.Sprintf("Supplier %d", bid.SupplierID)
+		if bid.Supplier.Name != "" {
+			supplierName = bid.Supplier.Name
+		}
+		bidDocuments := documents[bid.ID]
+		if bidDocuments == nil {
+			bidDocuments = []string{}
+		}
+		entries = append(entries, models.BidOpeningRegisterEntry{
+			BidOpeningID: opening.ID,
+			BidID:        bid.ID,
+			SupplierID:   bid.SupplierID,
+			SupplierName: supplierName,
+			BidAmount:    bid.BidAmount,
+			ReceivedAt:   bid.SubmissionDate.UTC(),
+			Documents:    bidDocuments,
+		})
+	}
+	return entries, nil
+}
+
+// canViewBidOpening reports whether a user may see a tender's opening: procurement officers,
+// evaluators and suppliers that bid on the tender.
+func canViewBidOpening(db *gorm.DB, tenderID int64, user *models.User) (bool, error) {
+	if hasRole(user, "procurement_officer", "evaluator") {
+		return true, nil
+	}
+	if !hasRole(user, "supplier") || user.SupplierID == nil {
+		return false, nil
+	}
+	var count int64
+	err := db.Model(&models.Bid{}).Where("tender_id = ? AND supplier_id = ?", tenderID, *user.SupplierID).Count(&count).Error
+	return count > 0, err
+}
+
+// loadVisibleBidOpening fetches the tender from the {id} URL parameter and its opening, checking
+// that the user may see it. Suppliers only see an opening once the bids are opened, and without
+// the panel members who attended. It writes the error response itself and returns nil on failure.
+func (h *BidHandler) loadVisibleBidOpening(w http.ResponseWriter, r *http.Request) *models.BidOpening {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return nil
+	}
+	tenderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return nil
+	}
+	var tender models.Tender
+	if err := h.DB.First(&tender, tenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return nil
+	}
+	allowed, err := canViewBidOpening(h.DB, tender.ID, currentUser)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check tender participation: "+err.Error())
+		return nil
+	}
+	if !allowed {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement staff and suppliers that bid on this tender can view its bid opening.")
+		return nil
+	}
+
+	opening, err := loadBidOpening(h.DB, tender.ID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve bid opening: "+err.Error())
+		return nil
+	}
+	isSupplier := hasRole(currentUser, "supplier")
+	if opening == nil || (isSupplier && opening.Status != models.BidOpeningStatusOpened) {
+		RespondWithError(w, http.StatusNotFound, "The bids of this tender have not been opened yet.")
+		return nil
+	}
+	if opening.Status == models.BidOpeningStatusOpened &&
+		(opening.RegisterDigest == nil || !h.Signer.Verify(registerPayload(opening, opening.Register), *opening.RegisterDigest)) {
+		log.Printf("loadVisibleBidOpening: register of BidOpeningID %d does not match its digest", opening.ID)
+		RespondWithError(w, http.StatusConflict, "The opening register does not match the register recorded at the opening.")
+		return nil
+	}
+	if isSupplier {
+		opening.Attendees = nil
+	}
+	return opening
+}
+
+// OpenTenderBids records the current panel member joining the bid opening of a closed tender.
+// Once a majority of the panel has joined, after the bid opening date, every bid is unsealed at
+// once, the opening register is recorded and the tender moves into evaluation. Each member joins
+// with their own request; the response is 202 while the opening still awaits its quorum and 200
+// once the bids are open. A member who has already joined may repeat the request once the quorum
+// is met, which finishes an opening whose unsealing or register failed part-way.
+// POST /api/tenders/{id}/open
+func (h *BidHandler) OpenTenderBids(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	tenderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	var tender models.Tender
+	if err := h.DB.First(&tender, tenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return
+	}
+	switch status := tenderStatusOf(&tender); status {
+	case models.TenderStatusClosed:
+	case models.TenderStatusEvaluation, models.TenderStatusAwarded:
+		RespondWithError(w, http.StatusConflict, "The bids of this tender have already been opened.")
+		return
+	default:
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Bids can only be opened once the tender is closed (current status: %s).", status))
+		return
+	}
+	now := time.Now().UTC()
+	opensAt := bidOpeningTime(&tender)
+	if opensAt == nil {
+		RespondWithError(w, http.StatusConflict, "The tender has no bid opening date.")
+		return
+	}
+	if now.Before(*opensAt) {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Bids cannot be opened before the bid opening at %s.", opensAt.UTC().Format(time.RFC3339)))
+		return
+	}
+
+	// Only cleared members of the panel attend; a procurement officer cannot open bids alone
+	if err := checkPanelClearance(h.DB, tender.ID, currentUser.ID); err != nil {
+		var clearanceErr *panelClearanceError
+		if errors.As(err, &clearanceErr) {
+			RespondWithError(w, http.StatusForbidden, "Forbidden: "+clearanceErr.Error()+".")
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to check evaluation panel clearance: "+err.Error())
+		}
+		return
+	}
+	panel, err := activePanelForTender(h.DB, tender.ID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve evaluation panel: "+err.Error())
+		return
+	}
+
+	var attendeeCount int64
+	var opening models.BidOpening
+	var rejoined bool
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		started, err := startBidOpening(tx, tender.ID, panel.ID)
+		if err != nil {
+			return err
+		}
+		opening = *started
+		if opening.Status == models.BidOpeningStatusOpened {
+			return errBidsAlreadyOpened
+		}
+
+		var joined int64
+		if err := tx.Model(&models.BidOpeningAttendee{}).Where("bid_opening_id = ? AND user_id = ?", opening.ID, currentUser.ID).Count(&joined).Error; err != nil {
+			return err
+		}
+		rejoined = joined > 0
+		if !rejoined {
+			attendee := models.BidOpeningAttendee{BidOpeningID: opening.ID, UserID: currentUser.ID, JoinedAt: now}
+			if err := tx.Create(&attendee).Error; err != nil {
+				return err
+			}
+		}
+		// Only attendees still on the panel count towards the quorum
+		return tx.Model(&models.BidOpeningAttendee{}).
+			Where("bid_opening_id = ? AND user_id IN (?)", opening.ID,
+				tx.Model(&models.UserEvaluationPanelMembership{}).Select("user_id").Where("evaluation_panel_id = ?", panel.ID)).
+			Count(&attendeeCount).Error
+	})
+	if err != nil {
+		if errors.Is(err, errBidsAlreadyOpened) {
+			RespondWithError(w, http.StatusConflict, "The bids of this tender have already been opened.")
+		} else if strings.Contains(strings.ToLower(err.Error()), "unique") {
+			RespondWithError(w, http.StatusConflict, "You have already joined this bid opening.")
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to record attendance: "+err.Error())
+		}
+		return
+	}
+	if rejoined && attendeeCount < int64(opening.Quorum) {
+		RespondWithError(w, http.StatusConflict, "You have already joined this bid opening.")
+		return
+	}
+	if rejoined {
+		log.Printf("OpenTenderBids: UserID %d retried BidOpeningID %d of TenderID %d, which has its quorum (%d of %d needed)", currentUser.ID, opening.ID, tender.ID, attendeeCount, opening.Quorum)
+	} else {
+		log.Printf("OpenTenderBids: UserID %d joined BidOpeningID %d of TenderID %d (%d of %d needed)", currentUser.ID, opening.ID, tender.ID, attendeeCount, opening.Quorum)
+	}
+
+	if attendeeCount < int64(opening.Quorum) {
+		latest, err := loadBidOpening(h.DB, tender.ID)
+		if err != nil {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve bid opening: "+err.Error())
+			return
+		}
+		RespondWithJSON(w, http.StatusAccepted, latest)
+		return
+	}
+
+	// Quorum reached: unseal every bid, then record the register in one go. Both steps only act
+	// on what is still sealed or awaiting quorum, so a retry after a failure picks up where it stopped.
+	if err := unsealTenderBids(r.Context(), h.DB, h.Blobs, h.Sealer, &tender, now); err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to unseal bids: "+err.Error())
+		return
+	}
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.BidOpening{}).Where("id = ? AND status = ?", opening.ID, models.BidOpeningStatusAwaitingQuorum).
+			UpdateColumns(map[string]interface{}{"status": models.BidOpeningStatusOpened, "opened_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errBidsAlreadyOpened
+		}
+		opening.Status = models.BidOpeningStatusOpened
+		opening.OpenedAt = &now
+
+		entries, err := buildOpeningRegister(tx, &opening)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			if err := tx.Create(&entries).Error; err != nil {
+				return err
+			}
+		}
+		digest := h.Signer.Sign(registerPayload(&opening, entries))
+		if err := tx.Model(&models.BidOpening{}).Where("id = ?", opening.ID).UpdateColumn("register_digest", digest).Error; err != nil {
+			return err
+		}
+		// Opening the bids starts their evaluation
+		moved, err := advanceTenderStatus(tx, tender.ID, models.TenderStatusClosed, models.TenderStatusEvaluation, nil)
+		if err != nil {
+			return err
+		}
+		if !moved {
+			return errTenderNotClosed
+		}
+		return nil
+	})
+	if errors.Is(err, errTenderNotClosed) {
+		RespondWithError(w, http.StatusConflict, "The tender changed status while its bids were being opened.")
+		return
+	}
+	// Another member reaching the quorum at the same moment has opened the bids; return the result
+	if err != nil && !errors.Is(err, errBidsAlreadyOpened) {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to record opening register: "+err.Error())
+		return
+	}
+	if err == nil {
+		log.Printf("OpenTenderBids: bids of TenderID %d opened by BidOpeningID %d", tender.ID, opening.ID)
+	}
+
+	latest, err := loadBidOpening(h.DB, tender.ID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve bid opening: "+err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, latest)
+}
+
+// GetBidOpening returns the tender's bid opening with its attendees and, once opened, its register.
+// GET /api/tenders/{id}/opening
+func (h *BidHandler) GetBidOpening(w http.ResponseWriter, r *http.Request) {
+	opening := h.loadVisibleBidOpening(w, r)
+	if opening == nil {
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, opening)
+}
+
+// DownloadOpeningRegister downloads the opening register of a tender as CSV.
+// GET /api/tenders/{id}/opening/register
+func (h *BidHandler) DownloadOpeningRegister(w http.ResponseWriter, r *http.Request) {
+	opening := h.loadVisibleBidOpening(w, r)
+	if opening == nil {
+		return
+	}
+	if opening.Status != models.BidOpeningStatusOpened {
+		RespondWithError(w, http.StatusNotFound, "The bids of this tender have not been opened yet.")
+		return
+	}
+
+	fileName := fmt.Sprintf("tender-%d-opening-register.csv", opening.TenderID)
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	w.Header().Set("X-Register-Digest", *opening.RegisterDigest)
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"Tender ID", strconv.FormatInt(opening.TenderID, 10), "Opened at", opening.OpenedAt.UTC().Format(time.RFC3339)})
+	writer.Write([]string{"Bid ID", "Bidder", "Supplier ID", "Bid amount", "Received at", "Documents"})
+	for _, entry := range opening.Register {
+		writer.Write([]string{
+			strconv.FormatInt(entry.BidID, 10),
+			entry.SupplierName,
+			strconv.FormatInt(entry.SupplierID, 10),
+			strconv.FormatFloat(entry.BidAmount, 'f', 2, 64),
+			entry.ReceivedAt.UTC().Format(time.RFC3339),
+			strings.Join(entry.Documents, "; "),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Printf("DownloadOpeningRegister: failed to write register of TenderID %d: %v", opening.TenderID, err)
+	}
+}
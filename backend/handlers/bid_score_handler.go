@@ -0,0 +1,435 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"procurement/models"
+)
+
+// defaultCriterionMaxScore is the score scale used when a criterion has no sub-criteria max score.
+const defaultCriterionMaxScore = 100.0
+
+var (
+	// errScoresSubmitted stops a change to an evaluator's scores for a bid once they are submitted.
+	errScoresSubmitted = errors.New("bid scores already submitted")
+	// errScoresIncomplete stops the submission of a score sheet that leaves a criterion unscored.
+	errScoresIncomplete = errors.New("bid scores incomplete")
+)
+
+// ScoreInput is one entry of a score sheet submitted by an evaluator.
+type ScoreInput struct {
+	CriterionID int64   `json:"criterion_id"`
+	Score       float64 `json:"score"`
+	Comments    *string `json:"comments,omitempty"`
+}
+
+// EvaluatorTotal is the weighted total one evaluator gave a bid.
+type EvaluatorTotal struct {
+	EvaluatorID   int64   `json:"evaluator_id"`
+	WeightedTotal float64 `json:"weighted_total"`
+	Submitted     bool    `json:"submitted"`
+}
+
+// BidScoreSummary aggregates the scores of a bid.
+// WeightedTotal is the average of the submitted evaluators' weighted totals (out of 100).
+type BidScoreSummary struct {
+	BidID              int64                        `json:"bid_id"`
+	SupplierID         int64                        `json:"supplier_id"`
+	WeightedTotal      float64                      `json:"weighted_total"`
+	SubmittedCount     int                          `json:"submitted_count"`
+	EvaluatorTotals    []EvaluatorTotal             `json:"evaluator_totals"`
+	Scores             []models.BidEvaluationResult `json:"scores,omitempty"`
+	FailedMandatoryIDs []int64                      `json:"failed_mandatory_criteria,omitempty"`
+}
+
+// criterionMaxScore returns the top of the score scale for a criterion.
+func criterionMaxScore(criterion *models.TenderEvaluationCriterion) float64 {
+	if criterion.SubCriteriaMaxScore != nil && *criterion.SubCriteriaMaxScore > 0 {
+		return float64(*criterion.SubCriteriaMaxScore)
+	}
+	return defaultCriterionMaxScore
+}
+
+// summarizeBidScores computes per-evaluator and averaged weighted totals for a bid.
+// Only submitted results count towards the averaged total; a mandatory criterion scored
+// zero by any submitted evaluator is reported as failed.
+func summarizeBidScores(bid *models.Bid, criteria map[int64]*models.TenderEvaluationCriterion, results []models.BidEvaluationResult) BidScoreSummary {
+	summary := BidScoreSummary{BidID: bid.ID, SupplierID: bid.SupplierID, EvaluatorTotals: []EvaluatorTotal{}}
+
+	totals := make(map[int64]*EvaluatorTotal)
+	failed := make(map[int64]bool)
+	for _, result := range results {
+		criterion, ok := criteria[result.CriterionID]
+		if !ok {
+			continue
+		}
+		total, ok := totals[result.EvaluatorID]
+		if !ok {
+			total = &EvaluatorTotal{EvaluatorID: result.EvaluatorID, Submitted: true}
+			totals[result.EvaluatorID] = total
+		}
+		total.WeightedTotal += result.Score / criterionMaxScore(criterion) * criterion.Weight
+		if result.SubmittedAt == nil {
+			total.Submitted = false
+		} else if criterion.IsMandatory && result.Score == 0 {
+			failed[criterion.ID] = true
+		}
+	}
+
+	var sum float64
+	for _, total := range totals {
+		summary.EvaluatorTotals = append(summary.EvaluatorTotals, *total)
+		if total.Submitted {
+			sum += total.WeightedTotal
+			summary.SubmittedCount++
+		}
+	}
+	sort.Slice(summary.EvaluatorTotals, func(i, j int) bool {
+		return summary.EvaluatorTotals[i].EvaluatorID < summary.EvaluatorTotals[j].EvaluatorID
+	})
+	if summary.SubmittedCount > 0 {
+		summary.WeightedTotal = sum / float64(summary.SubmittedCount)
+	}
+	for criterionID := range failed {
+		summary.FailedMandatoryIDs = append(summary.FailedMandatoryIDs, criterionID)
+	}
+	sort.Slice(summary.FailedMandatoryIDs, func(i, j int) bool { return summary.FailedMandatoryIDs[i] < summary.FailedMandatoryIDs[j] })
+	return summary
+}
+
+// loadTenderCriteria returns the criteria of a tender keyed by ID.
+func loadTenderCriteria(db *gorm.DB, tenderID int64) (map[int64]*models.TenderEvaluationCriterion, error) {
+	var criteria []models.TenderEvaluationCriterion
+	if err := db.Where("tender_id = ?", tenderID).Find(&criteria).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*models.TenderEvaluationCriterion, len(criteria))
+	for i := range criteria {
+		byID[criteria[i].ID] = &criteria[i]
+	}
+	return byID, nil
+}
+
+// loadScorableBid resolves the tender and bid from the URL and checks that the tender has
+// closed for submissions, so scoring never starts while bidding is still open, and that the
+// user is cleared by the tender's evaluation panel.
+func (h *EvaluationHandler) loadScorableBid(w http.ResponseWriter, r *http.Request, user *models.User) (*models.Tender, *models.Bid, bool) {
+	tenderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return nil, nil, false
+	}
+	bidID, ok := parseIDParam(w, r, "bidId")
+	if !ok {
+		return nil, nil, false
+	}
+
+	var tender models.Tender
+	if err := h.DB.First(&tender, tenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return nil, nil, false
+	}
+	var bid models.Bid
+	if err := h.DB.Where("id = ? AND tender_id = ?", bidID, tenderID).First(&bid).Error; err != nil {
+		respondWithLookupError(w, err, "Bid")
+		return nil, nil, false
+	}
+
+	if status := tenderStatusOf(&tender); status != models.TenderStatusEvaluation {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Bids can only be scored while the tender is in evaluation (current status: %s).", status))
+		return nil, nil, false
+	}
+	if !isActiveBid(&bid) {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("A %s bid cannot be scored.", bid.Status))
+		return nil, nil, false
+	}
+	if bid.Sealed {
+		RespondWithError(w, http.StatusConflict, "Bids cannot be scored until they are opened.")
+		return nil, nil, false
+	}
+	if !requirePanelClearance(h.DB, w, tender.ID, user) {
+		return nil, nil, false
+	}
+	return &tender, &bid, true
+}
+
+// GetBidScores returns the scores for a bid with weighted totals.
+// Evaluators see only their own scores; procurement officers see every evaluator's.
+// GET /api/tenders/{id}/bids/{bidId}/scores
+func (h *EvaluationHandler) GetBidScores(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "evaluator", "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only evaluators and procurement officers can view bid scores.")
+		return
+	}
+
+	tender, bid, ok := h.loadScorableBid(w, r, currentUser)
+	if !ok {
+		return
+	}
+
+	criteria, err := loadTenderCriteria(h.DB, tender.ID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve evaluation criteria: "+err.Error())
+		return
+	}
+
+	query := h.DB.Preload("Criterion").Where("bid_id = ?", bid.ID)
+	if !hasRole(currentUser, "procurement_officer") {
+		query = query.Where("evaluator_id = ?", currentUser.ID)
+	}
+	var results []models.BidEvaluationResult
+	if err := query.Order("evaluator_id ASC, criterion_id ASC").Find(&results).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve bid scores: "+err.Error())
+		return
+	}
+
+	summary := summarizeBidScores(bid, criteria, results)
+	summary.Scores = results
+	RespondWithJSON(w, http.StatusOK, summary)
+}
+
+// SaveBidScores creates or updates the current evaluator's scores for a bid.
+// Scores can be changed until the evaluator submits them.
+// PUT /api/tenders/{id}/bids/{bidId}/scores
+func (h *EvaluationHandler) SaveBidScores(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "evaluator") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only evaluators can score bids.")
+		return
+	}
+
+	tender, bid, ok := h.loadScorableBid(w, r, currentUser)
+	if !ok {
+		return
+	}
+
+	var inputs []ScoreInput
+	if err := json.NewDecoder(r.Body).Decode(&inputs); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if len(inputs) == 0 {
+		RespondWithError(w, http.StatusBadRequest, "At least one score is required.")
+		return
+	}
+
+	criteria, err := loadTenderCriteria(h.DB, tender.ID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve evaluation criteria: "+err.Error())
+		return
+	}
+	for i, input := range inputs {
+		criterion, ok := criteria[input.CriterionID]
+		if !ok {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Score %d references criterion %d, which does not belong to this tender.", i+1, input.CriterionID))
+			return
+		}
+		if maxScore := criterionMaxScore(criterion); input.Score < 0 || input.Score > maxScore {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Score %d must be between 0 and %g.", i+1, maxScore))
+			return
+		}
+	}
+
+	// The check and the saves share a transaction, and a submitted score is never overwritten, so
+	// a save racing the evaluator's own submission cannot change the submitted scores.
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var submittedCount int64
+		if err := tx.Model(&models.BidEvaluationResult{}).
+			Where("bid_id = ? AND evaluator_id = ? AND submitted_at IS NOT NULL", bid.ID, currentUser.ID).
+			Count(&submittedCount).Error; err != nil {
+			return err
+		}
+		if submittedCount > 0 {
+			return errScoresSubmitted
+		}
+
+		for _, input := range inputs {
+			result := models.BidEvaluationResult{
+				BidID:       bid.ID,
+				CriterionID: input.CriterionID,
+				EvaluatorID: currentUser.ID,
+				Score:       input.Score,
+				Comments:    input.Comments,
+			}
+			saved := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "bid_id"}, {Name: "criterion_id"}, {Name: "evaluator_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"score", "comments", "evaluation_date"}),
+				Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "submitted_at IS NULL"}}},
+			}).Create(&result)
+			if saved.Error != nil {
+				return saved.Error
+			}
+			if saved.RowsAffected == 0 {
+				return errScoresSubmitted
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errScoresSubmitted) {
+		RespondWithError(w, http.StatusConflict, "Your scores for this bid have been submitted and can no longer be changed.")
+		return
+	}
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to save bid scores: "+err.Error())
+		return
+	}
+	log.Printf("SaveBidScores: EvaluatorID %d saved %d scores for BidID %d", currentUser.ID, len(inputs), bid.ID)
+
+	var results []models.BidEvaluationResult
+	if err := h.DB.Where("bid_id = ? AND evaluator_id = ?", bid.ID, currentUser.ID).Order("criterion_id ASC").Find(&results).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve bid scores: "+err.Error())
+		return
+	}
+	summary := summarizeBidScores(bid, criteria, results)
+	summary.Scores = results
+	RespondWithJSON(w, http.StatusOK, summary)
+}
+
+// SubmitBidScores locks the current evaluator's scores for a bid.
+// Every criterion of the tender must have been scored.
+// POST /api/tenders/{id}/bids/{bidId}/scores/submit
+func (h *EvaluationHandler) SubmitBidScores(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "evaluator") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only evaluators can submit bid scores.")
+		return
+	}
+
+	tender, bid, ok := h.loadScorableBid(w, r, currentUser)
+	if !ok {
+		return
+	}
+
+	criteria, err := loadTenderCriteria(h.DB, tender.ID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve evaluation criteria: "+err.Error())
+		return
+	}
+
+	now := time.Now()
+	var results []models.BidEvaluationResult
+	var unscoredCriterionID int64
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bid_id = ? AND evaluator_id = ?", bid.ID, currentUser.ID).Find(&results).Error; err != nil {
+			return err
+		}
+		scored := make(map[int64]bool, len(results))
+		for _, result := range results {
+			if result.SubmittedAt != nil {
+				return errScoresSubmitted
+			}
+			scored[result.CriterionID] = true
+		}
+		for criterionID := range criteria {
+			if !scored[criterionID] {
+				unscoredCriterionID = criterionID
+				return errScoresIncomplete
+			}
+		}
+
+		// Only scores still open are locked, so two submissions at once cannot both succeed
+		submitted := tx.Model(&models.BidEvaluationResult{}).
+			Where("bid_id = ? AND evaluator_id = ? AND submitted_at IS NULL", bid.ID, currentUser.ID).
+			Update("submitted_at", now)
+		if submitted.Error != nil {
+			return submitted.Error
+		}
+		if submitted.RowsAffected == 0 && len(results) > 0 {
+			return errScoresSubmitted
+		}
+		return nil
+	})
+	if errors.Is(err, errScoresSubmitted) {
+		RespondWithError(w, http.StatusConflict, "Your scores for this bid have already been submitted.")
+		return
+	}
+	if errors.Is(err, errScoresIncomplete) {
+		RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Criterion %d has not been scored yet.", unscoredCriterionID))
+		return
+	}
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to submit bid scores: "+err.Error())
+		return
+	}
+	log.Printf("SubmitBidScores: EvaluatorID %d submitted scores for BidID %d", currentUser.ID, bid.ID)
+
+	for i := range results {
+		results[i].SubmittedAt = &now
+	}
+	summary := summarizeBidScores(bid, criteria, results)
+	summary.Scores = results
+	RespondWithJSON(w, http.StatusOK, summary)
+}
+
+// ListTenderScores returns the aggregated weighted totals of every active bid of a tender, highest
+// first. Withdrawn and superseded bids are left out of the ranking.
+// GET /api/tenders/{id}/scores
+func (h *EvaluationHandler) ListTenderScores(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can view tender score rankings.")
+		return
+	}
+
+	tenderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	var tender models.Tender
+	if err := h.DB.First(&tender, tenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return
+	}
+
+	criteria, err := loadTenderCriteria(h.DB, tender.ID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve evaluation criteria: "+err.Error())
+		return
+	}
+
+	var bids []models.Bid
+	if err := h.DB.Where("tender_id = ? AND status NOT IN ?", tender.ID, inactiveBidStatuses).Find(&bids).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve bids: "+err.Error())
+		return
+	}
+
+	var results []models.BidEvaluationResult
+	if err := h.DB.Joins("JOIN bids ON bids.id = bid_evaluation_results.bid_id").
+		Where("bids.tender_id = ?", tender.ID).Find(&results).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve bid scores: "+err.Error())
+		return
+	}
+	resultsByBid := make(map[int64][]models.BidEvaluationResult)
+	for _, result := range results {
+		resultsByBid[result.BidID] = append(resultsByBid[result.BidID], result)
+	}
+
+	summaries := make([]BidScoreSummary, 0, len(bids))
+	for i := range bids {
+		summaries = append(summaries, summarizeBidScores(&bids[i], criteria, resultsByBid[bids[i].ID]))
+	}
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].WeightedTotal > summaries[j].WeightedTotal })
+
+	RespondWithJSON(w, http.StatusOK, summaries)
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"procurement/models"
+	"procurement/services"
+)
+
+// sealedBidContent is the part of a bid that is kept encrypted until bid opening.
+type sealedBidContent struct {
+	BidAmount  float64           `json:"bid_amount"`
+	ItemPrices map[int64]float64 `json:"item_prices"` // Offered unit price by bid item ID
+}
+
+// bidsSealedError reports that a tender's bids cannot be revealed yet.
+type bidsSealedError struct {
+	opensAt *time.Time
+}
+
+func (e *bidsSealedError) Error() string {
+	if e.opensAt == nil {
+		return "Bids are sealed until the tender has a bid opening date."
+	}
+	return fmt.Sprintf("Bids are sealed until the bid opening at %s.", e.opensAt.UTC().Format(time.RFC3339))
+}
+
+// bidOpeningTime is when a tender's bids may be opened: its bid opening date, or its closing date
+// when no separate opening date is set.
+func bidOpeningTime(tender *models.Tender) *time.Time {
+	if tender.BidOpeningDate != nil {
+		return tender.BidOpeningDate
+	}
+	return tender.ClosingDate
+}
+
+// tenderSealingPublicKey returns the public key a tender's bids are sealed to, creating the
+// tender's key pair with its first bid.
+func tenderSealingPublicKey(db *gorm.DB, sealer services.BidSealingService, tenderID int64) ([]byte, error) {
+	var key models.TenderSealingKey
+	err := db.First(&key, tenderID).Error
+	if err == nil {
+		return key.PublicKey, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	publicKey, wrappedPrivateKey, err := sealer.NewKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	key = models.TenderSealingKey{TenderID: tenderID, PublicKey: publicKey, WrappedPrivateKey: wrappedPrivateKey}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&key).Error; err != nil {
+		return nil, err
+	}
+	// Another submission may have stored a key first; every bid must use the stored one
+	if err := db.First(&key, tenderID).Error; err != nil {
+		return nil, err
+	}
+	return key.PublicKey, nil
+}
+
+// sealBidContent encrypts a bid's amount and item prices to the tender's key.
+func sealBidContent(sealer services.BidSealingService, publicKey []byte, content *sealedBidContent) ([]byte, error) {
+	plaintext, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return sealer.Seal(publicKey, plaintext)
+}
+
+// bidAttachmentSealer returns the function that seals documents attached to a bid, or nil when the
+// bid is no longer sealed.
+func bidAttachmentSealer(db *gorm.DB, sealer services.BidSealingService, bidID int64) (func([]byte) ([]byte, error), error) {
+	var bid models.Bid
+	if err := db.Select("id", "tender_id", "sealed").First(&bid, bidID).Error; err != nil {
+		return nil, err
+	}
+	if !bid.Sealed {
+		return nil, nil
+	}
+	publicKey, err := tenderSealingPublicKey(db, sealer, bid.TenderID)
+	if err != nil {
+		return nil, err
+	}
+	return func(plaintext []byte) ([]byte, error) { return sealer.Seal(publicKey, plaintext) }, nil
+}
+
+// unsealAttachment decrypts a sealed attachment into a new object, switches the record over to it
+// and removes the sealed object.
+func unsealAttachment(ctx context.Context, db *gorm.DB, blobs services.BlobStore, sealer services.BidSealingService, wrappedPrivateKey []byte, attachment *models.Attachment) error {
+	object, err := blobs.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		return err
+	}
+	sealed, err := io.ReadAll(object)
+	object.Close()
+	if err != nil {
+		return err
+	}
+	plaintext, err := sealer.Open(wrappedPrivateKey, sealed)
+	if err != nil {
+		return fmt.Errorf("failed to unseal attachment %d: %w", attachment.ID, err)
+	}
+
+	key := newBlobKey(attachment.FileName, "attachments", attachment.EntityType, strconv.FormatInt(attachment.EntityID, 10))
+	if err := blobs.Put(ctx, key, bytes.NewReader(plaintext), int64(len(plaintext)), attachment.ContentType); err != nil {
+		return err
+	}
+	result := db.Model(&models.Attachment{}).Where("id = ? AND sealed = ?", attachment.ID, true).
+		UpdateColumns(map[string]interface{}{"storage_key": key, "sealed": false})
+	if result.Error != nil || result.RowsAffected == 0 {
+		// Failed, or another request unsealed it first
+		blobs.Delete(ctx, key)
+		return result.Error
+	}
+	if err := blobs.Delete(ctx, attachment.StorageKey); err != nil {
+		log.Printf("unsealAttachment: AttachmentID %d unsealed but its sealed copy could not be removed: %v", attachment.ID, err)
+	}
+	return nil
+}
+
+// unsealTenderBids opens every sealed bid of a tender at its bid opening: bid
+// amounts and item prices are restored from the sealed content and sealed documents are replaced
+// by their decrypted content. Before the opening time it returns a *bidsSealedError. It is safe to
+// call repeatedly; only bids and documents still sealed are touched.
+func unsealTenderBids(ctx context.Context, db *gorm.DB, blobs services.BlobStore, sealer services.BidSealingService, tender *models.Tender, now time.Time) error {
+	opensAt := bidOpeningTime(tender)
+	if opensAt == nil || now.Before(*opensAt) {
+		return &bidsSealedError{opensAt: opensAt}
+	}
+	var key models.TenderSealingKey
+	err := db.First(&key, tender.ID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil // No bid was ever sealed
+	}
+	if err != nil {
+		return err
+	}
+
+	var attachments []models.Attachment
+	err = db.Where("entity_type = ? AND sealed = ? AND entity_id IN (?)", models.AttachmentEntityBid, true,
+		db.Model(&models.Bid{}).Select("id").Where("tender_id = ?", tender.ID)).Find(&attachments).Error
+	if err != nil {
+		return err
+	}
+	for i := range attachments {
+		if err := unsealAttachment(ctx, db, blobs, sealer, key.WrappedPrivateKey, &attachments[i]); err != nil {
+			return err
+		}
+	}
+
+	var bids []models.Bid
+	if err := db.Where("tender_id = ? AND sealed = ?", tender.ID, true).Find(&bids).Error; err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, bid := range bids {
+			plaintext, err := sealer.Open(key.WrappedPrivateKey, bid.SealedContent)
+			if err != nil {
+				return fmt.Errorf("failed to unseal bid %d: %w", bid.ID, err)
+			}
+			var content sealedBidContent
+			if err := json.Unmarshal(plaintext, &content); err != nil {
+				return fmt.Errorf("failed to read sealed bid %d: %w", bid.ID, err)
+			}
+			for itemID, price := range content.ItemPrices {
+				if err := tx.Model(&models.BidItem{}).Where("id = ? AND bid_id = ?", itemID, bid.ID).UpdateColumn("offered_unit_price", price).Error; err != nil {
+					return err
+				}
+			}
+			err = tx.Model(&models.Bid{}).Where("id = ? AND sealed = ?", bid.ID, true).
+				UpdateColumns(map[string]interface{}{"bid_amount": content.BidAmount, "sealed": false, "sealed_content": nil}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.TenderSealingKey{}).Where("tender_id = ? AND released_at IS NULL", tender.ID).
+			UpdateColumn("released_at", now).Error
+	})
+	if err != nil {
+		return err
+	}
+	if len(bids) > 0 || len(attachments) > 0 {
+		log.Printf("unsealTenderBids: %d bids and %d documents of TenderID %d unsealed", len(bids), len(attachments), tender.ID)
+	}
+	return nil
+}
@@ -93,7 +93,7 @@ func GetRequisitionStatsHandler(w http.ResponseWriter, r *http.Request) {
 	stats := DashboardStats{}
 
 	// Count requisitions pending approval
-	db.Model(&models.Requisition{}).Where("status IN (?)", []string{string(models.RequisitionStatusPendingApproval1), string(models.RequisitionStatusPendingApproval2)}).Count(&stats.PendingApproval)
+	db.Model(&models.Requisition{}).Where("status = ?", models.RequisitionStatusPendingApproval).Count(&stats.PendingApproval)
 
 	// Count requisitions ready for tender
 	db.Model(&models.Requisition{}).Where("status IN (?)", []string{string(models.RequisitionStatusApproved), string(models.RequisitionStatusPendingTender)}).Count(&stats.ReadyForTender)
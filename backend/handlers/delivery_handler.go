@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// errOverReceipt is returned from the receipt transaction when a delivery would exceed the ordered quantity.
+var errOverReceipt = errors.New("delivery exceeds the outstanding quantity")
+
+// DeliveryInput is the request body for recording a goods received note.
+type DeliveryInput struct {
+	PurchaseOrderItemID int64      `json:"purchase_order_item_id"`
+	QuantityReceived    float64    `json:"quantity_received"`
+	DeliveryDate        *time.Time `json:"delivery_date,omitempty"`
+	ComplianceStatus    *string    `json:"compliance_status,omitempty"`
+	Notes               *string    `json:"notes,omitempty"`
+}
+
+// acceptedQuantity returns the quantity accepted so far against a purchase order item: everything
+// received except goods rejected as non-compliant, which go back to the supplier to be replaced.
+func acceptedQuantity(db *gorm.DB, itemID int64) (float64, error) {
+	var total float64
+	err := db.Model(&models.Delivery{}).
+		Where("purchase_order_item_id = ? AND (compliance_status IS NULL OR compliance_status <> ?)", itemID, models.ComplianceStatusNonCompliant).
+		Select("COALESCE(SUM(quantity_received), 0)").Scan(&total).Error
+	return total, err
+}
+
+// deliveryStatusFor rolls an accepted quantity up into the delivery status of an item.
+func deliveryStatusFor(accepted, ordered float64) string {
+	switch {
+	case accepted <= 0:
+		return models.DeliveryStatusPending
+	case accepted < ordered:
+		return models.DeliveryStatusPartial
+	default:
+		return models.DeliveryStatusComplete
+	}
+}
+
+// ListDeliveries returns the goods received notes of a purchase order.
+// GET /api/purchase-orders/{id}/deliveries
+func (h *PurchaseOrderHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin", "requester", "supplier") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: You do not have access to deliveries.")
+		return
+	}
+	order, ok := h.loadReceivablePurchaseOrder(w, r, currentUser)
+	if !ok {
+		return
+	}
+
+	var deliveries []models.Delivery
+	if err := h.DB.Where("purchase_order_id = ?", order.ID).Order("delivery_date ASC, id ASC").Find(&deliveries).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve deliveries: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, deliveries)
+}
+
+// loadReceivablePurchaseOrder loads the purchase order from the {id} URL parameter for delivery
+// handlers. Requesters, who receive goods, may see any order that has been issued.
+func (h *PurchaseOrderHandler) loadReceivablePurchaseOrder(w http.ResponseWriter, r *http.Request, user *models.User) (*models.PurchaseOrder, bool) {
+	if !hasRole(user, "requester") {
+		return loadPurchaseOrder(h.DB, w, r, user)
+	}
+	orderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+	var order models.PurchaseOrder
+	if err := h.DB.Preload("Items").First(&order, orderID).Error; err != nil {
+		respondWithLookupError(w, err, "Purchase order")
+		return nil, false
+	}
+	if order.Status == models.PurchaseOrderStatusDraft {
+		RespondWithError(w, http.StatusNotFound, "Purchase order not found")
+		return nil, false
+	}
+	return &order, true
+}
+
+// CreateDelivery records a goods received note against one item of an issued purchase order.
+// Partial quantities are allowed; receiving more than is outstanding is rejected. Rejected goods
+// leave the quantity outstanding, so their replacement can be received. The item's delivery
+// status is rolled up in the same transaction.
+// POST /api/purchase-orders/{id}/deliveries
+func (h *PurchaseOrderHandler) CreateDelivery(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "requester") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and requesters can record deliveries.")
+		return
+	}
+	order, ok := h.loadReceivablePurchaseOrder(w, r, currentUser)
+	if !ok {
+		return
+	}
+	if order.Status != models.PurchaseOrderStatusIssued && order.Status != models.PurchaseOrderStatusAcknowledged {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Deliveries can only be recorded against an issued or acknowledged purchase order (current status: %s).", order.Status))
+		return
+	}
+
+	var input DeliveryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if input.QuantityReceived <= 0 {
+		RespondWithError(w, http.StatusBadRequest, "quantity_received must be greater than zero.")
+		return
+	}
+	if input.ComplianceStatus != nil && *input.ComplianceStatus != models.ComplianceStatusCompliant && *input.ComplianceStatus != models.ComplianceStatusNonCompliant {
+		RespondWithError(w, http.StatusBadRequest, "compliance_status must be 'compliant' or 'non_compliant'.")
+		return
+	}
+
+	var item *models.PurchaseOrderItem
+	for i := range order.Items {
+		if order.Items[i].ID == input.PurchaseOrderItemID {
+			item = &order.Items[i]
+			break
+		}
+	}
+	if item == nil {
+		RespondWithError(w, http.StatusBadRequest, "purchase_order_item_id does not belong to this purchase order.")
+		return
+	}
+
+	now := time.Now()
+	delivery := models.Delivery{
+		PurchaseOrderID:     order.ID,
+		PurchaseOrderItemID: &item.ID,
+		DeliveryDate:        now,
+		ReceivedByID:        &currentUser.ID,
+		QuantityReceived:    input.QuantityReceived,
+		ComplianceStatus:    input.ComplianceStatus,
+		Notes:               input.Notes,
+	}
+	if input.DeliveryDate != nil {
+		delivery.DeliveryDate = *input.DeliveryDate
+	}
+
+	var outstanding float64
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Touch the item first so this transaction holds the write lock before summing earlier
+		// receipts; two receivers cannot then both fit into the same outstanding quantity.
+		if err := tx.Model(&models.PurchaseOrderItem{}).Where("id = ?", item.ID).Update("updated_at", now).Error; err != nil {
+			return err
+		}
+		accepted, err := acceptedQuantity(tx, item.ID)
+		if err != nil {
+			return err
+		}
+		outstanding = item.Quantity - accepted
+		if input.QuantityReceived > outstanding+1e-9 {
+			return errOverReceipt
+		}
+
+		number, err := nextDocumentNumber(tx, "GRN", now)
+		if err != nil {
+			return err
+		}
+		delivery.GRNNumber = number
+		if err := tx.Create(&delivery).Error; err != nil {
+			return err
+		}
+
+		if delivery.ComplianceStatus == nil || *delivery.ComplianceStatus != models.ComplianceStatusNonCompliant {
+			accepted += delivery.QuantityReceived
+		}
+		item.DeliveryStatus = deliveryStatusFor(accepted, item.Quantity)
+		return tx.Model(&models.PurchaseOrderItem{}).Where("id = ?", item.ID).Update("delivery_status", item.DeliveryStatus).Error
+	})
+	if err != nil {
+		if errors.Is(err, errOverReceipt) {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Cannot receive %g %s: only %g of %g is outstanding for this item.", input.QuantityReceived, item.Unit, outstanding, item.Quantity))
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to record delivery: "+err.Error())
+		}
+		return
+	}
+	log.Printf("CreateDelivery: %s received %g of PurchaseOrderItemID %d on PurchaseOrderID %d by UserID %d (item now %s)",
+		delivery.GRNNumber, delivery.QuantityReceived, item.ID, order.ID, currentUser.ID, item.DeliveryStatus)
+
+	RespondWithJSON(w, http.StatusCreated, delivery)
+}
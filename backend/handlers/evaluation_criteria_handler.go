@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+	"procurement/services"
+)
+
+// requiredCriteriaWeight is the total weight the criteria of a tender must add up to.
+const requiredCriteriaWeight = 100.0
+
+// EvaluationHandler holds dependencies for tender evaluation handlers.
+type EvaluationHandler struct {
+	DB     *gorm.DB
+	Signer services.SignatureService
+}
+
+// NewEvaluationHandler creates a new EvaluationHandler with the given DB connection and signature service.
+func NewEvaluationHandler(db *gorm.DB, signer services.SignatureService) *EvaluationHandler {
+	return &EvaluationHandler{DB: db, Signer: signer}
+}
+
+// validateCriterion checks a single criterion's fields.
+func validateCriterion(criterion *models.TenderEvaluationCriterion) error {
+	criterion.Type = strings.ToLower(strings.TrimSpace(criterion.Type))
+	switch criterion.Type {
+	case models.CriterionTypeTechnical, models.CriterionTypeCommercial, models.CriterionTypeDelivery, models.CriterionTypeCompliance:
+	default:
+		return fmt.Errorf("criterion type must be one of 'technical', 'commercial', 'delivery' or 'compliance'")
+	}
+	if strings.TrimSpace(criterion.CriterionText) == "" {
+		return fmt.Errorf("criterion text is required")
+	}
+	if criterion.Weight < 0 || criterion.Weight > requiredCriteriaWeight {
+		return fmt.Errorf("criterion weight must be between 0 and 100")
+	}
+	if criterion.SubCriteriaMaxScore != nil && *criterion.SubCriteriaMaxScore <= 0 {
+		return fmt.Errorf("sub-criteria max score must be greater than zero")
+	}
+	return nil
+}
+
+// criteriaWeightTotal returns the summed weight of a tender's criteria, optionally leaving one criterion out.
+func criteriaWeightTotal(db *gorm.DB, tenderID int64, excludeID int64) (float64, error) {
+	var total float64
+	err := db.Model(&models.TenderEvaluationCriterion{}).
+		Where("tender_id = ? AND id <> ?", tenderID, excludeID).
+		Select("COALESCE(SUM(weight), 0)").Scan(&total).Error
+	return total, err
+}
+
+// checkCriteriaWeights makes sure there is at least one criterion and that the weights add up to 100.
+func checkCriteriaWeights(criteria []models.TenderEvaluationCriterion) error {
+	if len(criteria) == 0 {
+		return fmt.Errorf("tender has no evaluation criteria")
+	}
+	var total float64
+	for _, criterion := range criteria {
+		total += criterion.Weight
+	}
+	if math.Abs(total-requiredCriteriaWeight) > 0.001 {
+		return fmt.Errorf("evaluation criteria weights add up to %g, they must add up to 100", total)
+	}
+	return nil
+}
+
+// validateCriteriaForPublish checks the stored evaluation criteria of a tender before it is published.
+func validateCriteriaForPublish(db *gorm.DB, tenderID int64) error {
+	var criteria []models.TenderEvaluationCriterion
+	if err := db.Where("tender_id = ?", tenderID).Find(&criteria).Error; err != nil {
+		return err
+	}
+	return checkCriteriaWeights(criteria)
+}
+
+// ListCriteria returns the evaluation criteria of a tender.
+// GET /api/tenders/{id}/criteria
+func (h *EvaluationHandler) ListCriteria(w http.ResponseWriter, r *http.Request) {
+	tender := loadVisibleTender(h.DB, w, r)
+	if tender == nil {
+		return
+	}
+
+	var criteria []models.TenderEvaluationCriterion
+	if err := h.DB.Where("tender_id = ?", tender.ID).Order("type ASC, id ASC").Find(&criteria).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve evaluation criteria: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, criteria)
+}
+
+// CreateCriterion adds an evaluation criterion to a draft tender.
+// POST /api/tenders/{id}/criteria
+func (h *EvaluationHandler) CreateCriterion(w http.ResponseWriter, r *http.Request) {
+	tender := loadDraftTender(h.DB, w, r, "evaluation criteria")
+	if tender == nil {
+		return
+	}
+
+	var criterion models.TenderEvaluationCriterion
+	if err := json.NewDecoder(r.Body).Decode(&criterion); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if err := validateCriterion(&criterion); err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	total, err := criteriaWeightTotal(h.DB, tender.ID, 0)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to sum criteria weights: "+err.Error())
+		return
+	}
+	if total+criterion.Weight > requiredCriteriaWeight+0.001 {
+		RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Criteria weights would add up to %g, which exceeds 100.", total+criterion.Weight))
+		return
+	}
+
+	criterion.ID = 0
+	criterion.TenderID = tender.ID
+	if err := h.DB.Create(&criterion).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to create evaluation criterion: "+err.Error())
+		return
+	}
+	log.Printf("CreateCriterion: Created CriterionID %d for TenderID %d", criterion.ID, tender.ID)
+
+	RespondWithJSON(w, http.StatusCreated, criterion)
+}
+
+// UpdateCriterion changes an evaluation criterion of a draft tender.
+// PUT /api/tenders/{id}/criteria/{criterionId}
+func (h *EvaluationHandler) UpdateCriterion(w http.ResponseWriter, r *http.Request) {
+	tender := loadDraftTender(h.DB, w, r, "evaluation criteria")
+	if tender == nil {
+		return
+	}
+	criterionID, ok := parseIDParam(w, r, "criterionId")
+	if !ok {
+		return
+	}
+
+	var criterion models.TenderEvaluationCriterion
+	if err := h.DB.Where("id = ? AND tender_id = ?", criterionID, tender.ID).First(&criterion).Error; err != nil {
+		respondWithLookupError(w, err, "Evaluation criterion")
+		return
+	}
+
+	var input models.TenderEvaluationCriterion
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if err := validateCriterion(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	total, err := criteriaWeightTotal(h.DB, tender.ID, criterion.ID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to sum criteria weights: "+err.Error())
+		return
+	}
+	if total+input.Weight > requiredCriteriaWeight+0.001 {
+		RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Criteria weights would add up to %g, which exceeds 100.", total+input.Weight))
+		return
+	}
+
+	criterion.Type = input.Type
+	criterion.CriterionText = input.CriterionText
+	criterion.Weight = input.Weight
+	criterion.SubCriteriaName = input.SubCriteriaName
+	criterion.SubCriteriaMaxScore = input.SubCriteriaMaxScore
+	criterion.IsMandatory = input.IsMandatory
+	if err := h.DB.Save(&criterion).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update evaluation criterion: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, criterion)
+}
+
+// DeleteCriterion removes an evaluation criterion from a draft tender.
+// DELETE /api/tenders/{id}/criteria/{criterionId}
+func (h *EvaluationHandler) DeleteCriterion(w http.ResponseWriter, r *http.Request) {
+	tender := loadDraftTender(h.DB, w, r, "evaluation criteria")
+	if tender == nil {
+		return
+	}
+	criterionID, ok := parseIDParam(w, r, "criterionId")
+	if !ok {
+		return
+	}
+
+	result := h.DB.Where("id = ? AND tender_id = ?", criterionID, tender.ID).Delete(&models.TenderEvaluationCriterion{})
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to delete evaluation criterion: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusNotFound, "Evaluation criterion not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,421 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// panelClearanceError explains why a user may not see a tender's bids.
+type panelClearanceError struct {
+	message string
+}
+
+func (e *panelClearanceError) Error() string { return e.message }
+
+// errNotPanelMember is returned by checkPanelClearance when the user is not on the tender's active panel.
+var errNotPanelMember = &panelClearanceError{"you are not a member of this tender's evaluation panel"}
+
+// PanelMemberInput is the request body for assigning a user to a panel.
+type PanelMemberInput struct {
+	UserID      int64   `json:"user_id"`
+	RoleInPanel *string `json:"role_in_panel,omitempty"`
+}
+
+// DeclarationInput is one entry of a conflict-of-interest declaration filed by a panel member.
+type DeclarationInput struct {
+	SupplierID  int64   `json:"supplier_id"`
+	HasConflict bool    `json:"has_conflict"`
+	Details     *string `json:"details,omitempty"`
+}
+
+// activePanelForTender returns the active evaluation panel of a tender.
+func activePanelForTender(db *gorm.DB, tenderID int64) (*models.EvaluationPanel, error) {
+	var panel models.EvaluationPanel
+	if err := db.Where("tender_id = ? AND status = ?", tenderID, "active").First(&panel).Error; err != nil {
+		return nil, err
+	}
+	return &panel, nil
+}
+
+// biddingSupplierIDs returns the distinct suppliers that have bid on a tender.
+func biddingSupplierIDs(db *gorm.DB, tenderID int64) ([]int64, error) {
+	var supplierIDs []int64
+	err := db.Model(&models.Bid{}).Where("tender_id = ?", tenderID).Distinct().Pluck("supplier_id", &supplierIDs).Error
+	return supplierIDs, err
+}
+
+// isPanelMember reports whether a user sits on a panel.
+func isPanelMember(db *gorm.DB, panelID, userID int64) (bool, error) {
+	var count int64
+	err := db.Model(&models.UserEvaluationPanelMembership{}).
+		Where("evaluation_panel_id = ? AND user_id = ?", panelID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+// checkPanelClearance verifies that a user sits on the tender's active panel and has declared,
+// without conflict, against every supplier that bid on the tender.
+func checkPanelClearance(db *gorm.DB, tenderID, userID int64) error {
+	panel, err := activePanelForTender(db, tenderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errNotPanelMember
+	} else if err != nil {
+		return err
+	}
+
+	isMember, err := isPanelMember(db, panel.ID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return errNotPanelMember
+	}
+
+	supplierIDs, err := biddingSupplierIDs(db, tenderID)
+	if err != nil {
+		return err
+	}
+	var declarations []models.ConflictOfInterestDeclaration
+	if err := db.Where("evaluation_panel_id = ? AND user_id = ?", panel.ID, userID).Find(&declarations).Error; err != nil {
+		return err
+	}
+	declared := make(map[int64]bool, len(declarations))
+	for _, declaration := range declarations {
+		if declaration.HasConflict {
+			return &panelClearanceError{fmt.Sprintf("you have declared a conflict of interest with supplier %d and are excluded from this evaluation", declaration.SupplierID)}
+		}
+		declared[declaration.SupplierID] = true
+	}
+	for _, supplierID := range supplierIDs {
+		if !declared[supplierID] {
+			return &panelClearanceError{fmt.Sprintf("you must file a conflict-of-interest declaration for supplier %d before viewing bids", supplierID)}
+		}
+	}
+	return nil
+}
+
+// requirePanelClearance lets a user through to a tender's bids only if checkPanelClearance passes.
+// The one exemption is oversight: procurement officers who do not sit on the panel may see the
+// bids without declaring, and every such access is logged. Officers who do sit on the panel
+// must be cleared like any other member.
+// It writes the error response itself and returns false when access is denied.
+func requirePanelClearance(db *gorm.DB, w http.ResponseWriter, tenderID int64, user *models.User) bool {
+	err := checkPanelClearance(db, tenderID, user.ID)
+	if err == nil {
+		return true
+	}
+	if err == errNotPanelMember && hasRole(user, "procurement_officer") {
+		log.Printf("Oversight: procurement officer UserID %d accessed the bids of TenderID %d without sitting on its evaluation panel (no conflict-of-interest declaration)", user.ID, tenderID)
+		return true
+	}
+	var clearanceErr *panelClearanceError
+	if errors.As(err, &clearanceErr) {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: "+clearanceErr.Error()+".")
+		return false
+	}
+	RespondWithError(w, http.StatusInternalServerError, "Failed to check evaluation panel clearance: "+err.Error())
+	return false
+}
+
+// loadPanel fetches the panel from the {panelId} URL parameter.
+func (h *EvaluationHandler) loadPanel(w http.ResponseWriter, r *http.Request) (*models.EvaluationPanel, bool) {
+	panelID, ok := parseIDParam(w, r, "panelId")
+	if !ok {
+		return nil, false
+	}
+	var panel models.EvaluationPanel
+	if err := h.DB.First(&panel, panelID).Error; err != nil {
+		respondWithLookupError(w, err, "Evaluation panel")
+		return nil, false
+	}
+	return &panel, true
+}
+
+// CreatePanel sets up the evaluation panel of a tender.
+// POST /api/tenders/{id}/panels
+func (h *EvaluationHandler) CreatePanel(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can create evaluation panels.")
+		return
+	}
+
+	tenderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	var tender models.Tender
+	if err := h.DB.First(&tender, tenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return
+	}
+	if status := tenderStatusOf(&tender); status == "cancelled" || status == "awarded" {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Cannot create an evaluation panel for a tender that is %s.", status))
+		return
+	}
+
+	var panel models.EvaluationPanel
+	if err := json.NewDecoder(r.Body).Decode(&panel); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+
+	if _, err := activePanelForTender(h.DB, tender.ID); err == nil {
+		RespondWithError(w, http.StatusConflict, "This tender already has an active evaluation panel.")
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check existing panels: "+err.Error())
+		return
+	}
+
+	panel.ID = 0
+	panel.TenderID = tender.ID
+	panel.Status = "active"
+	panel.CreatedByID = &currentUser.ID
+	panel.Members = nil
+	panel.Declarations = nil
+	if err := h.DB.Create(&panel).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to create evaluation panel: "+err.Error())
+		return
+	}
+	log.Printf("CreatePanel: Created PanelID %d for TenderID %d", panel.ID, tender.ID)
+
+	RespondWithJSON(w, http.StatusCreated, panel)
+}
+
+// ListPanels returns the evaluation panels of a tender with their members.
+// GET /api/tenders/{id}/panels
+func (h *EvaluationHandler) ListPanels(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "evaluator") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and evaluators can view evaluation panels.")
+		return
+	}
+
+	tenderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var panels []models.EvaluationPanel
+	if err := h.DB.Preload("Members.User").Where("tender_id = ?", tenderID).Order("creation_date DESC").Find(&panels).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve evaluation panels: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, panels)
+}
+
+// GetPanel returns a panel with its members and declarations.
+// Procurement officers see every declaration; members see only their own.
+// GET /api/panels/{panelId}
+func (h *EvaluationHandler) GetPanel(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "evaluator") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and evaluators can view evaluation panels.")
+		return
+	}
+
+	panelID, ok := parseIDParam(w, r, "panelId")
+	if !ok {
+		return
+	}
+
+	declarationScope := func(db *gorm.DB) *gorm.DB { return db }
+	if !hasRole(currentUser, "procurement_officer") {
+		declarationScope = func(db *gorm.DB) *gorm.DB { return db.Where("user_id = ?", currentUser.ID) }
+	}
+	var panel models.EvaluationPanel
+	if err := h.DB.Preload("Members.User").Preload("Declarations", declarationScope).First(&panel, panelID).Error; err != nil {
+		respondWithLookupError(w, err, "Evaluation panel")
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, panel)
+}
+
+// AddPanelMember assigns an evaluator to a panel.
+// POST /api/panels/{panelId}/members
+func (h *EvaluationHandler) AddPanelMember(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can assign panel members.")
+		return
+	}
+
+	panel, ok := h.loadPanel(w, r)
+	if !ok {
+		return
+	}
+	if panel.Status != "active" {
+		RespondWithError(w, http.StatusConflict, "Members can only be assigned to an active panel.")
+		return
+	}
+
+	var input PanelMemberInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+
+	var member models.User
+	if err := h.DB.First(&member, input.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			RespondWithError(w, http.StatusBadRequest, "User to assign was not found.")
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve user: "+err.Error())
+		}
+		return
+	}
+	if !hasRole(&member, "evaluator", "procurement_officer") {
+		RespondWithError(w, http.StatusBadRequest, "Only evaluators and procurement officers can sit on an evaluation panel.")
+		return
+	}
+
+	membership := models.UserEvaluationPanelMembership{
+		UserID:            member.ID,
+		EvaluationPanelID: panel.ID,
+		RoleInPanel:       input.RoleInPanel,
+	}
+	if err := h.DB.Create(&membership).Error; err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "unique") {
+			RespondWithError(w, http.StatusConflict, "User is already a member of this panel.")
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to assign panel member: "+err.Error())
+		}
+		return
+	}
+	log.Printf("AddPanelMember: UserID %d assigned to PanelID %d", member.ID, panel.ID)
+
+	membership.User = &member
+	RespondWithJSON(w, http.StatusCreated, membership)
+}
+
+// RemovePanelMember removes a member from a panel.
+// DELETE /api/panels/{panelId}/members/{userId}
+func (h *EvaluationHandler) RemovePanelMember(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can remove panel members.")
+		return
+	}
+
+	panel, ok := h.loadPanel(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(w, r, "userId")
+	if !ok {
+		return
+	}
+
+	result := h.DB.Where("evaluation_panel_id = ? AND user_id = ?", panel.ID, userID).Delete(&models.UserEvaluationPanelMembership{})
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to remove panel member: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusNotFound, "Panel member not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FileDeclarations records the current member's conflict-of-interest declarations
+// against the suppliers that bid on the panel's tender. Declarations cannot be changed once filed.
+// POST /api/panels/{panelId}/declarations
+func (h *EvaluationHandler) FileDeclarations(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+
+	panel, ok := h.loadPanel(w, r)
+	if !ok {
+		return
+	}
+
+	isMember, err := isPanelMember(h.DB, panel.ID, currentUser.ID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check panel membership: "+err.Error())
+		return
+	}
+	if !isMember {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only panel members can file declarations.")
+		return
+	}
+
+	var inputs []DeclarationInput
+	if err := json.NewDecoder(r.Body).Decode(&inputs); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if len(inputs) == 0 {
+		RespondWithError(w, http.StatusBadRequest, "At least one declaration is required.")
+		return
+	}
+
+	supplierIDs, err := biddingSupplierIDs(h.DB, panel.TenderID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve bidding suppliers: "+err.Error())
+		return
+	}
+	bidding := make(map[int64]bool, len(supplierIDs))
+	for _, supplierID := range supplierIDs {
+		bidding[supplierID] = true
+	}
+
+	declarations := make([]models.ConflictOfInterestDeclaration, 0, len(inputs))
+	for i, input := range inputs {
+		if !bidding[input.SupplierID] {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Declaration %d references supplier %d, which has not bid on this tender.", i+1, input.SupplierID))
+			return
+		}
+		if input.HasConflict && (input.Details == nil || strings.TrimSpace(*input.Details) == "") {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Declaration %d must describe the conflict of interest.", i+1))
+			return
+		}
+		declarations = append(declarations, models.ConflictOfInterestDeclaration{
+			EvaluationPanelID: panel.ID,
+			UserID:            currentUser.ID,
+			SupplierID:        input.SupplierID,
+			HasConflict:       input.HasConflict,
+			Details:           input.Details,
+		})
+	}
+
+	if err := h.DB.Create(&declarations).Error; err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "unique") {
+			RespondWithError(w, http.StatusConflict, "A declaration for one of these suppliers has already been filed and cannot be changed.")
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to file declarations: "+err.Error())
+		}
+		return
+	}
+	log.Printf("FileDeclarations: UserID %d filed %d declarations on PanelID %d", currentUser.ID, len(declarations), panel.ID)
+
+	RespondWithJSON(w, http.StatusCreated, declarations)
+}
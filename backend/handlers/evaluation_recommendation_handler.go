@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// errAwardConflict is returned from the award transaction when the tender or recommendation changed underneath it.
+var errAwardConflict = errors.New("tender or recommendation was modified concurrently")
+
+// RecommendationInput is the request body for raising an award recommendation.
+type RecommendationInput struct {
+	RecommendedBidID int64   `json:"recommended_bid_id"`
+	Justification    string  `json:"justification"`
+	OverallSummary   *string `json:"overall_summary,omitempty"`
+}
+
+// SignatureInput is the request body for signing or dissenting from a recommendation.
+type SignatureInput struct {
+	Dissent  bool    `json:"dissent"`
+	Comments *string `json:"comments,omitempty"`
+}
+
+// signaturePayload is the canonical text a panel member's signature covers.
+// It binds the signer to the recommended bid and justification at the moment of signing.
+func signaturePayload(recommendation *models.EvaluationPanelRecommendation, signature *models.EvaluationPanelSignature) string {
+	var bidID int64
+	if recommendation.RecommendedBidID != nil {
+		bidID = *recommendation.RecommendedBidID
+	}
+	return fmt.Sprintf("recommendation:%d|panel:%d|bid:%d|justification:%s|user:%d|dissent:%t|signed_at:%d",
+		recommendation.ID, recommendation.EvaluationPanelID, bidID, recommendation.Justification,
+		signature.UserID, signature.Dissent, signature.SignedAt.Unix())
+}
+
+// loadRecommendation fetches the recommendation from the {recommendationId} URL parameter along with its panel.
+func (h *EvaluationHandler) loadRecommendation(w http.ResponseWriter, r *http.Request) (*models.EvaluationPanelRecommendation, *models.EvaluationPanel, bool) {
+	recommendationID, ok := parseIDParam(w, r, "recommendationId")
+	if !ok {
+		return nil, nil, false
+	}
+	var recommendation models.EvaluationPanelRecommendation
+	if err := h.DB.Preload("Signatures").First(&recommendation, recommendationID).Error; err != nil {
+		respondWithLookupError(w, err, "Recommendation")
+		return nil, nil, false
+	}
+	var panel models.EvaluationPanel
+	if err := h.DB.First(&panel, recommendation.EvaluationPanelID).Error; err != nil {
+		respondWithLookupError(w, err, "Evaluation panel")
+		return nil, nil, false
+	}
+	return &recommendation, &panel, true
+}
+
+// canViewPanelRecords reports whether a user may read a panel's recommendations:
+// procurement officers and the panel's own members.
+func canViewPanelRecords(db *gorm.DB, panelID int64, user *models.User) (bool, error) {
+	if hasRole(user, "procurement_officer") {
+		return true, nil
+	}
+	return isPanelMember(db, panelID, user.ID)
+}
+
+// CreateRecommendation raises the panel's award recommendation for a bid.
+// Only cleared panel members may recommend, and a panel has one open recommendation at a time.
+// POST /api/panels/{panelId}/recommendations
+func (h *EvaluationHandler) CreateRecommendation(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	panel, ok := h.loadPanel(w, r)
+	if !ok {
+		return
+	}
+	if panel.Status != "active" {
+		RespondWithError(w, http.StatusConflict, "Only an active panel can make a recommendation.")
+		return
+	}
+
+	isMember, err := isPanelMember(h.DB, panel.ID, currentUser.ID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check panel membership: "+err.Error())
+		return
+	}
+	if !isMember {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only panel members can make a recommendation.")
+		return
+	}
+	if !requirePanelClearance(h.DB, w, panel.TenderID, currentUser) {
+		return
+	}
+
+	var tender models.Tender
+	if err := h.DB.First(&tender, panel.TenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return
+	}
+	if status := tenderStatusOf(&tender); status != models.TenderStatusEvaluation {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("An award can only be recommended while the tender is in evaluation (current status: %s).", status))
+		return
+	}
+
+	var input RecommendationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(input.Justification) == "" {
+		RespondWithError(w, http.StatusBadRequest, "A justification is required.")
+		return
+	}
+
+	var bid models.Bid
+	if err := h.DB.Where("id = ? AND tender_id = ?", input.RecommendedBidID, tender.ID).First(&bid).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			RespondWithError(w, http.StatusBadRequest, "Recommended bid does not belong to this tender.")
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve bid: "+err.Error())
+		}
+		return
+	}
+	if !isActiveBid(&bid) || bid.Status == "rejected" {
+		RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("A %s bid cannot be recommended.", bid.Status))
+		return
+	}
+	if bid.Sealed {
+		RespondWithError(w, http.StatusConflict, "A bid cannot be recommended until it is opened.")
+		return
+	}
+
+	var openCount int64
+	if err := h.DB.Model(&models.EvaluationPanelRecommendation{}).
+		Where("evaluation_panel_id = ? AND status IN ?", panel.ID, []string{models.RecommendationStatusPendingApproval, models.RecommendationStatusSigned}).
+		Count(&openCount).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check existing recommendations: "+err.Error())
+		return
+	}
+	if openCount > 0 {
+		RespondWithError(w, http.StatusConflict, "This panel already has an open recommendation.")
+		return
+	}
+
+	recommendation := models.EvaluationPanelRecommendation{
+		EvaluationPanelID: panel.ID,
+		RecommendedBidID:  &bid.ID,
+		RecommendedByID:   currentUser.ID,
+		Justification:     input.Justification,
+		OverallSummary:    input.OverallSummary,
+		Status:            models.RecommendationStatusPendingApproval,
+	}
+	if err := h.DB.Create(&recommendation).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to create recommendation: "+err.Error())
+		return
+	}
+	log.Printf("CreateRecommendation: RecommendationID %d for BidID %d raised on PanelID %d by UserID %d", recommendation.ID, bid.ID, panel.ID, currentUser.ID)
+
+	RespondWithJSON(w, http.StatusCreated, recommendation)
+}
+
+// ListRecommendations returns a panel's recommendations with their signatures.
+// GET /api/panels/{panelId}/recommendations
+func (h *EvaluationHandler) ListRecommendations(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	panel, ok := h.loadPanel(w, r)
+	if !ok {
+		return
+	}
+	allowed, err := canViewPanelRecords(h.DB, panel.ID, currentUser)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check panel membership: "+err.Error())
+		return
+	}
+	if !allowed {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only panel members and procurement officers can view recommendations.")
+		return
+	}
+
+	var recommendations []models.EvaluationPanelRecommendation
+	if err := h.DB.Preload("Signatures").Where("evaluation_panel_id = ?", panel.ID).Order("recommendation_date DESC").Find(&recommendations).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve recommendations: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, recommendations)
+}
+
+// GetRecommendation returns a recommendation with its signatures and recommended bid.
+// GET /api/recommendations/{recommendationId}
+func (h *EvaluationHandler) GetRecommendation(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	recommendation, panel, ok := h.loadRecommendation(w, r)
+	if !ok {
+		return
+	}
+	allowed, err := canViewPanelRecords(h.DB, panel.ID, currentUser)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check panel membership: "+err.Error())
+		return
+	}
+	if !allowed {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only panel members and procurement officers can view recommendations.")
+		return
+	}
+
+	if recommendation.RecommendedBidID != nil {
+		var bid models.Bid
+		if err := h.DB.First(&bid, *recommendation.RecommendedBidID).Error; err == nil {
+			recommendation.RecommendedBid = &bid
+		}
+	}
+
+	RespondWithJSON(w, http.StatusOK, recommendation)
+}
+
+// SignRecommendation records the current member's signature on, or dissent from, a recommendation.
+// The recommendation becomes 'signed' once every member has signed, or 'dissented' as soon as one dissents.
+// POST /api/recommendations/{recommendationId}/signatures
+func (h *EvaluationHandler) SignRecommendation(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	recommendation, panel, ok := h.loadRecommendation(w, r)
+	if !ok {
+		return
+	}
+
+	isMember, err := isPanelMember(h.DB, panel.ID, currentUser.ID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check panel membership: "+err.Error())
+		return
+	}
+	if !isMember {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only panel members can sign a recommendation.")
+		return
+	}
+	if !requirePanelClearance(h.DB, w, panel.TenderID, currentUser) {
+		return
+	}
+	if recommendation.Status != models.RecommendationStatusPendingApproval {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Recommendation is %s and can no longer be signed.", recommendation.Status))
+		return
+	}
+
+	var input SignatureInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if input.Dissent && (input.Comments == nil || strings.TrimSpace(*input.Comments) == "") {
+		RespondWithError(w, http.StatusBadRequest, "Comments are required when dissenting.")
+		return
+	}
+
+	signature := models.EvaluationPanelSignature{
+		RecommendationID: recommendation.ID,
+		UserID:           currentUser.ID,
+		Dissent:          input.Dissent,
+		SignedAt:         time.Now().UTC().Truncate(time.Second),
+		Comments:         input.Comments,
+	}
+	signature.DigitalSignature = h.Signer.Sign(signaturePayload(recommendation, &signature))
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&signature).Error; err != nil {
+			return err
+		}
+
+		newStatus := ""
+		if signature.Dissent {
+			newStatus = models.RecommendationStatusDissented
+		} else {
+			var memberCount, signedCount int64
+			if err := tx.Model(&models.UserEvaluationPanelMembership{}).Where("evaluation_panel_id = ?", panel.ID).Count(&memberCount).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.EvaluationPanelSignature{}).
+				Where("recommendation_id = ? AND dissent = ? AND user_id IN (?)", recommendation.ID, false,
+					tx.Model(&models.UserEvaluationPanelMembership{}).Select("user_id").Where("evaluation_panel_id = ?", panel.ID)).
+				Count(&signedCount).Error; err != nil {
+				return err
+			}
+			if memberCount > 0 && signedCount >= memberCount {
+				newStatus = models.RecommendationStatusSigned
+			}
+		}
+		if newStatus != "" {
+			recommendation.Status = newStatus
+			return tx.Model(recommendation).Update("status", newStatus).Error
+		}
+		return nil
+	})
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "unique") {
+			RespondWithError(w, http.StatusConflict, "You have already signed this recommendation.")
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to record signature: "+err.Error())
+		}
+		return
+	}
+	log.Printf("SignRecommendation: UserID %d signed RecommendationID %d (dissent: %t, status: %s)", currentUser.ID, recommendation.ID, signature.Dissent, recommendation.Status)
+
+	recommendation.Signatures = append(recommendation.Signatures, signature)
+	RespondWithJSON(w, http.StatusOK, recommendation)
+}
+
+// AwardRecommendation awards the tender to the recommended bid. The recommendation must be
+// signed by every current panel member with valid signatures. The tender becomes 'awarded', the
+// winning bid 'awarded' and every other active bid 'rejected', all in one transaction.
+// POST /api/recommendations/{recommendationId}/award
+func (h *EvaluationHandler) AwardRecommendation(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can award a tender.")
+		return
+	}
+	recommendation, panel, ok := h.loadRecommendation(w, r)
+	if !ok {
+		return
+	}
+	if recommendation.Status != models.RecommendationStatusSigned || recommendation.RecommendedBidID == nil {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Only a fully signed recommendation can be awarded (current status: %s).", recommendation.Status))
+		return
+	}
+
+	// Re-check the signatures against the current membership so a member added after signing,
+	// or a tampered record, blocks the award.
+	var memberIDs []int64
+	if err := h.DB.Model(&models.UserEvaluationPanelMembership{}).Where("evaluation_panel_id = ?", panel.ID).Pluck("user_id", &memberIDs).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve panel members: "+err.Error())
+		return
+	}
+	if len(memberIDs) == 0 {
+		RespondWithError(w, http.StatusConflict, "The evaluation panel has no members, so the recommendation cannot be awarded.")
+		return
+	}
+	signatures := make(map[int64]*models.EvaluationPanelSignature, len(recommendation.Signatures))
+	for i := range recommendation.Signatures {
+		signatures[recommendation.Signatures[i].UserID] = &recommendation.Signatures[i]
+	}
+	for _, memberID := range memberIDs {
+		signature, ok := signatures[memberID]
+		if !ok || signature.Dissent {
+			RespondWithError(w, http.StatusConflict, fmt.Sprintf("Panel member %d has not signed this recommendation.", memberID))
+			return
+		}
+		if !h.Signer.Verify(signaturePayload(recommendation, signature), signature.DigitalSignature) {
+			RespondWithError(w, http.StatusConflict, fmt.Sprintf("The signature of panel member %d does not match the recommendation.", memberID))
+			return
+		}
+	}
+
+	winningBidID := *recommendation.RecommendedBidID
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		moved, err := advanceTenderStatus(tx, panel.TenderID, models.TenderStatusEvaluation, models.TenderStatusAwarded, nil)
+		if err != nil {
+			return err
+		}
+		if !moved {
+			return errAwardConflict
+		}
+
+		result := tx.Model(&models.Bid{}).Where("id = ? AND tender_id = ? AND status NOT IN ?", winningBidID, panel.TenderID, inactiveBidStatuses).Update("status", "awarded")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAwardConflict
+		}
+		if err := tx.Model(&models.Bid{}).
+			Where("tender_id = ? AND id <> ? AND status NOT IN ?", panel.TenderID, winningBidID, inactiveBidStatuses).
+			Update("status", "rejected").Error; err != nil {
+			return err
+		}
+
+		result = tx.Model(&models.EvaluationPanelRecommendation{}).
+			Where("id = ? AND status = ?", recommendation.ID, models.RecommendationStatusSigned).
+			Update("status", models.RecommendationStatusAwarded)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAwardConflict
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errAwardConflict) {
+			RespondWithError(w, http.StatusConflict, "Tender could not be awarded: it is no longer in evaluation, or the bid was withdrawn.")
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to award tender: "+err.Error())
+		}
+		return
+	}
+	log.Printf("AwardRecommendation: TenderID %d awarded to BidID %d on RecommendationID %d by UserID %d", panel.TenderID, winningBidID, recommendation.ID, currentUser.ID)
+
+	var tender models.Tender
+	if err := h.DB.Preload("Bids").First(&tender, panel.TenderID).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Tender awarded but could not be reloaded: "+err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, tender)
+}
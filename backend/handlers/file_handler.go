@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"procurement/models"
+)
+
+// fileLinkTTL is how long a signed file link stays valid.
+const fileLinkTTL = 15 * time.Minute
+
+// FileLink is a signed, expiring URL that downloads a file without an Authorization header, for
+// use in links and image tags.
+type FileLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// fileLinkPayload is the value signed for a link to an attachment that expires at the given Unix time.
+func fileLinkPayload(attachmentID, expires int64) string {
+	return fmt.Sprintf("file:%d:%d", attachmentID, expires)
+}
+
+// attachmentDisposition shows PDFs and images in the browser and downloads everything else. Both
+// are safe to show inline because attachment types are sniffed from the content and served with nosniff.
+func attachmentDisposition(contentType string) string {
+	if contentType == "application/pdf" || strings.HasPrefix(contentType, "image/") {
+		return "inline"
+	}
+	return "attachment"
+}
+
+// serveAttachment streams an attachment with its sniffed content type. Attachments never change
+// once stored, so the ID and size make a strong ETag for resuming range requests.
+func (h *AttachmentHandler) serveAttachment(w http.ResponseWriter, r *http.Request, attachment *models.Attachment) {
+	if attachment.Sealed {
+		RespondWithError(w, http.StatusForbidden, "This document is sealed until the tender's bids are opened.")
+		return
+	}
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Header().Set("ETag", fmt.Sprintf(`"attachment-%d-%d"`, attachment.ID, attachment.Size))
+	serveBlob(w, r, h.Blobs, attachment.StorageKey, attachment.FileName, attachmentDisposition(attachment.ContentType), attachment.UploadDate, "Attachment")
+}
+
+// CreateFileLink returns a signed link to a file the user may read, valid for 15 minutes.
+// POST /api/files/{attachmentId}/link
+func (h *AttachmentHandler) CreateFileLink(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	attachment, ok := h.loadAttachment(w, r, currentUser, false)
+	if !ok {
+		return
+	}
+
+	expiresAt := time.Now().Add(fileLinkTTL).Truncate(time.Second)
+	expires := expiresAt.Unix()
+	signature := h.SignatureService.Sign(fileLinkPayload(attachment.ID, expires))
+	log.Printf("CreateFileLink: link to AttachmentID %d issued to UserID %d until %s", attachment.ID, currentUser.ID, expiresAt.UTC().Format(time.RFC3339))
+
+	RespondWithJSON(w, http.StatusCreated, FileLink{
+		URL:       fmt.Sprintf("/files/%d/signed?expires=%d&signature=%s", attachment.ID, expires, signature),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// DownloadSignedFile streams a file to anyone holding an unexpired link from CreateFileLink. The
+// route sits outside the authenticated group; the signature is the authorisation.
+// GET /api/files/{attachmentId}/signed?expires=&signature=
+func (h *AttachmentHandler) DownloadSignedFile(w http.ResponseWriter, r *http.Request) {
+	attachmentID, ok := parseIDParam(w, r, "attachmentId")
+	if !ok {
+		return
+	}
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil || !h.SignatureService.Verify(fileLinkPayload(attachmentID, expires), r.URL.Query().Get("signature")) {
+		RespondWithError(w, http.StatusForbidden, "Invalid file link.")
+		return
+	}
+	if time.Now().Unix() > expires {
+		RespondWithError(w, http.StatusForbidden, "This file link has expired.")
+		return
+	}
+
+	var attachment models.Attachment
+	if err := h.DB.First(&attachment, attachmentID).Error; err != nil {
+		respondWithLookupError(w, err, "Attachment")
+		return
+	}
+	h.serveAttachment(w, r, &attachment)
+}
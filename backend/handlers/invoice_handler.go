@@ -0,0 +1,476 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// errInvoiceStatusChanged is returned from an invoice transaction when the invoice moved on underneath it.
+var errInvoiceStatusChanged = errors.New("invoice status was changed concurrently")
+
+// InvoiceHandler holds dependencies for invoice handlers.
+type InvoiceHandler struct {
+	DB *gorm.DB
+	// MatchTolerance is the relative variance (0.02 = 2%) allowed between an invoice line and
+	// the ordered price or received quantity before the line is flagged.
+	MatchTolerance float64
+}
+
+// NewInvoiceHandler creates a new InvoiceHandler with the given DB connection and match tolerance.
+func NewInvoiceHandler(db *gorm.DB, matchTolerance float64) *InvoiceHandler {
+	return &InvoiceHandler{DB: db, MatchTolerance: matchTolerance}
+}
+
+// InvoiceItemInput is one billed line of an InvoiceInput.
+type InvoiceItemInput struct {
+	PurchaseOrderItemID int64   `json:"purchase_order_item_id"`
+	Quantity            float64 `json:"quantity"`
+	UnitPrice           float64 `json:"unit_price"`
+}
+
+// InvoiceInput is the request body for submitting an invoice against a purchase order.
+type InvoiceInput struct {
+	InvoiceNumber string             `json:"invoice_number"`
+	InvoiceDate   *time.Time         `json:"invoice_date,omitempty"`
+	DueDate       *time.Time         `json:"due_date,omitempty"`
+	TaxAmount     float64            `json:"tax_amount"`
+	Items         []InvoiceItemInput `json:"items"`
+}
+
+// PaymentInput is the request body for recording an invoice payment.
+type PaymentInput struct {
+	PaymentReference string     `json:"payment_reference"`
+	PaymentDate      *time.Time `json:"payment_date,omitempty"`
+}
+
+// canViewInvoice reports whether a user may read an invoice. Procurement, finance approvers and
+// admins see every invoice; a supplier sees its own.
+func canViewInvoice(user *models.User, invoice *models.Invoice) bool {
+	if hasRole(user, "procurement_officer", "approver", "admin") {
+		return true
+	}
+	return actsForSupplier(user, invoice.SupplierID)
+}
+
+// billedQuantity returns the quantity of a purchase order item billed on invoices submitted before the given one.
+func billedQuantity(db *gorm.DB, orderItemID, beforeInvoiceID int64) (float64, error) {
+	var total float64
+	err := db.Model(&models.InvoiceItem{}).
+		Where("purchase_order_item_id = ? AND invoice_id < ?", orderItemID, beforeInvoiceID).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&total).Error
+	return total, err
+}
+
+// matchInvoice three-way matches every line of the invoice against the purchase order price and
+// the quantity accepted on GRNs that earlier invoices have not already billed; goods rejected as
+// non-compliant are never payable. Lines outside the
+// tolerance are flagged; the invoice becomes 'matched' only when every line is within it.
+// It must run inside a transaction and saves the lines and invoice itself.
+func matchInvoice(tx *gorm.DB, invoice *models.Invoice, orderItems []models.PurchaseOrderItem, tolerance float64) error {
+	byID := make(map[int64]*models.PurchaseOrderItem, len(orderItems))
+	for i := range orderItems {
+		byID[orderItems[i].ID] = &orderItems[i]
+	}
+
+	allWithin := true
+	for i := range invoice.Items {
+		line := &invoice.Items[i]
+		orderItem, ok := byID[line.PurchaseOrderItemID]
+		if !ok {
+			return fmt.Errorf("invoice line %d references an item that is not on the purchase order", i+1)
+		}
+		accepted, err := acceptedQuantity(tx, orderItem.ID)
+		if err != nil {
+			return err
+		}
+		billed, err := billedQuantity(tx, orderItem.ID, invoice.ID)
+		if err != nil {
+			return err
+		}
+
+		line.OrderedUnitPrice = orderItem.UnitPrice
+		line.ReceivedQuantity = math.Max(accepted-billed, 0)
+		line.QuantityVariance = line.Quantity - line.ReceivedQuantity
+		line.PriceVariance = roundMoney(line.UnitPrice - orderItem.UnitPrice)
+
+		// Billing for less than was received is a normal partial invoice; only over-billing is a variance.
+		var notes []string
+		if line.QuantityVariance > line.ReceivedQuantity*tolerance+1e-9 {
+			notes = append(notes, fmt.Sprintf("billed quantity %g exceeds the %g accepted and not yet invoiced", line.Quantity, line.ReceivedQuantity))
+		}
+		if math.Abs(line.PriceVariance) > orderItem.UnitPrice*tolerance+0.005 {
+			notes = append(notes, fmt.Sprintf("unit price %g differs from the ordered %g", line.UnitPrice, orderItem.UnitPrice))
+		}
+		line.WithinTolerance = len(notes) == 0
+		line.VarianceNote = nil
+		if !line.WithinTolerance {
+			allWithin = false
+			note := strings.Join(notes, "; ")
+			line.VarianceNote = &note
+		}
+		if err := tx.Save(line).Error; err != nil {
+			return err
+		}
+	}
+
+	invoice.HasVariance = !allWithin
+	invoice.Status = models.InvoiceStatusPending
+	invoice.MatchedAt = nil
+	if allWithin {
+		now := time.Now()
+		invoice.Status = models.InvoiceStatusMatched
+		invoice.MatchedAt = &now
+	}
+	result := tx.Model(&models.Invoice{}).
+		Where("id = ? AND status IN ?", invoice.ID, []string{models.InvoiceStatusPending, models.InvoiceStatusMatched}).
+		Updates(map[string]interface{}{"status": invoice.Status, "has_variance": invoice.HasVariance, "matched_at": invoice.MatchedAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errInvoiceStatusChanged
+	}
+	return nil
+}
+
+// loadInvoice fetches the invoice from the {invoiceId} URL parameter with its lines and checks the
+// user may see it. It writes the error response itself on failure.
+func (h *InvoiceHandler) loadInvoice(w http.ResponseWriter, r *http.Request, user *models.User) (*models.Invoice, bool) {
+	invoiceID, ok := parseIDParam(w, r, "invoiceId")
+	if !ok {
+		return nil, false
+	}
+	var invoice models.Invoice
+	if err := h.DB.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).First(&invoice, invoiceID).Error; err != nil {
+		respondWithLookupError(w, err, "Invoice")
+		return nil, false
+	}
+	if !canViewInvoice(user, &invoice) {
+		RespondWithError(w, http.StatusNotFound, "Invoice not found")
+		return nil, false
+	}
+	return &invoice, true
+}
+
+// CreateInvoice records a supplier invoice against a purchase order and immediately runs the
+// three-way match. The supplier of the order, or a procurement officer on its behalf, may submit it.
+// POST /api/purchase-orders/{id}/invoices
+func (h *InvoiceHandler) CreateInvoice(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "supplier", "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only suppliers and procurement officers can submit invoices.")
+		return
+	}
+	order, ok := loadPurchaseOrder(h.DB, w, r, currentUser)
+	if !ok {
+		return
+	}
+	if order.Status == models.PurchaseOrderStatusDraft {
+		RespondWithError(w, http.StatusConflict, "Cannot invoice a purchase order that has not been issued.")
+		return
+	}
+
+	var input InvoiceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	input.InvoiceNumber = strings.TrimSpace(input.InvoiceNumber)
+	if input.InvoiceNumber == "" {
+		RespondWithError(w, http.StatusBadRequest, "invoice_number is required.")
+		return
+	}
+	if len(input.Items) == 0 {
+		RespondWithError(w, http.StatusBadRequest, "An invoice needs at least one line.")
+		return
+	}
+	if input.TaxAmount < 0 {
+		RespondWithError(w, http.StatusBadRequest, "tax_amount cannot be negative.")
+		return
+	}
+
+	orderItemIDs := make(map[int64]bool, len(order.Items))
+	for _, item := range order.Items {
+		orderItemIDs[item.ID] = true
+	}
+	invoice := models.Invoice{
+		PurchaseOrderID: order.ID,
+		SupplierID:      order.SupplierID,
+		InvoiceNumber:   input.InvoiceNumber,
+		InvoiceDate:     time.Now(),
+		DueDate:         input.DueDate,
+		TaxAmount:       roundMoney(input.TaxAmount),
+		Status:          models.InvoiceStatusPending,
+		SubmittedByID:   &currentUser.ID,
+	}
+	if input.InvoiceDate != nil {
+		invoice.InvoiceDate = *input.InvoiceDate
+	}
+	seen := make(map[int64]bool, len(input.Items))
+	for i, line := range input.Items {
+		if !orderItemIDs[line.PurchaseOrderItemID] {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invoice line %d references an item that is not on this purchase order.", i+1))
+			return
+		}
+		if seen[line.PurchaseOrderItemID] {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invoice line %d bills purchase order item %d twice.", i+1, line.PurchaseOrderItemID))
+			return
+		}
+		seen[line.PurchaseOrderItemID] = true
+		if line.Quantity <= 0 || line.UnitPrice < 0 {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invoice line %d needs a positive quantity and a non-negative unit price.", i+1))
+			return
+		}
+		lineTotal := roundMoney(line.Quantity * line.UnitPrice)
+		invoice.Subtotal += lineTotal
+		invoice.Items = append(invoice.Items, models.InvoiceItem{
+			PurchaseOrderItemID: line.PurchaseOrderItemID,
+			Quantity:            line.Quantity,
+			UnitPrice:           line.UnitPrice,
+			LineTotal:           lineTotal,
+		})
+	}
+	invoice.Subtotal = roundMoney(invoice.Subtotal)
+	invoice.TotalAmount = roundMoney(invoice.Subtotal + invoice.TaxAmount)
+
+	var duplicates int64
+	if err := h.DB.Model(&models.Invoice{}).Where("supplier_id = ? AND invoice_number = ?", invoice.SupplierID, invoice.InvoiceNumber).Count(&duplicates).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check existing invoices: "+err.Error())
+		return
+	}
+	if duplicates > 0 {
+		RespondWithError(w, http.StatusConflict, "This supplier has already submitted an invoice with that number.")
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&invoice).Error; err != nil {
+			return err
+		}
+		return matchInvoice(tx, &invoice, order.Items, h.MatchTolerance)
+	})
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to create invoice: "+err.Error())
+		return
+	}
+	log.Printf("CreateInvoice: InvoiceID %d (%s) for PurchaseOrderID %d submitted by UserID %d, status %s", invoice.ID, invoice.InvoiceNumber, order.ID, currentUser.ID, invoice.Status)
+
+	RespondWithJSON(w, http.StatusCreated, invoice)
+}
+
+// ListPurchaseOrderInvoices returns the invoices submitted against a purchase order.
+// GET /api/purchase-orders/{id}/invoices
+func (h *InvoiceHandler) ListPurchaseOrderInvoices(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	order, ok := loadPurchaseOrder(h.DB, w, r, currentUser)
+	if !ok {
+		return
+	}
+
+	var invoices []models.Invoice
+	if err := h.DB.Preload("Items").Where("purchase_order_id = ?", order.ID).Order("id ASC").Find(&invoices).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve invoices: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, invoices)
+}
+
+// ListInvoices returns invoices, newest first. Suppliers only see their own.
+// An optional ?status= query parameter filters by status.
+// GET /api/invoices
+func (h *InvoiceHandler) ListInvoices(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+
+	query := h.DB.Model(&models.Invoice{})
+	switch {
+	case hasRole(currentUser, "procurement_officer", "approver", "admin"):
+	case hasRole(currentUser, "supplier"):
+		supplierID, ok := requireSupplierOrganisation(w, currentUser)
+		if !ok {
+			return
+		}
+		query = query.Where("supplier_id = ?", supplierID)
+	default:
+		RespondWithError(w, http.StatusForbidden, "Forbidden: You do not have access to invoices.")
+		return
+	}
+	if status := strings.TrimSpace(r.URL.Query().Get("status")); status != "" {
+		query = query.Where("status = ?", strings.ToLower(status))
+	}
+
+	var invoices []models.Invoice
+	if err := query.Order("invoice_date DESC, id DESC").Find(&invoices).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve invoices: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, invoices)
+}
+
+// GetInvoice returns an invoice with its matched lines.
+// GET /api/invoices/{invoiceId}
+func (h *InvoiceHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	invoice, ok := h.loadInvoice(w, r, currentUser)
+	if !ok {
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, invoice)
+}
+
+// MatchInvoice re-runs the three-way match, e.g. after further goods have been received.
+// POST /api/invoices/{invoiceId}/match
+func (h *InvoiceHandler) MatchInvoice(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "approver") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and approvers can match invoices.")
+		return
+	}
+	invoice, ok := h.loadInvoice(w, r, currentUser)
+	if !ok {
+		return
+	}
+	if invoice.Status != models.InvoiceStatusPending && invoice.Status != models.InvoiceStatusMatched {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("An invoice that is %s can no longer be re-matched.", invoice.Status))
+		return
+	}
+
+	var orderItems []models.PurchaseOrderItem
+	if err := h.DB.Where("purchase_order_id = ?", invoice.PurchaseOrderID).Find(&orderItems).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve purchase order items: "+err.Error())
+		return
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		return matchInvoice(tx, invoice, orderItems, h.MatchTolerance)
+	})
+	if err != nil {
+		if errors.Is(err, errInvoiceStatusChanged) {
+			RespondWithError(w, http.StatusConflict, "Invoice was approved while it was being matched.")
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to match invoice: "+err.Error())
+		}
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, invoice)
+}
+
+// ApproveInvoice approves a matched invoice for payment.
+// POST /api/invoices/{invoiceId}/approve
+func (h *InvoiceHandler) ApproveInvoice(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "approver", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only approvers can approve invoices for payment.")
+		return
+	}
+	invoice, ok := h.loadInvoice(w, r, currentUser)
+	if !ok {
+		return
+	}
+	if invoice.Status != models.InvoiceStatusMatched {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Only a matched invoice can be approved for payment (current status: %s).", invoice.Status))
+		return
+	}
+
+	now := time.Now()
+	result := h.DB.Model(&models.Invoice{}).
+		Where("id = ? AND status = ?", invoice.ID, models.InvoiceStatusMatched).
+		Updates(map[string]interface{}{"status": models.InvoiceStatusApprovedForPayment, "approved_by_id": currentUser.ID, "approved_at": now})
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to approve invoice: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusConflict, "Invoice status was changed by someone else, please reload.")
+		return
+	}
+	log.Printf("ApproveInvoice: InvoiceID %d approved for payment by UserID %d", invoice.ID, currentUser.ID)
+
+	invoice.Status = models.InvoiceStatusApprovedForPayment
+	invoice.ApprovedByID = &currentUser.ID
+	invoice.ApprovedAt = &now
+	RespondWithJSON(w, http.StatusOK, invoice)
+}
+
+// PayInvoice records the payment of an approved invoice.
+// POST /api/invoices/{invoiceId}/pay
+func (h *InvoiceHandler) PayInvoice(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "approver", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only approvers can record invoice payments.")
+		return
+	}
+	invoice, ok := h.loadInvoice(w, r, currentUser)
+	if !ok {
+		return
+	}
+	if invoice.Status != models.InvoiceStatusApprovedForPayment {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Only an invoice approved for payment can be paid (current status: %s).", invoice.Status))
+		return
+	}
+
+	var input PaymentInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	input.PaymentReference = strings.TrimSpace(input.PaymentReference)
+	if input.PaymentReference == "" {
+		RespondWithError(w, http.StatusBadRequest, "payment_reference is required.")
+		return
+	}
+	paymentDate := time.Now()
+	if input.PaymentDate != nil {
+		paymentDate = *input.PaymentDate
+	}
+
+	result := h.DB.Model(&models.Invoice{}).
+		Where("id = ? AND status = ?", invoice.ID, models.InvoiceStatusApprovedForPayment).
+		Updates(map[string]interface{}{"status": models.InvoiceStatusPaid, "payment_date": paymentDate, "payment_reference": input.PaymentReference})
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to record payment: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusConflict, "Invoice status was changed by someone else, please reload.")
+		return
+	}
+	log.Printf("PayInvoice: InvoiceID %d paid (ref %s) recorded by UserID %d", invoice.ID, input.PaymentReference, currentUser.ID)
+
+	invoice.Status = models.InvoiceStatusPaid
+	invoice.PaymentDate = &paymentDate
+	invoice.PaymentReference = &input.PaymentReference
+	RespondWithJSON(w, http.StatusOK, invoice)
+}
@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// PurchaseOrderHandler holds dependencies for purchase order handlers.
+type PurchaseOrderHandler struct {
+	DB *gorm.DB
+}
+
+// NewPurchaseOrderHandler creates a new PurchaseOrderHandler with the given DB connection.
+func NewPurchaseOrderHandler(db *gorm.DB) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{DB: db}
+}
+
+// PurchaseOrderInput is the request body for raising a purchase order from an awarded bid.
+type PurchaseOrderInput struct {
+	BidID                int64      `json:"bid_id"`
+	PaymentTerms         *string    `json:"payment_terms,omitempty"`
+	DeliveryAddress      *string    `json:"delivery_address,omitempty"`
+	ExpectedDeliveryDate *time.Time `json:"expected_delivery_date,omitempty"`
+	Currency             string     `json:"currency,omitempty"`
+}
+
+// PurchaseOrderUpdateInput is the request body for changing the terms of a draft purchase order.
+type PurchaseOrderUpdateInput struct {
+	PaymentTerms         *string    `json:"payment_terms,omitempty"`
+	DeliveryAddress      *string    `json:"delivery_address,omitempty"`
+	ExpectedDeliveryDate *time.Time `json:"expected_delivery_date,omitempty"`
+	Currency             string     `json:"currency,omitempty"`
+}
+
+// roundMoney rounds an amount to two decimal places.
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// isBlank reports whether an optional string is missing or only whitespace.
+func isBlank(value *string) bool {
+	return value == nil || strings.TrimSpace(*value) == ""
+}
+
+// purchaseOrderItemsFromBid copies the items of an awarded bid into purchase order items.
+func purchaseOrderItemsFromBid(bidItems []models.BidItem) []models.PurchaseOrderItem {
+	items := make([]models.PurchaseOrderItem, 0, len(bidItems))
+	for _, bidItem := range bidItems {
+		bidItemID := bidItem.ID
+		items = append(items, models.PurchaseOrderItem{
+			BidItemID:         &bidItemID,
+			RequisitionItemID: bidItem.RequisitionItemID,
+			Description:       bidItem.Description,
+			Quantity:          bidItem.Quantity,
+			Unit:              bidItem.Unit,
+			UnitPrice:         bidItem.OfferedUnitPrice,
+			TotalPrice:        roundMoney(bidItem.Quantity * bidItem.OfferedUnitPrice),
+			DeliveryStatus:    models.DeliveryStatusPending,
+		})
+	}
+	return items
+}
+
+// canViewPurchaseOrder reports whether a user may read a purchase order. Procurement officers and
+// admins see every order; a supplier sees its own orders once they have been issued.
+func canViewPurchaseOrder(user *models.User, order *models.PurchaseOrder) bool {
+	if hasRole(user, "procurement_officer", "admin") {
+		return true
+	}
+	return actsForSupplier(user, order.SupplierID) && order.Status != models.PurchaseOrderStatusDraft
+}
+
+// loadPurchaseOrder fetches the purchase order from the {id} URL parameter with its items and
+// checks the user may see it. It writes the error response itself on failure.
+func loadPurchaseOrder(db *gorm.DB, w http.ResponseWriter, r *http.Request, user *models.User) (*models.PurchaseOrder, bool) {
+	orderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+	var order models.PurchaseOrder
+	if err := db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).First(&order, orderID).Error; err != nil {
+		respondWithLookupError(w, err, "Purchase order")
+		return nil, false
+	}
+	if !canViewPurchaseOrder(user, &order) {
+		// Hide orders the user has no business with rather than confirming they exist.
+		RespondWithError(w, http.StatusNotFound, "Purchase order not found")
+		return nil, false
+	}
+	return &order, true
+}
+
+// CreatePurchaseOrder raises a draft purchase order from an awarded bid, copying its items.
+// POST /api/purchase-orders
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can create purchase orders.")
+		return
+	}
+
+	var input PurchaseOrderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if input.BidID <= 0 {
+		RespondWithError(w, http.StatusBadRequest, "bid_id is required.")
+		return
+	}
+
+	var bid models.Bid
+	if err := h.DB.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).First(&bid, input.BidID).Error; err != nil {
+		respondWithLookupError(w, err, "Bid")
+		return
+	}
+	if bid.Status != "awarded" {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("A purchase order can only be raised for an awarded bid (current status: %s).", bid.Status))
+		return
+	}
+	if len(bid.Items) == 0 {
+		RespondWithError(w, http.StatusConflict, "The awarded bid has no items to order.")
+		return
+	}
+
+	debarment, err := activeDebarment(h.DB, bid.SupplierID, time.Now())
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check supplier debarment: "+err.Error())
+		return
+	}
+	if debarment != nil {
+		RespondWithError(w, http.StatusConflict, "Cannot raise a purchase order: the supplier is "+debarmentMessage(debarment)+".")
+		return
+	}
+
+	var existing int64
+	if err := h.DB.Model(&models.PurchaseOrder{}).Where("bid_id = ?", bid.ID).Count(&existing).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check existing purchase orders: "+err.Error())
+		return
+	}
+	if existing > 0 {
+		RespondWithError(w, http.StatusConflict, "A purchase order already exists for this bid.")
+		return
+	}
+
+	// Link the order to the recommendation the award was made on, when there is one.
+	var recommendationID *int64
+	var recommendation models.EvaluationPanelRecommendation
+	err = h.DB.Where("recommended_bid_id = ? AND status = ?", bid.ID, models.RecommendationStatusAwarded).First(&recommendation).Error
+	switch {
+	case err == nil:
+		recommendationID = &recommendation.ID
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve award recommendation: "+err.Error())
+		return
+	}
+
+	tenderID := bid.TenderID
+	order := models.PurchaseOrder{
+		RecommendationID:     recommendationID,
+		BidID:                &bid.ID,
+		TenderID:             &tenderID,
+		SupplierID:           bid.SupplierID,
+		Status:               models.PurchaseOrderStatusDraft,
+		Currency:             "TZS",
+		PaymentTerms:         input.PaymentTerms,
+		DeliveryAddress:      input.DeliveryAddress,
+		CreatedByID:          &currentUser.ID,
+		ExpectedDeliveryDate: input.ExpectedDeliveryDate,
+		Items:                purchaseOrderItemsFromBid(bid.Items),
+	}
+	if c := strings.TrimSpace(input.Currency); c != "" {
+		order.Currency = strings.ToUpper(c)
+	}
+	for _, item := range order.Items {
+		order.TotalAmount += item.TotalPrice
+	}
+	order.TotalAmount = roundMoney(order.TotalAmount)
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		number, err := nextDocumentNumber(tx, "PO", time.Now())
+		if err != nil {
+			return err
+		}
+		order.PONumber = number
+		return tx.Create(&order).Error
+	})
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to create purchase order: "+err.Error())
+		return
+	}
+	log.Printf("CreatePurchaseOrder: Created %s (PurchaseOrderID %d) from BidID %d by UserID %d", order.PONumber, order.ID, bid.ID, currentUser.ID)
+
+	RespondWithJSON(w, http.StatusCreated, order)
+}
+
+// ListPurchaseOrders returns purchase orders, newest first. Suppliers only see their own issued orders.
+// An optional ?status= query parameter filters by status.
+// GET /api/purchase-orders
+func (h *PurchaseOrderHandler) ListPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+
+	query := h.DB.Model(&models.PurchaseOrder{})
+	switch {
+	case hasRole(currentUser, "procurement_officer", "admin"):
+	case hasRole(currentUser, "supplier"):
+		supplierID, ok := requireSupplierOrganisation(w, currentUser)
+		if !ok {
+			return
+		}
+		query = query.Where("supplier_id = ? AND status <> ?", supplierID, models.PurchaseOrderStatusDraft)
+	default:
+		RespondWithError(w, http.StatusForbidden, "Forbidden: You do not have access to purchase orders.")
+		return
+	}
+	if status := strings.TrimSpace(r.URL.Query().Get("status")); status != "" {
+		query = query.Where("status = ?", strings.ToLower(status))
+	}
+
+	var orders []models.PurchaseOrder
+	if err := query.Order("order_date DESC, id DESC").Find(&orders).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve purchase orders: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, orders)
+}
+
+// GetPurchaseOrder returns a purchase order with its items.
+// GET /api/purchase-orders/{id}
+func (h *PurchaseOrderHandler) GetPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	order, ok := loadPurchaseOrder(h.DB, w, r, currentUser)
+	if !ok {
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, order)
+}
+
+// UpdatePurchaseOrder changes the payment terms, delivery address or currency of a draft purchase order.
+// PUT /api/purchase-orders/{id}
+func (h *PurchaseOrderHandler) UpdatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can change purchase orders.")
+		return
+	}
+	order, ok := loadPurchaseOrder(h.DB, w, r, currentUser)
+	if !ok {
+		return
+	}
+	if order.Status != models.PurchaseOrderStatusDraft {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Only a draft purchase order can be changed (current status: %s).", order.Status))
+		return
+	}
+
+	var input PurchaseOrderUpdateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if input.PaymentTerms != nil {
+		order.PaymentTerms = input.PaymentTerms
+	}
+	if input.DeliveryAddress != nil {
+		order.DeliveryAddress = input.DeliveryAddress
+	}
+	if input.ExpectedDeliveryDate != nil {
+		order.ExpectedDeliveryDate = input.ExpectedDeliveryDate
+	}
+	if c := strings.TrimSpace(input.Currency); c != "" {
+		order.Currency = strings.ToUpper(c)
+	}
+
+	result := h.DB.Model(&models.PurchaseOrder{}).
+		Where("id = ? AND status = ?", order.ID, models.PurchaseOrderStatusDraft).
+		Updates(map[string]interface{}{
+			"payment_terms":          order.PaymentTerms,
+			"delivery_address":       order.DeliveryAddress,
+			"expected_delivery_date": order.ExpectedDeliveryDate,
+			"currency":               order.Currency,
+		})
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update purchase order: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusConflict, "Purchase order was issued while it was being changed.")
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, order)
+}
+
+// transitionPurchaseOrder moves a purchase order from one status to the next, stamping the given
+// timestamp column. The update is conditional on the current status so concurrent transitions
+// cannot both succeed. It writes the response itself.
+func (h *PurchaseOrderHandler) transitionPurchaseOrder(w http.ResponseWriter, order *models.PurchaseOrder, from, to, stampColumn string, userID int64) {
+	if order.Status != from {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Cannot move a purchase order from '%s' to '%s'.", order.Status, to))
+		return
+	}
+
+	now := time.Now()
+	result := h.DB.Model(&models.PurchaseOrder{}).
+		Where("id = ? AND status = ?", order.ID, from).
+		Updates(map[string]interface{}{"status": to, stampColumn: now})
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update purchase order status: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusConflict, "Purchase order status was changed by someone else, please reload.")
+		return
+	}
+	log.Printf("PurchaseOrder: %s (PurchaseOrderID %d) moved from %s to %s by UserID %d", order.PONumber, order.ID, from, to, userID)
+
+	order.Status = to
+	switch stampColumn {
+	case "issued_at":
+		order.IssuedAt = &now
+	case "acknowledged_at":
+		order.AcknowledgedAt = &now
+	case "closed_at":
+		order.ClosedAt = &now
+	}
+	RespondWithJSON(w, http.StatusOK, order)
+}
+
+// IssuePurchaseOrder sends a draft purchase order to the supplier. Payment terms and a delivery
+// address must be set first.
+// POST /api/purchase-orders/{id}/issue
+func (h *PurchaseOrderHandler) IssuePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can issue purchase orders.")
+		return
+	}
+	order, ok := loadPurchaseOrder(h.DB, w, r, currentUser)
+	if !ok {
+		return
+	}
+	if order.Status == models.PurchaseOrderStatusDraft && (isBlank(order.PaymentTerms) || isBlank(order.DeliveryAddress)) {
+		RespondWithError(w, http.StatusBadRequest, "Payment terms and a delivery address are required before issuing a purchase order.")
+		return
+	}
+	h.transitionPurchaseOrder(w, order, models.PurchaseOrderStatusDraft, models.PurchaseOrderStatusIssued, "issued_at", currentUser.ID)
+}
+
+// AcknowledgePurchaseOrder records the supplier's acceptance of an issued purchase order.
+// POST /api/purchase-orders/{id}/acknowledge
+func (h *PurchaseOrderHandler) AcknowledgePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "supplier") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only the supplier can acknowledge a purchase order.")
+		return
+	}
+	order, ok := loadPurchaseOrder(h.DB, w, r, currentUser)
+	if !ok {
+		return
+	}
+	h.transitionPurchaseOrder(w, order, models.PurchaseOrderStatusIssued, models.PurchaseOrderStatusAcknowledged, "acknowledged_at", currentUser.ID)
+}
+
+// ClosePurchaseOrder closes an acknowledged purchase order.
+// POST /api/purchase-orders/{id}/close
+func (h *PurchaseOrderHandler) ClosePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can close purchase orders.")
+		return
+	}
+	order, ok := loadPurchaseOrder(h.DB, w, r, currentUser)
+	if !ok {
+		return
+	}
+	h.transitionPurchaseOrder(w, order, models.PurchaseOrderStatusAcknowledged, models.PurchaseOrderStatusClosed, "closed_at", currentUser.ID)
+}
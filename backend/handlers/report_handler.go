@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// ReportHandler holds dependencies for the reports recorded by the scheduler.
+type ReportHandler struct {
+	DB *gorm.DB
+}
+
+// NewReportHandler creates a new ReportHandler with the given DB connection.
+func NewReportHandler(db *gorm.DB) *ReportHandler {
+	return &ReportHandler{DB: db}
+}
+
+// requireReportAccess makes sure the current user may read reports. It writes the error
+// response itself.
+func (h *ReportHandler) requireReportAccess(w http.ResponseWriter, r *http.Request) bool {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return false
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and admins can view reports.")
+		return false
+	}
+	return true
+}
+
+// ListReports returns the recorded reports, newest period first, without their data. Filter
+// with ?kind=.
+// GET /api/reports
+func (h *ReportHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	if !h.requireReportAccess(w, r) {
+		return
+	}
+	query := h.DB.Model(&models.Report{}).Omit("data")
+	if kind := r.URL.Query().Get("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var reports []models.Report
+	if err := query.Order("period_start DESC, id DESC").Find(&reports).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve reports: "+err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, reports)
+}
+
+// GetReport returns a report with its data.
+// GET /api/reports/{id}
+func (h *ReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	if !h.requireReportAccess(w, r) {
+		return
+	}
+	reportID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	var report models.Report
+	if err := h.DB.First(&report, reportID).Error; err != nil {
+		respondWithLookupError(w, err, "Report")
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, report)
+}
@@ -30,15 +30,21 @@ func CreateRequisitionHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	// Get User ID from context
-	userID, ok := r.Context().Value("userID").(uint)
+	userID, ok := r.Context().Value("userID").(int64)
 	if !ok {
 		log.Println("ERROR: CreateRequisitionHandler: Could not retrieve userID from context or type assertion failed.")
 		RespondWithError(w, http.StatusInternalServerError, "Could not process request: user authentication issue.")
 		return
 	}
+	var requester models.User
+	if err := db.First(&requester, userID).Error; err != nil {
+		log.Printf("ERROR: CreateRequisitionHandler: Failed to query user %d: %v\n", userID, err)
+		RespondWithError(w, http.StatusUnauthorized, "Unauthorized: User not found.")
+		return
+	}
 
 	// Assign the authenticated user's ID to the requisition
-	reqPayload.UserID = int64(userID)
+	reqPayload.UserID = userID
 
 	// Basic Validation (example)
 	if reqPayload.Type == "" {
@@ -50,6 +56,38 @@ func CreateRequisitionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Approval is decided here, never by the client. Drafts wait unrouted; anything else goes to
+	// the first level of the chain matching its type, department and value. The department picks
+	// the chain, so it is the requester's own; only procurement staff may raise for another one.
+	department := trimmedOrNil(reqPayload.Department)
+	if department == nil || !hasRole(&requester, "admin", "procurement_officer") {
+		department = requester.Department
+	}
+	reqPayload.Department = department
+	reqPayload.TotalValue = requisitionTotalValue(reqPayload.Items)
+	reqPayload.ApprovalChainID = nil
+	reqPayload.ApprovalChain = nil
+	reqPayload.ApprovalSteps = nil
+	reqPayload.CurrentApprovalLevel = 0
+	reqPayload.RejectionReason = nil
+	if strings.EqualFold(string(reqPayload.Status), string(models.RequisitionStatusDraft)) {
+		reqPayload.Status = models.RequisitionStatusDraft
+	} else {
+		chain, err := routeRequisition(db, &reqPayload)
+		if err != nil {
+			log.Printf("ERROR: CreateRequisitionHandler: Failed to route requisition: %v\n", err)
+			RespondWithError(w, http.StatusInternalServerError, "Failed to find an approval chain: "+err.Error())
+			return
+		}
+		if chain == nil {
+			RespondWithError(w, http.StatusUnprocessableEntity, "No approval chain applies to this requisition. Ask an admin to configure one.")
+			return
+		}
+		reqPayload.Status = models.RequisitionStatusPendingApproval
+		reqPayload.ApprovalChainID = &chain.ID
+		reqPayload.CurrentApprovalLevel = chain.Levels[0].Level
+	}
+
 	tx := db.Begin()
 	if tx.Error != nil {
 		log.Printf("ERROR: CreateRequisitionHandler: Failed to begin transaction: %v\n", tx.Error)
@@ -115,7 +153,7 @@ func CreateRequisitionHandler(w http.ResponseWriter, r *http.Request) {
 
 	// --- End Transactional Operations ---
 
-	log.Printf("INFO: CreateRequisitionHandler: Successfully created requisition ID %d with %d items\n", reqPayload.ID, len(itemsToCreate))
+	log.Printf("INFO: CreateRequisitionHandler: Successfully created requisition ID %d with %d items, status %s\n", reqPayload.ID, len(itemsToCreate), reqPayload.Status)
 
 	// To send back the full requisition with its newly created items (and their DB-generated IDs):
 	// We need to reload the requisition with its items. The `reqPayload` has the main requisition details,
@@ -162,6 +200,15 @@ func ListRequisitionsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The requisitions the user may decide now are worked out once, both to include them in the
+	// list and to flag them.
+	awaiting, err := requisitionsAwaitingDecision(db, &user)
+	if err != nil {
+		log.Printf("ERROR: ListRequisitionsHandler: Failed to find requisitions awaiting user %d: %v\n", userID, err)
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve requisitions: "+err.Error())
+		return
+	}
+
 	var requisitions []models.Requisition
 	query := db.Preload("Items").Order("created_at desc")
 
@@ -172,9 +219,13 @@ func ListRequisitionsHandler(w http.ResponseWriter, r *http.Request) {
 		// Procurement officers see all requisitions
 		log.Printf("INFO: User %d (Role: %s) is a procurement officer or admin, fetching all requisitions.", userID, user.Role)
 	} else {
-		// Other users see only their own requisitions
-		log.Printf("INFO: User %d (Role: %s) is not a procurement officer or admin, fetching only their requisitions.", userID, user.Role)
-		query = query.Where("user_id = ?", userID)
+		// Other users see their own requisitions and those they approve
+		log.Printf("INFO: User %d (Role: %s) is not a procurement officer or admin, fetching their own and approvable requisitions.", userID, user.Role)
+		awaitingIDs := []int64{0}
+		for id := range awaiting {
+			awaitingIDs = append(awaitingIDs, id)
+		}
+		query = query.Where("user_id = ? OR id IN (?) OR id IN (SELECT requisition_id FROM approval_steps WHERE approver_id = ?)", userID, awaitingIDs, userID)
 	}
 
 	if err := query.Find(&requisitions).Error; err != nil {
@@ -186,6 +237,9 @@ func ListRequisitionsHandler(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve requisitions: "+err.Error())
 		return
 	}
+	for i := range requisitions {
+		requisitions[i].CanDecide = awaiting[requisitions[i].ID]
+	}
 
 	RespondWithJSON(w, http.StatusOK, requisitions)
 	log.Printf("INFO: Successfully retrieved %d requisitions for user ID: %d", len(requisitions), userID)
@@ -243,31 +297,16 @@ func GetRequisitionHandler(w http.ResponseWriter, r *http.Request) {
 	log.Printf("DIAGNOSTIC: GetRequisitionHandler: Fetched user for role check. UserID: %d, UserRole from DB: '%s'", user.ID, user.Role)
 
 	var requisition models.Requisition
-	query := db.Preload("Items")
-
-	// Role-based access control
-	// TODO: Make "procurement_officer" a constant or configurable value
-	if strings.EqualFold(user.Role, "procurement_officer") || strings.EqualFold(user.Role, "admin") {
-		// Procurement officers can view any requisition by ID
-		log.Printf("INFO: GetRequisitionHandler: User %d (Role: %s) is a procurement officer or admin. Accessing requisition ID %d.", userID, user.Role, requisitionID)
-		query = query.Where("id = ?", requisitionID)
-	} else {
-		// Other users can only view their own requisitions
-		log.Printf("INFO: GetRequisitionHandler: User %d (Role: %s) is not a procurement officer or admin. Accessing own requisition ID %d.", userID, user.Role, requisitionID)
-		query = query.Where("id = ? AND user_id = ?", requisitionID, userID)
-	}
+	query := db.Preload("Items").
+		Preload("ApprovalChain.Levels", levelsByNumber).
+		Preload("ApprovalSteps", func(db *gorm.DB) *gorm.DB { return db.Order("decided_at ASC, id ASC") }).
+		Preload("ApprovalSteps.Approver")
 
 	// Query for the specific requisition
-	if err := query.First(&requisition).Error; err != nil {
+	if err := query.First(&requisition, requisitionID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Log slightly different message based on role access
-			if strings.EqualFold(user.Role, "procurement_officer") {
-				log.Printf("WARN: GetRequisitionHandler: Requisition ID %d not found (Procurement Officer access).", requisitionID)
-				RespondWithError(w, http.StatusNotFound, "Requisition not found.")
-			} else {
-				log.Printf("WARN: GetRequisitionHandler: Requisition ID %d not found for user ID %d or user lacks permission.", requisitionID, userID)
-				RespondWithError(w, http.StatusNotFound, "Requisition not found or you do not have permission to view it.")
-			}
+			log.Printf("WARN: GetRequisitionHandler: Requisition ID %d not found.", requisitionID)
+			RespondWithError(w, http.StatusNotFound, "Requisition not found or you do not have permission to view it.")
 		} else {
 			log.Printf("ERROR: GetRequisitionHandler: Failed to query requisition ID %d (User ID %d, Role %s): %v\n", requisitionID, userID, user.Role, err)
 			RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve requisition: "+err.Error())
@@ -275,6 +314,27 @@ func GetRequisitionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if requisition.CanDecide, err = canDecideRequisition(db, &user, &requisition); err != nil {
+		log.Printf("ERROR: GetRequisitionHandler: Failed to check approver of requisition ID %d: %v\n", requisitionID, err)
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve requisition: "+err.Error())
+		return
+	}
+
+	// Role-based access control
+	// TODO: Make "procurement_officer" a constant or configurable value
+	// Procurement officers and admins can view any requisition; other users their own, those
+	// awaiting their decision and those they have decided.
+	allowed := strings.EqualFold(user.Role, "procurement_officer") || strings.EqualFold(user.Role, "admin") ||
+		requisition.UserID == userID || requisition.CanDecide
+	for _, step := range requisition.ApprovalSteps {
+		allowed = allowed || step.ApproverID == userID
+	}
+	if !allowed {
+		log.Printf("WARN: GetRequisitionHandler: User %d (Role: %s) lacks permission to view requisition ID %d.", userID, user.Role, requisitionID)
+		RespondWithError(w, http.StatusNotFound, "Requisition not found or you do not have permission to view it.")
+		return
+	}
+
 	RespondWithJSON(w, http.StatusOK, requisition)
 	log.Printf("INFO: Successfully retrieved requisition ID %d for user ID %d (Role: %s)", requisition.ID, userID, user.Role)
 }
@@ -285,7 +345,9 @@ type RequisitionActionPayload struct {
 	Reason string `json:"reason,omitempty"` // Required if action is "reject"
 }
 
-// HandleRequisitionAction handles POST requests to approve or reject a requisition
+// errRequisitionDecided stops a decision on a requisition that has moved past the level decided.
+var errRequisitionDecided = errors.New("requisition was decided concurrently")
+
 // MyRequisitionStats defines the statistics for a requester's personal dashboard.
 type MyRequisitionStats struct {
 	Pending  int64 `json:"pending"`
@@ -304,7 +366,7 @@ func GetMyRequisitionStatsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var stats MyRequisitionStats
-	db.Model(&models.Requisition{}).Where("user_id = ? AND status IN (?)", userID, []string{string(models.RequisitionStatusPendingApproval1), string(models.RequisitionStatusPendingApproval2)}).Count(&stats.Pending)
+	db.Model(&models.Requisition{}).Where("user_id = ? AND status = ?", userID, models.RequisitionStatusPendingApproval).Count(&stats.Pending)
 	db.Model(&models.Requisition{}).Where("user_id = ? AND status IN (?)", userID, []string{string(models.RequisitionStatusApproved), string(models.RequisitionStatusPendingTender), string(models.RequisitionStatusTendered)}).Count(&stats.Approved)
 	db.Model(&models.Requisition{}).Where("user_id = ? AND status = ?", userID, models.RequisitionStatusRejected).Count(&stats.Rejected)
 
@@ -329,8 +391,10 @@ func GetMyRecentRequisitionsHandler(w http.ResponseWriter, r *http.Request) {
 	RespondWithJSON(w, http.StatusOK, requisitions)
 }
 
+// HandleRequisitionAction handles POST requests to approve or reject a requisition at the current
+// level of its approval chain. Approving the last level approves the requisition; rejecting at
+// any level rejects it. Every decision is recorded as an approval step.
 func HandleRequisitionAction(w http.ResponseWriter, r *http.Request) {
-	log.Println("DEBUG: HandleRequisitionAction: Entered function.")
 	db := database.GetDB()
 	if db == nil {
 		log.Println("ERROR: HandleRequisitionAction: Database not initialized")
@@ -338,56 +402,15 @@ func HandleRequisitionAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get authenticated user ID from context
-	userIDFromCtx := r.Context().Value("userID")
-	if userIDFromCtx == nil {
-		log.Println("ERROR: HandleRequisitionAction: userID not found in context. Unauthorized.")
-		RespondWithError(w, http.StatusUnauthorized, "Unauthorized: User ID not found in request context.")
-		return
-	}
-
-	adminID, okUserID := userIDFromCtx.(int64)
-	if !okUserID || adminID == 0 {
-		log.Printf("ERROR: HandleRequisitionAction: Invalid userID type in context. userIDFromCtx: %v", userIDFromCtx)
-		RespondWithError(w, http.StatusForbidden, "Forbidden: Invalid user identifier.")
-		return
-	}
-
-	// Fetch the admin user from DB to get their role
-	var adminUser models.User
-	if err := db.First(&adminUser, adminID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Printf("ERROR: HandleRequisitionAction: Admin user with ID %d not found in DB.", adminID)
-			RespondWithError(w, http.StatusUnauthorized, "Unauthorized: Admin user not found.")
-		} else {
-			log.Printf("ERROR: HandleRequisitionAction: Error fetching admin user %d: %v", adminID, err)
-			RespondWithError(w, http.StatusInternalServerError, "Error verifying admin user.")
-		}
-		return
-	}
-	adminRole := adminUser.Role
-
-	// Check if the user is an admin
-	// TODO: Use a constant for "admin" role
-	if !strings.EqualFold(adminRole, "admin") {
-		log.Printf("WARN: HandleRequisitionAction: User %d (Role: %s) attempted to perform admin action.", adminID, adminRole)
-		RespondWithError(w, http.StatusForbidden, "Forbidden: This action requires admin privileges.")
+	approver, ok := getAuthenticatedUser(db, w, r)
+	if !ok {
 		return
 	}
 
-	// Get requisition ID from URL parameter
-	requisitionIDStr := chi.URLParam(r, "id")
-	if requisitionIDStr == "" {
-		RespondWithError(w, http.StatusBadRequest, "Requisition ID is required")
-		return
-	}
-	requisitionID, err := strconv.ParseInt(requisitionIDStr, 10, 64)
-	if err != nil {
-		log.Printf("ERROR: HandleRequisitionAction: Invalid Requisition ID format '%s': %v", requisitionIDStr, err)
-		RespondWithError(w, http.StatusBadRequest, "Invalid Requisition ID format")
+	requisitionID, ok := parseIDParam(w, r, "id")
+	if !ok {
 		return
 	}
-	log.Printf("DEBUG: HandleRequisitionAction: Requisition ID parsed: %d. Admin ID: %d. Admin Role: %s", requisitionID, adminID, adminRole)
 
 	// Decode the request body
 	var payload RequisitionActionPayload
@@ -397,7 +420,6 @@ func HandleRequisitionAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer r.Body.Close()
-	log.Printf("DEBUG: HandleRequisitionAction: Payload decoded successfully: Action='%s', Reason='%s'", payload.Action, payload.Reason)
 
 	// Validate action
 	payload.Action = strings.ToLower(payload.Action)
@@ -405,108 +427,170 @@ func HandleRequisitionAction(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusBadRequest, "Invalid action specified. Must be 'approve' or 'reject'.")
 		return
 	}
-
-	if payload.Action == "reject" && strings.TrimSpace(payload.Reason) == "" {
+	payload.Reason = strings.TrimSpace(payload.Reason)
+	if payload.Action == "reject" && payload.Reason == "" {
 		RespondWithError(w, http.StatusBadRequest, "Rejection reason is required when action is 'reject'.")
 		return
 	}
 
 	var requisition models.Requisition
-	tx := db.Begin()
-	if tx.Error != nil {
-		log.Printf("ERROR: HandleRequisitionAction: Failed to begin transaction: %v\n", tx.Error)
-		RespondWithError(w, http.StatusInternalServerError, "Failed to process request: "+tx.Error.Error())
+	if err := db.First(&requisition, requisitionID).Error; err != nil {
+		respondWithLookupError(w, err, "Requisition")
 		return
 	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			log.Printf("PANIC: HandleRequisitionAction: Recovered from panic: %v\n", r)
-			// RespondWithError might not be possible if headers already sent
-			return
-		}
-		if tx.Error != nil {
-			log.Printf("INFO: HandleRequisitionAction: Rolling back transaction due to error: %v\n", tx.Error)
-			tx.Rollback()
-		}
-	}()
-
-	if err := tx.First(&requisition, requisitionID).Error; err != nil { // Fetched within transaction
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			RespondWithError(w, http.StatusNotFound, "Requisition not found.")
-		} else {
-			log.Printf("ERROR: HandleRequisitionAction: Failed to query requisition ID %d: %v\n", requisitionID, err)
-			RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve requisition: "+err.Error())
-		}
-		tx.Rollback() // Explicit rollback
+	level, err := pendingApprovalLevel(db, &requisition)
+	if err != nil {
+		log.Printf("ERROR: HandleRequisitionAction: Failed to load approval level of requisition ID %d: %v\n", requisitionID, err)
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve approval chain: "+err.Error())
+		return
+	}
+	if level == nil {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Requisition is not awaiting approval. Current status: %s", requisition.Status))
+		return
+	}
+	denial, err := checkApprover(db, approver, &requisition, level)
+	if err != nil {
+		log.Printf("ERROR: HandleRequisitionAction: Failed to check approver %d of requisition ID %d: %v\n", approver.ID, requisitionID, err)
+		RespondWithError(w, http.StatusInternalServerError, "Failed to verify approver: "+err.Error())
+		return
+	}
+	if denial != "" {
+		log.Printf("WARN: HandleRequisitionAction: User %d (Role: %s) may not decide level %d of requisition %d.", approver.ID, approver.Role, level.Level, requisitionID)
+		RespondWithError(w, http.StatusForbidden, denial)
 		return
 	}
 
-	// Perform action based on current status
-	log.Printf("DEBUG: HandleRequisitionAction: Validated payload. Action: %s. Requisition ID: %d. Current Requisition Status from DB: %s", payload.Action, requisition.ID, requisition.Status)
-	switch payload.Action {
-	case "approve":
-		switch requisition.Status {
-		case models.RequisitionStatusPendingApproval1, "submitted_for_approval": // Accept both for first approval
-			// Ensure it's not already approved by this admin if it was somehow in pending_approval_1 but had an approver
-			if requisition.ApproverOneID != nil && *requisition.ApproverOneID == adminID && requisition.Status == models.RequisitionStatusPendingApproval2 {
-				log.Printf("WARN: HandleRequisitionAction: Admin %d attempted to re-approve requisition %d which they already first-approved.", adminID, requisitionID)
-				RespondWithError(w, http.StatusForbidden, "You have already performed the first approval.")
-				tx.Rollback()
-				return
-			}
-			requisition.ApproverOneID = &adminID
-			now := time.Now()
-			requisition.ApprovedOneAt = &now
-			requisition.Status = models.RequisitionStatusPendingApproval2
-			log.Printf("INFO: HandleRequisitionAction: Requisition %d approved (1st approval) by admin %d. Status -> %s\n", requisitionID, adminID, requisition.Status)
-		case models.RequisitionStatusPendingApproval2:
-			if requisition.ApproverOneID != nil && *requisition.ApproverOneID == adminID {
-				RespondWithError(w, http.StatusForbidden, "Second approval must be by a different admin.")
-				tx.Rollback()
-				return
-			}
-			requisition.ApproverTwoID = &adminID
-			now := time.Now()
-			requisition.ApprovedTwoAt = &now
-			requisition.Status = models.RequisitionStatusApproved
-			log.Printf("INFO: HandleRequisitionAction: Requisition %d approved (2nd approval) by admin %d. Status -> %s\n", requisitionID, adminID, requisition.Status)
+	step := models.ApprovalStep{
+		RequisitionID: requisition.ID,
+		Level:         level.Level,
+		ApproverID:    approver.ID,
+		Decision:      models.ApprovalDecisionApproved,
+		DecidedAt:     time.Now().UTC(),
+	}
+	updates := map[string]interface{}{}
+	if payload.Reason != "" {
+		step.Comment = &payload.Reason
+	}
+	if payload.Action == "reject" {
+		step.Decision = models.ApprovalDecisionRejected
+		updates["status"] = models.RequisitionStatusRejected
+		updates["current_approval_level"] = 0
+		updates["rejection_reason"] = payload.Reason
+	} else {
+		var next models.ApprovalChainLevel
+		err := db.Where("chain_id = ? AND level > ?", level.ChainID, level.Level).Order("level ASC").First(&next).Error
+		switch {
+		case err == nil:
+			updates["current_approval_level"] = next.Level
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			updates["status"] = models.RequisitionStatusApproved
+			updates["current_approval_level"] = 0
 		default:
-			log.Printf("ERROR: HandleRequisitionAction: Attempt to approve requisition %d in unexpected status '%s' by admin %d.", requisitionID, requisition.Status, adminID)
-			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Cannot approve requisition in status '%s'.", requisition.Status))
-			tx.Rollback() // Ensure rollback
+			log.Printf("ERROR: HandleRequisitionAction: Failed to find the level after %d of requisition ID %d: %v\n", level.Level, requisitionID, err)
+			RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve approval chain: "+err.Error())
 			return
 		}
-	case "reject":
-		if requisition.Status == models.RequisitionStatusApproved || requisition.Status == models.RequisitionStatusTendered || requisition.Status == models.RequisitionStatusClosed {
-			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Requisition cannot be rejected. Current status: %s", requisition.Status))
-			tx.Rollback()
-			return
+	}
+
+	// The update only applies while the requisition still awaits this level, so two approvers
+	// deciding at once cannot both succeed.
+	err = db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Requisition{}).
+			Where("id = ? AND status = ? AND current_approval_level = ?", requisition.ID, models.RequisitionStatusPendingApproval, level.Level).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRequisitionDecided
 		}
-		// For simplicity, any admin can reject at pending_approval_1 or pending_approval_2 stage.
-		// We could record who rejected it if needed, e.g., by setting ApproverOneID/TwoID with a negative value or a dedicated RejectedByID field.
-		requisition.Status = models.RequisitionStatusRejected
-		requisition.RejectionReason = &payload.Reason
-		// Optionally, clear approval fields if it's rejected after first approval
-		// requisition.ApproverOneID = nil
-		// requisition.ApprovedOneAt = nil
-		log.Printf("INFO: HandleRequisitionAction: Requisition %d rejected by admin %d. Reason: %s. Status -> %s\n", requisitionID, adminID, payload.Reason, requisition.Status)
-	}
-
-	if err := tx.Save(&requisition).Error; err != nil {
-		log.Printf("ERROR: HandleRequisitionAction: Failed to save requisition ID %d: %v\n", requisitionID, err)
+		return tx.Create(&step).Error
+	})
+	if errors.Is(err, errRequisitionDecided) {
+		RespondWithError(w, http.StatusConflict, "Requisition was decided by someone else, please reload.")
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: HandleRequisitionAction: Failed to record decision on requisition ID %d: %v\n", requisitionID, err)
 		RespondWithError(w, http.StatusInternalServerError, "Failed to update requisition: "+err.Error())
-		tx.Rollback() // Explicit rollback
 		return
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		log.Printf("ERROR: HandleRequisitionAction: Failed to commit transaction for requisition ID %d: %v\n", requisitionID, err)
-		RespondWithError(w, http.StatusInternalServerError, "Failed to finalize requisition update: "+err.Error())
-		// tx.Rollback() is implicitly handled by defer if Commit fails and sets tx.Error
+	if err := db.Preload("Items").Preload("ApprovalSteps", func(db *gorm.DB) *gorm.DB { return db.Order("decided_at ASC, id ASC") }).First(&requisition, requisition.ID).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Requisition updated but could not be reloaded: "+err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, requisition)
+	log.Printf("INFO: HandleRequisitionAction: User %d %s level %d of requisition ID %d. Status -> %s, level -> %d\n", approver.ID, step.Decision, level.Level, requisitionID, requisition.Status, requisition.CurrentApprovalLevel)
+}
+
+// SubmitRequisitionHandler handles POST requests to submit a draft requisition for approval. Only
+// the requester may submit it; it is routed to the first level of the chain matching its type,
+// department and value, as a requisition created without the draft status is.
+func SubmitRequisitionHandler(w http.ResponseWriter, r *http.Request) {
+	db := database.GetDB()
+	if db == nil {
+		log.Println("ERROR: SubmitRequisitionHandler: Database not initialized")
+		RespondWithError(w, http.StatusInternalServerError, "Database connection not initialized")
+		return
+	}
+
+	user, ok := getAuthenticatedUser(db, w, r)
+	if !ok {
+		return
+	}
+	requisitionID, ok := parseIDParam(w, r, "id")
+	if !ok {
 		return
 	}
 
+	var requisition models.Requisition
+	if err := db.First(&requisition, requisitionID).Error; err != nil {
+		respondWithLookupError(w, err, "Requisition")
+		return
+	}
+	if requisition.UserID != user.ID {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only the requester can submit this requisition.")
+		return
+	}
+	if requisition.Status != models.RequisitionStatusDraft {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Only draft requisitions can be submitted. Current status: %s", requisition.Status))
+		return
+	}
+
+	chain, err := routeRequisition(db, &requisition)
+	if err != nil {
+		log.Printf("ERROR: SubmitRequisitionHandler: Failed to route requisition ID %d: %v\n", requisitionID, err)
+		RespondWithError(w, http.StatusInternalServerError, "Failed to find an approval chain: "+err.Error())
+		return
+	}
+	if chain == nil {
+		RespondWithError(w, http.StatusUnprocessableEntity, "No approval chain applies to this requisition. Ask an admin to configure one.")
+		return
+	}
+
+	// Only a requisition still in draft is submitted, so a double submission cannot route it twice
+	result := db.Model(&models.Requisition{}).
+		Where("id = ? AND status = ?", requisition.ID, models.RequisitionStatusDraft).
+		Updates(map[string]interface{}{
+			"status":                 models.RequisitionStatusPendingApproval,
+			"approval_chain_id":      chain.ID,
+			"current_approval_level": chain.Levels[0].Level,
+		})
+	if result.Error != nil {
+		log.Printf("ERROR: SubmitRequisitionHandler: Failed to submit requisition ID %d: %v\n", requisitionID, result.Error)
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update requisition: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusConflict, "Requisition was already submitted, please reload.")
+		return
+	}
+
+	if err := db.Preload("Items").First(&requisition, requisition.ID).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Requisition submitted but could not be reloaded: "+err.Error())
+		return
+	}
 	RespondWithJSON(w, http.StatusOK, requisition)
-	log.Printf("INFO: HandleRequisitionAction: Successfully processed action '%s' for requisition ID %d by admin %d\n", payload.Action, requisitionID, adminID)
+	log.Printf("INFO: SubmitRequisitionHandler: User %d submitted requisition ID %d to approval chain %d at level %d\n", user.ID, requisitionID, chain.ID, requisition.CurrentApprovalLevel)
 }
@@ -0,0 +1,439 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+
+	"procurement/models"
+	"procurement/services"
+)
+
+const (
+	jobLease         = 5 * time.Minute    // How long a claimed job may run before it is picked up again
+	maxJobAttempts   = 5                  // Failed attempts before a one-off job is given up
+	jobRetryDelay    = time.Minute        // Multiplied by the attempt number between retries
+	dueJobsBatchSize = 100                // Jobs claimed per poll
+	sessionRetention = 7 * 24 * time.Hour // Longest token lifetime; revoked tokens older than this have expired
+)
+
+// recurringJobs are the housekeeping and report jobs the scheduler keeps on its books.
+var recurringJobs = []struct {
+	kind       string
+	recurrence string
+}{
+	{models.JobKindPurgeExpiredTokens, models.JobRecurrenceHourly},
+	{models.JobKindLiftExpiredDebarments, models.JobRecurrenceHourly},
+	{models.JobKindSupplierScorecardReport, models.JobRecurrenceMonthly},
+}
+
+// jobNotDueError asks for a job to run again at a later time without counting a failed attempt.
+type jobNotDueError struct {
+	at time.Time
+}
+
+func (e *jobNotDueError) Error() string {
+	return "not due until " + e.at.Format(time.RFC3339)
+}
+
+// jobRunner does the work of one kind of job. Returning an error retries the job later.
+type jobRunner func(s *Scheduler, job *models.ScheduledJob, now time.Time) error
+
+// Scheduler runs deadline-driven and recurring background jobs stored in the ScheduledJobs
+// table, polling for due jobs in-process.
+type Scheduler struct {
+	DB           *gorm.DB
+	Email        services.EmailService // Asks panel members to join bid openings
+	PollInterval time.Duration
+	runners      map[string]jobRunner
+	stopped      chan struct{} // Closed once the polling started by Start has returned
+}
+
+// NewScheduler creates a Scheduler that looks for due jobs every pollInterval.
+func NewScheduler(db *gorm.DB, email services.EmailService, pollInterval time.Duration) *Scheduler {
+	return &Scheduler{
+		DB:           db,
+		Email:        email,
+		PollInterval: pollInterval,
+		runners: map[string]jobRunner{
+			models.JobKindCloseTender:             (*Scheduler).runCloseTender,
+			models.JobKindStartBidOpening:         (*Scheduler).runStartBidOpening,
+			models.JobKindPurgeExpiredTokens:      (*Scheduler).runPurgeExpiredTokens,
+			models.JobKindLiftExpiredDebarments:   (*Scheduler).runLiftExpiredDebarments,
+			models.JobKindSupplierScorecardReport: (*Scheduler).runSupplierScorecardReport,
+		},
+	}
+}
+
+// nextJobRun returns the next run time of a recurring job after now. Runs missed while the
+// server was down are not made up; the job runs once and carries on from there.
+func nextJobRun(recurrence string, now time.Time) time.Time {
+	now = now.UTC()
+	year, month, day := now.Date()
+	switch recurrence {
+	case models.JobRecurrenceDaily:
+		return time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+	case models.JobRecurrenceMonthly:
+		return time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return now.Truncate(time.Hour).Add(time.Hour)
+	}
+}
+
+// scheduleJob adds a job, or moves the existing job of that kind and entity to runAt when
+// reschedule is set. A moved job starts over as pending.
+func scheduleJob(db *gorm.DB, kind string, entityID int64, runAt time.Time, reschedule bool) error {
+	job := models.ScheduledJob{Kind: kind, EntityID: entityID, RunAt: runAt.UTC(), Status: models.ScheduledJobStatusPending}
+	conflict := clause.OnConflict{Columns: []clause.Column{{Name: "kind"}, {Name: "entity_id"}}, DoNothing: true}
+	if reschedule {
+		conflict = clause.OnConflict{
+			Columns: []clause.Column{{Name: "kind"}, {Name: "entity_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"run_at": job.RunAt, "status": models.ScheduledJobStatusPending, "attempts": 0,
+				"last_error": nil, "locked_until": nil, "updated_at": time.Now().UTC(),
+			}),
+		}
+	}
+	return db.Clauses(conflict).Create(&job).Error
+}
+
+// scheduleTenderJobs schedules the deadline jobs of a tender from its current dates: closing it
+// while it takes bids, and starting its bid opening until that has begun. Pass reschedule to move
+// jobs already on the books after the dates changed.
+func scheduleTenderJobs(db *gorm.DB, tender *models.Tender, reschedule bool) error {
+	status := tenderStatusOf(tender)
+	if status == models.TenderStatusPublished && tender.ClosingDate != nil {
+		if err := scheduleJob(db, models.JobKindCloseTender, tender.ID, *tender.ClosingDate, reschedule); err != nil {
+			return err
+		}
+	}
+	if opensAt := bidOpeningTime(tender); opensAt != nil && (status == models.TenderStatusPublished || status == models.TenderStatusClosed) {
+		if err := scheduleJob(db, models.JobKindStartBidOpening, tender.ID, *opensAt, reschedule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start puts the recurring jobs on the books, schedules the deadlines of open tenders that have
+// none yet, and then runs due jobs in the background until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) error {
+	now := time.Now().UTC()
+	for _, recurring := range recurringJobs {
+		recurrence := recurring.recurrence
+		job := models.ScheduledJob{Kind: recurring.kind, Recurrence: &recurrence, RunAt: now, Status: models.ScheduledJobStatusPending}
+		if err := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&job).Error; err != nil {
+			return fmt.Errorf("failed to schedule %s: %w", recurring.kind, err)
+		}
+	}
+
+	var tenders []models.Tender
+	if err := s.DB.Where(tenderStatusExpr+" IN ?", []string{models.TenderStatusPublished, models.TenderStatusClosed}).Find(&tenders).Error; err != nil {
+		return fmt.Errorf("failed to retrieve open tenders: %w", err)
+	}
+	for i := range tenders {
+		if err := scheduleTenderJobs(s.DB, &tenders[i], false); err != nil {
+			return fmt.Errorf("failed to schedule jobs of tender %d: %w", tenders[i].ID, err)
+		}
+	}
+
+	s.stopped = make(chan struct{})
+	go func() {
+		defer close(s.stopped)
+		ticker := time.NewTicker(s.PollInterval)
+		defer ticker.Stop()
+		for {
+			s.RunDueJobs(time.Now().UTC())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("Scheduler: started, polling every %s", s.PollInterval)
+	return nil
+}
+
+// Wait blocks until the scheduler has stopped polling after the context given to Start was
+// cancelled, letting a job that is already running finish first.
+func (s *Scheduler) Wait() {
+	if s.stopped != nil {
+		<-s.stopped
+	}
+}
+
+// RunDueJobs runs the jobs whose run time has passed, along with running jobs whose lease lapsed
+// because the server stopped mid-run.
+func (s *Scheduler) RunDueJobs(now time.Time) {
+	// Polling is not logged; the jobs it finds are
+	poll := s.DB.Session(&gorm.Session{Logger: s.DB.Logger.LogMode(logger.Warn)})
+	var due []models.ScheduledJob
+	if err := poll.Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until <= ?)",
+		models.ScheduledJobStatusPending, now, models.ScheduledJobStatusRunning, now).
+		Order("run_at ASC, id ASC").Limit(dueJobsBatchSize).Find(&due).Error; err != nil {
+		log.Printf("Scheduler: failed to retrieve due jobs: %v", err)
+		return
+	}
+	for i := range due {
+		s.runJob(&due[i], now)
+	}
+}
+
+// runJob claims a job, runs it and records the outcome. The claim is conditional so a job is
+// never run twice at once.
+func (s *Scheduler) runJob(job *models.ScheduledJob, now time.Time) {
+	result := s.DB.Model(&models.ScheduledJob{}).
+		Where("id = ? AND ((status = ? AND run_at <= ?) OR (status = ? AND locked_until <= ?))",
+			job.ID, models.ScheduledJobStatusPending, now, models.ScheduledJobStatusRunning, now).
+		Updates(map[string]interface{}{"status": models.ScheduledJobStatusRunning, "locked_until": now.Add(jobLease)})
+	if result.Error != nil {
+		log.Printf("Scheduler: failed to claim JobID %d: %v", job.ID, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	runErr := func() (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("job panicked: %v", recovered)
+			}
+		}()
+		runner, ok := s.runners[job.Kind]
+		if !ok {
+			return fmt.Errorf("unknown job kind %q", job.Kind)
+		}
+		return runner(s, job, now)
+	}()
+
+	updates := map[string]interface{}{"locked_until": nil, "last_run_at": now}
+	var notDue *jobNotDueError
+	switch {
+	case errors.As(runErr, &notDue):
+		updates["status"] = models.ScheduledJobStatusPending
+		updates["run_at"] = notDue.at.UTC()
+	case runErr == nil:
+		updates["attempts"] = 0
+		updates["last_error"] = nil
+		if job.Recurrence != nil {
+			updates["status"] = models.ScheduledJobStatusPending
+			updates["run_at"] = nextJobRun(*job.Recurrence, now)
+		} else {
+			updates["status"] = models.ScheduledJobStatusDone
+		}
+	default:
+		attempts := job.Attempts + 1
+		log.Printf("Scheduler: JobID %d (%s) failed on attempt %d: %v", job.ID, job.Kind, attempts, runErr)
+		updates["last_error"] = runErr.Error()
+		switch {
+		case attempts < maxJobAttempts:
+			updates["status"] = models.ScheduledJobStatusPending
+			updates["attempts"] = attempts
+			updates["run_at"] = now.Add(time.Duration(attempts) * jobRetryDelay)
+		case job.Recurrence != nil:
+			// Give up on this run; the next one starts afresh
+			updates["status"] = models.ScheduledJobStatusPending
+			updates["attempts"] = 0
+			updates["run_at"] = nextJobRun(*job.Recurrence, now)
+		default:
+			updates["status"] = models.ScheduledJobStatusFailed
+			updates["attempts"] = attempts
+		}
+	}
+
+	// A job rescheduled while it ran is already pending again and keeps its new run time
+	if err := s.DB.Model(&models.ScheduledJob{}).Where("id = ? AND status = ?", job.ID, models.ScheduledJobStatusRunning).
+		Updates(updates).Error; err != nil {
+		log.Printf("Scheduler: failed to record the outcome of JobID %d: %v", job.ID, err)
+	}
+}
+
+// loadJobTender fetches the tender a job acts on, or nil when it has been deleted.
+func (s *Scheduler) loadJobTender(job *models.ScheduledJob) (*models.Tender, error) {
+	var tender models.Tender
+	err := s.DB.First(&tender, job.EntityID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tender, nil
+}
+
+// runCloseTender closes a published tender once its closing date has passed. A tender closed by
+// hand or cancelled in the meantime is left alone.
+func (s *Scheduler) runCloseTender(job *models.ScheduledJob, now time.Time) error {
+	tender, err := s.loadJobTender(job)
+	if err != nil || tender == nil {
+		return err
+	}
+	if tenderStatusOf(tender) != models.TenderStatusPublished || tender.ClosingDate == nil {
+		return nil
+	}
+	if tender.ClosingDate.After(now) {
+		return &jobNotDueError{at: *tender.ClosingDate}
+	}
+	moved, err := advanceTenderStatus(s.DB, tender.ID, models.TenderStatusPublished, models.TenderStatusClosed, nil)
+	if err != nil {
+		return err
+	}
+	if moved {
+		log.Printf("Scheduler: TenderID %d closed at its closing date", tender.ID)
+	}
+	return nil
+}
+
+// runStartBidOpening starts the bid opening of a closed tender at its bid opening date and asks the
+// members of its evaluation panel to join. The bids are unsealed once a quorum of them has
+// joined (see OpenTenderBids).
+func (s *Scheduler) runStartBidOpening(job *models.ScheduledJob, now time.Time) error {
+	tender, err := s.loadJobTender(job)
+	if err != nil || tender == nil {
+		return err
+	}
+	switch tenderStatusOf(tender) {
+	case models.TenderStatusClosed:
+	case models.TenderStatusPublished:
+		// Its close job has not run yet
+		return &jobNotDueError{at: now.Add(s.PollInterval)}
+	default:
+		return nil
+	}
+	opensAt := bidOpeningTime(tender)
+	if opensAt == nil {
+		return nil
+	}
+	if opensAt.After(now) {
+		return &jobNotDueError{at: *opensAt}
+	}
+
+	panel, err := activePanelForTender(s.DB, tender.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("tender %d has no active evaluation panel to open its bids", tender.ID)
+	}
+	if err != nil {
+		return err
+	}
+	opening, err := startBidOpening(s.DB, tender.ID, panel.ID)
+	if err != nil {
+		return err
+	}
+	if opening.Status == models.BidOpeningStatusOpened {
+		return nil
+	}
+	log.Printf("Scheduler: BidOpeningID %d of TenderID %d started, awaiting %d panel members", opening.ID, tender.ID, opening.Quorum)
+
+	// Notification failures are logged; members can join the opening regardless
+	var recipients []string
+	if err := s.DB.Model(&models.User{}).
+		Joins("JOIN user_evaluation_panel_memberships ON user_evaluation_panel_memberships.user_id = users.id").
+		Where("user_evaluation_panel_memberships.evaluation_panel_id = ?", panel.ID).
+		Pluck("users.email", &recipients).Error; err != nil {
+		log.Printf("Scheduler: failed to find panel members to notify about BidOpeningID %d: %v", opening.ID, err)
+	}
+	for _, recipient := range recipients {
+		if err := s.Email.SendBidOpeningDueEmail(recipient, tender.Title, opening.Quorum); err != nil {
+			log.Printf("Scheduler: failed to notify %s about BidOpeningID %d: %v", recipient, opening.ID, err)
+		}
+	}
+	return nil
+}
+
+// runPurgeExpiredTokens deletes password resets and revoked sessions whose tokens have expired.
+// Sessions recorded without an expiry are kept for the longest token lifetime.
+func (s *Scheduler) runPurgeExpiredTokens(job *models.ScheduledJob, now time.Time) error {
+	resets := s.DB.Where("expires_at <= ?", now).Delete(&models.PasswordReset{})
+	if resets.Error != nil {
+		return resets.Error
+	}
+	var noExpiry time.Time
+	sessions := s.DB.Where("expires_at > ? AND expires_at <= ?", noExpiry, now).
+		Or("expires_at <= ? AND created_at <= ?", noExpiry, now.Add(-sessionRetention)).
+		Delete(&models.Session{})
+	if sessions.Error != nil {
+		return sessions.Error
+	}
+	if resets.RowsAffected > 0 || sessions.RowsAffected > 0 {
+		log.Printf("Scheduler: purged %d expired password resets and %d expired sessions", resets.RowsAffected, sessions.RowsAffected)
+	}
+	return nil
+}
+
+// runLiftExpiredDebarments marks debarments whose end date has passed as expired.
+func (s *Scheduler) runLiftExpiredDebarments(job *models.ScheduledJob, now time.Time) error {
+	_, err := liftExpiredDebarments(s.DB, now)
+	return err
+}
+
+// runSupplierScorecardReport records the scorecard of every supplier for the previous calendar
+// month. A month already reported is left as it is.
+func (s *Scheduler) runSupplierScorecardReport(job *models.ScheduledJob, now time.Time) error {
+	now = now.UTC()
+	periodStart := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd := periodStart.AddDate(0, 1, 0).Add(-time.Nanosecond)
+
+	var supplierIDs []int64
+	if err := s.DB.Model(&models.Supplier{}).Order("id ASC").Pluck("id", &supplierIDs).Error; err != nil {
+		return err
+	}
+	scorecards, err := supplierScorecards(s.DB, supplierIDs, periodStart, periodEnd)
+	if err != nil {
+		return err
+	}
+	ordered := make([]*SupplierScorecard, 0, len(supplierIDs))
+	for _, id := range supplierIDs {
+		ordered = append(ordered, scorecards[id])
+	}
+	data, err := json.Marshal(ordered)
+	if err != nil {
+		return err
+	}
+
+	report := models.Report{Kind: models.ReportKindSupplierScorecards, PeriodStart: periodStart, PeriodEnd: periodEnd, Data: data}
+	result := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&report)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Scheduler: supplier scorecard report for %s recorded (ReportID %d)", periodStart.Format("2006-01"), report.ID)
+	}
+	return nil
+}
+
+// ListScheduledJobs returns the scheduler's jobs, soonest first. Filter with ?status= and ?kind=.
+// GET /api/scheduled-jobs
+func (s *Scheduler) ListScheduledJobs(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(s.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only admins can view scheduled jobs.")
+		return
+	}
+
+	query := s.DB.Model(&models.ScheduledJob{})
+	if status := r.URL.Query().Get("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if kind := r.URL.Query().Get("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var jobs []models.ScheduledJob
+	if err := query.Order("run_at ASC, id ASC").Find(&jobs).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve scheduled jobs: "+err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, jobs)
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// DebarmentInput is the request body for debarring a supplier. StartDate defaults to now and a
+// missing EndDate debars the supplier indefinitely.
+type DebarmentInput struct {
+	Reason    string     `json:"reason"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+}
+
+// DebarmentLiftInput is the request body for lifting a debarment early.
+type DebarmentLiftInput struct {
+	Reason string `json:"reason"`
+}
+
+// inForceDebarments scopes a debarment query to those in force at now. It checks the dates
+// directly, so a debarment stops applying the moment its end date passes even before
+// liftExpiredDebarments has marked it expired. Dates are stored in UTC so SQLite compares them
+// correctly as text.
+func inForceDebarments(db *gorm.DB, now time.Time) *gorm.DB {
+	now = now.UTC()
+	return db.Where("status = ? AND start_date <= ? AND (end_date IS NULL OR end_date > ?)", models.DebarmentStatusActive, now, now)
+}
+
+// activeDebarment returns the debarment in force against a supplier at now, or nil when there is none.
+// When several overlap the one running longest is returned.
+func activeDebarment(db *gorm.DB, supplierID int64, now time.Time) (*models.SupplierDebarment, error) {
+	var debarment models.SupplierDebarment
+	err := inForceDebarments(db, now).Where("supplier_id = ?", supplierID).
+		Order("end_date IS NULL DESC, end_date DESC").First(&debarment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &debarment, nil
+}
+
+// debarmentMessage describes a debarment for an error response.
+func debarmentMessage(debarment *models.SupplierDebarment) string {
+	if debarment.EndDate == nil {
+		return fmt.Sprintf("debarred indefinitely since %s: %s", debarment.StartDate.Format("2006-01-02"), debarment.Reason)
+	}
+	return fmt.Sprintf("debarred until %s: %s", debarment.EndDate.Format("2006-01-02"), debarment.Reason)
+}
+
+// liftExpiredDebarments marks active debarments whose end date has passed as expired.
+func liftExpiredDebarments(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Model(&models.SupplierDebarment{}).
+		Where("status = ? AND end_date IS NOT NULL AND end_date <= ?", models.DebarmentStatusActive, now.UTC()).
+		Updates(map[string]interface{}{"status": models.DebarmentStatusExpired, "lifted_at": gorm.Expr("end_date")})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("liftExpiredDebarments: %d debarments expired", result.RowsAffected)
+	}
+	return result.RowsAffected, nil
+}
+
+// DebarSupplier records a debarment against a supplier.
+// POST /api/suppliers/{id}/debarments
+func (h *SupplierHandler) DebarSupplier(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and admins can debar suppliers.")
+		return
+	}
+	supplierID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	var supplier models.Supplier
+	if err := h.DB.First(&supplier, supplierID).Error; err != nil {
+		respondWithLookupError(w, err, "Supplier")
+		return
+	}
+
+	var input DebarmentInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		RespondWithError(w, http.StatusBadRequest, "A reason is required to debar a supplier.")
+		return
+	}
+	start := time.Now().UTC()
+	if input.StartDate != nil {
+		start = input.StartDate.UTC()
+	}
+	var end *time.Time
+	if input.EndDate != nil {
+		endUTC := input.EndDate.UTC()
+		if !endUTC.After(start) {
+			RespondWithError(w, http.StatusBadRequest, "end_date must be after start_date.")
+			return
+		}
+		end = &endUTC
+	}
+
+	debarment := models.SupplierDebarment{
+		SupplierID: supplier.ID,
+		Reason:     reason,
+		StartDate:  start,
+		EndDate:    end,
+		Status:     models.DebarmentStatusActive,
+		IssuedByID: currentUser.ID,
+	}
+	if err := h.DB.Create(&debarment).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to debar supplier: "+err.Error())
+		return
+	}
+	log.Printf("DebarSupplier: SupplierID %d debarred (DebarmentID %d) by UserID %d", supplier.ID, debarment.ID, currentUser.ID)
+
+	RespondWithJSON(w, http.StatusCreated, debarment)
+}
+
+// ListSupplierDebarments returns a supplier's debarment history, newest first.
+// GET /api/suppliers/{id}/debarments
+func (h *SupplierHandler) ListSupplierDebarments(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	supplierID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") && !actsForSupplier(currentUser, supplierID) {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: You cannot view this supplier's debarments.")
+		return
+	}
+	if _, err := liftExpiredDebarments(h.DB, time.Now()); err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to expire debarments: "+err.Error())
+		return
+	}
+
+	var debarments []models.SupplierDebarment
+	if err := h.DB.Where("supplier_id = ?", supplierID).Order("start_date DESC, id DESC").Find(&debarments).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve debarments: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, debarments)
+}
+
+// ListDebarredSuppliers returns the debarments currently in force with their suppliers.
+// GET /api/debarments/active
+func (h *SupplierHandler) ListDebarredSuppliers(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "approver", "evaluator", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: You do not have access to the debarment list.")
+		return
+	}
+
+	now := time.Now()
+	if _, err := liftExpiredDebarments(h.DB, now); err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to expire debarments: "+err.Error())
+		return
+	}
+	var debarments []models.SupplierDebarment
+	if err := inForceDebarments(h.DB, now).Preload("Supplier").Order("supplier_id ASC, start_date ASC").Find(&debarments).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve debarments: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, debarments)
+}
+
+// LiftDebarment ends an active debarment early. A reason is required.
+// POST /api/debarments/{debarmentId}/lift
+func (h *SupplierHandler) LiftDebarment(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and admins can lift debarments.")
+		return
+	}
+	debarmentID, ok := parseIDParam(w, r, "debarmentId")
+	if !ok {
+		return
+	}
+	var debarment models.SupplierDebarment
+	if err := h.DB.First(&debarment, debarmentID).Error; err != nil {
+		respondWithLookupError(w, err, "Debarment")
+		return
+	}
+
+	var input DebarmentLiftInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		RespondWithError(w, http.StatusBadRequest, "A reason is required to lift a debarment.")
+		return
+	}
+
+	now := time.Now()
+	result := h.DB.Model(&models.SupplierDebarment{}).Where("id = ? AND status = ?", debarment.ID, models.DebarmentStatusActive).
+		Updates(map[string]interface{}{"status": models.DebarmentStatusLifted, "lifted_at": now, "lifted_by_id": currentUser.ID, "lift_reason": reason})
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to lift debarment: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Only active debarments can be lifted (this one is %s).", debarment.Status))
+		return
+	}
+	log.Printf("LiftDebarment: DebarmentID %d for SupplierID %d lifted by UserID %d", debarment.ID, debarment.SupplierID, currentUser.ID)
+
+	debarment.Status = models.DebarmentStatusLifted
+	debarment.LiftedAt = &now
+	debarment.LiftedByID = &currentUser.ID
+	debarment.LiftReason = &reason
+	RespondWithJSON(w, http.StatusOK, debarment)
+}
@@ -17,23 +17,30 @@ type SupplierDashboardData struct {
 // GetSupplierDashboardDataHandler fetches all the necessary data for the supplier dashboard.
 func GetSupplierDashboardDataHandler(w http.ResponseWriter, r *http.Request) {
 	db := database.GetDB()
-	userID, ok := r.Context().Value("userID").(int64)
+	currentUser, ok := getAuthenticatedUser(db, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "supplier") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only suppliers have a supplier dashboard.")
+		return
+	}
+	supplierID, ok := requireSupplierOrganisation(w, currentUser)
 	if !ok {
-		RespondWithError(w, http.StatusUnauthorized, "Could not identify user.")
 		return
 	}
 
 	var data SupplierDashboardData
 
 	// Get stats
-	db.Model(&models.Bid{}).Where("supplier_id = ?", userID).Count(&data.BidsSubmitted)
-	db.Model(&models.Bid{}).Where("supplier_id = ? AND status = ?", userID, "awarded").Count(&data.BidsAwarded)
+	db.Model(&models.Bid{}).Where("supplier_id = ? AND status <> ?", supplierID, "superseded").Count(&data.BidsSubmitted)
+	db.Model(&models.Bid{}).Where("supplier_id = ? AND status = ?", supplierID, "awarded").Count(&data.BidsAwarded)
 
 	// Get active tenders (published)
 	db.Where("status = ?", "published").Find(&data.ActiveTenders)
 
 	// Get my bids
-	db.Where("supplier_id = ?", userID).Preload("Tender").Order("submission_date desc").Find(&data.MyBids)
+	db.Where("supplier_id = ?", supplierID).Preload("Tender").Order("submission_date desc").Find(&data.MyBids)
 
 	RespondWithJSON(w, http.StatusOK, data)
 }
@@ -0,0 +1,486 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+	"procurement/services"
+)
+
+const (
+	maxSupplierDocumentSize = 10 * 1024 * 1024 // 10 MB per document
+	maxSupplierUploadSize   = 50 * 1024 * 1024 // 50 MB per request
+)
+
+// requiredSupplierDocuments must be among the documents of a self-registration.
+var requiredSupplierDocuments = []string{
+	models.SupplierDocumentIncorporation,
+	models.SupplierDocumentTaxClearance,
+}
+
+// SupplierRegistrationResponse is returned after a supplier registers itself.
+type SupplierRegistrationResponse struct {
+	Token    string          `json:"token"`
+	User     models.User     `json:"user"`
+	Supplier models.Supplier `json:"supplier"`
+}
+
+// SupplierReviewInput is the request body for rejecting a supplier registration.
+type SupplierReviewInput struct {
+	Reason string `json:"reason"`
+}
+
+// supplierDocumentUpload is one compliance document taken from a multipart form, not yet stored.
+type supplierDocumentUpload struct {
+	DocumentType string
+	Header       *multipart.FileHeader
+	ExpiryDate   *time.Time
+}
+
+// supplierDocumentsFromForm reads the compliance documents from a parsed multipart form.
+// Files are sent as repeated "documents" parts with a matching "document_types" value each, and
+// optionally a "document_expiry_dates" value (YYYY-MM-DD, may be blank) each.
+func supplierDocumentsFromForm(r *http.Request) ([]supplierDocumentUpload, error) {
+	if r.MultipartForm == nil {
+		return nil, nil
+	}
+	files := r.MultipartForm.File["documents"]
+	types := r.MultipartForm.Value["document_types"]
+	expiries := r.MultipartForm.Value["document_expiry_dates"]
+	if len(types) != len(files) {
+		return nil, fmt.Errorf("every document needs a matching document_types value (%d documents, %d types)", len(files), len(types))
+	}
+	if len(expiries) > 0 && len(expiries) != len(files) {
+		return nil, fmt.Errorf("document_expiry_dates must have one value per document, blank for none")
+	}
+
+	uploads := make([]supplierDocumentUpload, 0, len(files))
+	for i, header := range files {
+		documentType := strings.ToLower(strings.TrimSpace(types[i]))
+		switch documentType {
+		case models.SupplierDocumentIncorporation, models.SupplierDocumentTaxClearance,
+			models.SupplierDocumentBusinessLicence, models.SupplierDocumentOther:
+		default:
+			return nil, fmt.Errorf("document %d has unknown type %q", i+1, types[i])
+		}
+		if header.Size > maxSupplierDocumentSize {
+			return nil, fmt.Errorf("document %q exceeds the 10MB limit", header.Filename)
+		}
+		upload := supplierDocumentUpload{DocumentType: documentType, Header: header}
+		if len(expiries) > 0 && strings.TrimSpace(expiries[i]) != "" {
+			expiry, err := time.Parse("2006-01-02", strings.TrimSpace(expiries[i]))
+			if err != nil {
+				return nil, fmt.Errorf("document %d has an invalid expiry date, expected YYYY-MM-DD", i+1)
+			}
+			upload.ExpiryDate = &expiry
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, nil
+}
+
+// storeSupplierDocuments puts the uploads in the blob store under suppliers/{id}/documents/ and
+// records them. Objects already stored are deleted again if a later one fails, so the caller's
+// transaction can roll back cleanly.
+func storeSupplierDocuments(ctx context.Context, tx *gorm.DB, blobs services.BlobStore, supplierID int64, uploads []supplierDocumentUpload, uploadedByID *int64) ([]models.SupplierDocument, error) {
+	documents := make([]models.SupplierDocument, 0, len(uploads))
+	var stored []string
+	cleanup := func() {
+		for _, key := range stored {
+			blobs.Delete(ctx, key)
+		}
+	}
+
+	for _, upload := range uploads {
+		contentType := upload.Header.Header.Get("Content-Type")
+		key := newBlobKey(upload.Header.Filename, "suppliers", strconv.FormatInt(supplierID, 10), "documents")
+		if err := putUploadedFile(ctx, blobs, key, upload.Header, contentType); err != nil {
+			cleanup()
+			return nil, err
+		}
+		stored = append(stored, key)
+
+		document := models.SupplierDocument{
+			SupplierID:   supplierID,
+			DocumentType: upload.DocumentType,
+			FileName:     upload.Header.Filename,
+			StorageKey:   key,
+			Size:         upload.Header.Size,
+			ExpiryDate:   upload.ExpiryDate,
+			UploadedByID: uploadedByID,
+		}
+		if contentType != "" {
+			document.ContentType = &contentType
+		}
+		if err := tx.Create(&document).Error; err != nil {
+			cleanup()
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
+
+// newBlobKey builds a blob store key for an uploaded file under the given prefix segments. The
+// random part keeps keys unique across replicas; the file name only helps when browsing the store.
+func newBlobKey(fileName string, prefix ...string) string {
+	random := make([]byte, 12)
+	rand.Read(random)
+	return path.Join(append(prefix, hex.EncodeToString(random)+"_"+SanitizeFilename(fileName))...)
+}
+
+// putUploadedFile copies an uploaded multipart file into the blob store under key.
+func putUploadedFile(ctx context.Context, blobs services.BlobStore, key string, header *multipart.FileHeader, contentType string) error {
+	src, err := header.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read %q: %w", header.Filename, err)
+	}
+	defer src.Close()
+
+	if err := blobs.Put(ctx, key, src, header.Size, contentType); err != nil {
+		return fmt.Errorf("failed to save %q: %w", header.Filename, err)
+	}
+	return nil
+}
+
+// putSealedUploadedFile reads an uploaded multipart file, seals it and puts the sealed content
+// into the blob store under key. Uploads are size-limited, so the file is sealed in memory.
+func putSealedUploadedFile(ctx context.Context, blobs services.BlobStore, key string, header *multipart.FileHeader, seal func([]byte) ([]byte, error)) error {
+	src, err := header.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read %q: %w", header.Filename, err)
+	}
+	defer src.Close()
+	plaintext, err := io.ReadAll(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %q: %w", header.Filename, err)
+	}
+	sealed, err := seal(plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to seal %q: %w", header.Filename, err)
+	}
+
+	if err := blobs.Put(ctx, key, bytes.NewReader(sealed), int64(len(sealed)), "application/octet-stream"); err != nil {
+		return fmt.Errorf("failed to save %q: %w", header.Filename, err)
+	}
+	return nil
+}
+
+// serveBlob streams the object under key with http.ServeContent, which also answers range and
+// conditional requests. disposition is "inline" or "attachment"; what names the file in error responses.
+func serveBlob(w http.ResponseWriter, r *http.Request, blobs services.BlobStore, key, fileName, disposition string, modTime time.Time, what string) {
+	object, err := blobs.Open(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, services.ErrBlobNotFound) {
+			RespondWithError(w, http.StatusNotFound, what+" file is missing")
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to open "+strings.ToLower(what)+": "+err.Error())
+		}
+		return
+	}
+	defer object.Close()
+
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": fileName}))
+	http.ServeContent(w, r, fileName, modTime, object)
+}
+
+// formValueOrNil returns the trimmed form value, or nil when it is missing or blank.
+func formValueOrNil(r *http.Request, key string) *string {
+	value := r.FormValue(key)
+	return trimmedOrNil(&value)
+}
+
+// RegisterSupplier lets a supplier sign up with its company details and compliance documents.
+// It creates the supplier organisation in pending_verification together with a supplier user
+// linked to it; bids are refused until a procurement officer approves the registration.
+// POST /api/auth/register/supplier (multipart/form-data)
+func (c *AuthController) RegisterSupplier(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSupplierUploadSize)
+	if err := r.ParseMultipartForm(maxSupplierUploadSize); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Failed to parse multipart form: "+err.Error())
+		return
+	}
+
+	email := strings.TrimSpace(r.FormValue("email"))
+	username := strings.TrimSpace(r.FormValue("username"))
+	password := r.FormValue("password")
+	if email == "" || username == "" {
+		RespondWithError(w, http.StatusBadRequest, "email and username are required.")
+		return
+	}
+	if err := c.PasswordService.ValidatePasswordStrength(password); err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	input := SupplierInput{
+		Name:               r.FormValue("company_name"),
+		RegistrationNumber: formValueOrNil(r, "registration_number"),
+		TaxID:              formValueOrNil(r, "tax_id"),
+		ContactPerson:      formValueOrNil(r, "contact_person"),
+		Email:              &email,
+		Phone:              formValueOrNil(r, "phone"),
+		Address:            formValueOrNil(r, "address"),
+		BankName:           formValueOrNil(r, "bank_name"),
+		BankBranch:         formValueOrNil(r, "bank_branch"),
+		BankAccountName:    formValueOrNil(r, "bank_account_name"),
+		BankAccountNumber:  formValueOrNil(r, "bank_account_number"),
+	}
+	for _, value := range r.MultipartForm.Value["categories"] {
+		input.Categories = append(input.Categories, strings.Split(value, ",")...)
+	}
+	supplier := models.Supplier{Status: models.SupplierStatusPendingVerification}
+	categories, err := supplierFromInput(&input, &supplier)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if supplier.RegistrationNumber == nil || supplier.TaxID == nil {
+		RespondWithError(w, http.StatusBadRequest, "registration_number and tax_id are required.")
+		return
+	}
+
+	uploads, err := supplierDocumentsFromForm(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid documents: "+err.Error())
+		return
+	}
+	submitted := make(map[string]bool, len(uploads))
+	for _, upload := range uploads {
+		submitted[upload.DocumentType] = true
+	}
+	for _, required := range requiredSupplierDocuments {
+		if !submitted[required] {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("A %s document is required.", required))
+			return
+		}
+	}
+
+	var existing int64
+	if err := c.DB.Model(&models.User{}).Where("email = ? OR username = ?", email, username).Count(&existing).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Database error: "+err.Error())
+		return
+	}
+	if existing > 0 {
+		RespondWithError(w, http.StatusConflict, "Email or username already registered")
+		return
+	}
+	if !checkSupplierUniqueness(c.DB, w, &supplier) {
+		return
+	}
+
+	hashedPassword, err := c.PasswordService.HashPassword(password)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to hash password")
+		return
+	}
+
+	user := models.User{
+		Email:         email,
+		Username:      username,
+		PasswordHash:  hashedPassword,
+		Role:          "supplier",
+		ContactNumber: supplier.Phone,
+		IsActive:      true,
+	}
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		supplier.Categories = categories
+		if err := tx.Create(&supplier).Error; err != nil {
+			return err
+		}
+		user.SupplierID = &supplier.ID
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		documents, err := storeSupplierDocuments(r.Context(), tx, c.Blobs, supplier.ID, uploads, &user.ID)
+		if err != nil {
+			return err
+		}
+		supplier.Documents = documents
+		return nil
+	})
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to register supplier: "+err.Error())
+		return
+	}
+	log.Printf("RegisterSupplier: SupplierID %d (%s) registered by UserID %d, awaiting verification", supplier.ID, supplier.Name, user.ID)
+
+	token, err := c.TokenService.GenerateToken(&user)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+	RespondWithJSON(w, http.StatusCreated, SupplierRegistrationResponse{Token: token, User: user, Supplier: supplier})
+}
+
+// reviewSupplier moves a supplier out of pending_verification, recording who reviewed it.
+func (h *SupplierHandler) reviewSupplier(w http.ResponseWriter, r *http.Request, to string, reason *string) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and admins can review supplier registrations.")
+		return
+	}
+	supplier, ok := h.loadSupplier(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	result := h.DB.Model(&models.Supplier{}).
+		Where("id = ? AND status = ?", supplier.ID, models.SupplierStatusPendingVerification).
+		Updates(map[string]interface{}{"status": to, "verified_by_id": currentUser.ID, "verified_at": now, "rejection_reason": reason})
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update supplier: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Supplier is %s, only suppliers awaiting verification can be reviewed.", supplier.Status))
+		return
+	}
+	log.Printf("reviewSupplier: SupplierID %d moved to %s by UserID %d", supplier.ID, to, currentUser.ID)
+
+	supplier.Status = to
+	supplier.VerifiedByID = &currentUser.ID
+	supplier.VerifiedAt = &now
+	supplier.RejectionReason = reason
+	RespondWithJSON(w, http.StatusOK, supplier)
+}
+
+// ApproveSupplier approves a self-registered supplier so its users can bid.
+// POST /api/suppliers/{id}/approve
+func (h *SupplierHandler) ApproveSupplier(w http.ResponseWriter, r *http.Request) {
+	h.reviewSupplier(w, r, models.SupplierStatusActive, nil)
+}
+
+// RejectSupplier refuses a self-registered supplier. A reason is required; the supplier may upload
+// corrected documents to be reviewed again.
+// POST /api/suppliers/{id}/reject
+func (h *SupplierHandler) RejectSupplier(w http.ResponseWriter, r *http.Request) {
+	var input SupplierReviewInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	reason := trimmedOrNil(&input.Reason)
+	if reason == nil {
+		RespondWithError(w, http.StatusBadRequest, "A reason is required to reject a supplier.")
+		return
+	}
+	h.reviewSupplier(w, r, models.SupplierStatusRejected, reason)
+}
+
+// UploadSupplierDocuments adds compliance documents to a supplier, using the same multipart fields
+// as registration. When a rejected supplier's own user uploads, the registration goes back to
+// pending_verification for another review.
+// POST /api/suppliers/{id}/documents (multipart/form-data)
+func (h *SupplierHandler) UploadSupplierDocuments(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	supplier, ok := h.loadSupplier(w, r)
+	if !ok {
+		return
+	}
+	ownUser := actsForSupplier(currentUser, supplier.ID)
+	if !ownUser && !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: You cannot upload documents for this supplier.")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxSupplierUploadSize)
+	if err := r.ParseMultipartForm(maxSupplierUploadSize); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Failed to parse multipart form: "+err.Error())
+		return
+	}
+	uploads, err := supplierDocumentsFromForm(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid documents: "+err.Error())
+		return
+	}
+	if len(uploads) == 0 {
+		RespondWithError(w, http.StatusBadRequest, "At least one document is required.")
+		return
+	}
+
+	resubmitted := false
+	var documents []models.SupplierDocument
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		documents, err = storeSupplierDocuments(r.Context(), tx, h.Blobs, supplier.ID, uploads, &currentUser.ID)
+		if err != nil {
+			return err
+		}
+		if ownUser && supplier.Status == models.SupplierStatusRejected {
+			result := tx.Model(&models.Supplier{}).
+				Where("id = ? AND status = ?", supplier.ID, models.SupplierStatusRejected).
+				Updates(map[string]interface{}{"status": models.SupplierStatusPendingVerification, "verified_by_id": nil, "verified_at": nil})
+			if result.Error != nil {
+				return result.Error
+			}
+			resubmitted = result.RowsAffected > 0
+		}
+		return nil
+	})
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to store documents: "+err.Error())
+		return
+	}
+	if resubmitted {
+		log.Printf("UploadSupplierDocuments: SupplierID %d resubmitted for verification by UserID %d", supplier.ID, currentUser.ID)
+	}
+
+	RespondWithJSON(w, http.StatusCreated, documents)
+}
+
+// DownloadSupplierDocument streams a supplier's compliance document to procurement officers,
+// admins and the supplier's own users.
+// GET /api/suppliers/{id}/documents/{documentId}
+func (h *SupplierHandler) DownloadSupplierDocument(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	supplierID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	documentID, ok := parseIDParam(w, r, "documentId")
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") && !actsForSupplier(currentUser, supplierID) {
+		RespondWithError(w, http.StatusNotFound, "Document not found")
+		return
+	}
+
+	var document models.SupplierDocument
+	if err := h.DB.Where("id = ? AND supplier_id = ?", documentID, supplierID).First(&document).Error; err != nil {
+		respondWithLookupError(w, err, "Document")
+		return
+	}
+	if document.ContentType != nil {
+		w.Header().Set("Content-Type", *document.ContentType)
+	}
+	serveBlob(w, r, h.Blobs, document.StorageKey, document.FileName, "attachment", document.CreatedAt, "Document")
+}
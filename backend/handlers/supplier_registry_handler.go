@@ -0,0 +1,461 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+	"procurement/services"
+)
+
+// SupplierHandler holds dependencies for the supplier organisation registry handlers.
+type SupplierHandler struct {
+	DB    *gorm.DB
+	Blobs services.BlobStore
+}
+
+// NewSupplierHandler creates a new SupplierHandler with the given DB connection and blob store.
+func NewSupplierHandler(db *gorm.DB, blobs services.BlobStore) *SupplierHandler {
+	return &SupplierHandler{DB: db, Blobs: blobs}
+}
+
+// SupplierInput is the request body for creating or updating a supplier organisation.
+type SupplierInput struct {
+	Name               string   `json:"name"`
+	RegistrationNumber *string  `json:"registration_number,omitempty"`
+	TaxID              *string  `json:"tax_id,omitempty"`
+	ContactPerson      *string  `json:"contact_person,omitempty"`
+	Email              *string  `json:"email,omitempty"`
+	Phone              *string  `json:"phone,omitempty"`
+	Address            *string  `json:"address,omitempty"`
+	Status             string   `json:"status,omitempty"`
+	BankName           *string  `json:"bank_name,omitempty"`
+	BankBranch         *string  `json:"bank_branch,omitempty"`
+	BankAccountName    *string  `json:"bank_account_name,omitempty"`
+	BankAccountNumber  *string  `json:"bank_account_number,omitempty"`
+	Categories         []string `json:"categories,omitempty"`
+}
+
+// SupplierUserInput is the request body for linking a user to a supplier organisation.
+type SupplierUserInput struct {
+	UserID int64 `json:"user_id"`
+}
+
+// requireSupplierOrganisation returns the supplier organisation a supplier user acts for.
+// It writes a 403 and returns false when the user is not linked to one.
+func requireSupplierOrganisation(w http.ResponseWriter, user *models.User) (int64, bool) {
+	if user.SupplierID == nil {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Your account is not linked to a supplier organisation.")
+		return 0, false
+	}
+	return *user.SupplierID, true
+}
+
+// actsForSupplier reports whether the user is a supplier user acting for the given organisation.
+func actsForSupplier(user *models.User, supplierID int64) bool {
+	return hasRole(user, "supplier") && user.SupplierID != nil && *user.SupplierID == supplierID
+}
+
+// requireActiveSupplier loads the supplier organisation a supplier user acts for and makes sure it
+// has been approved and is active. It writes a 403 and returns false otherwise.
+func requireActiveSupplier(db *gorm.DB, w http.ResponseWriter, user *models.User) (*models.Supplier, bool) {
+	supplierID, ok := requireSupplierOrganisation(w, user)
+	if !ok {
+		return nil, false
+	}
+	var supplier models.Supplier
+	if err := db.First(&supplier, supplierID).Error; err != nil {
+		respondWithLookupError(w, err, "Supplier")
+		return nil, false
+	}
+	switch supplier.Status {
+	case models.SupplierStatusActive:
+		return &supplier, true
+	case models.SupplierStatusPendingVerification:
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Your supplier registration is awaiting verification by a procurement officer.")
+	case models.SupplierStatusRejected:
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Your supplier registration was rejected.")
+	default:
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Your supplier organisation is not active.")
+	}
+	return nil, false
+}
+
+// trimmedOrNil trims an optional string and drops it when it is empty.
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// supplierFromInput validates the input and copies it onto the supplier, returning its categories.
+func supplierFromInput(input *SupplierInput, supplier *models.Supplier) ([]models.SupplierCategory, error) {
+	supplier.Name = strings.TrimSpace(input.Name)
+	if supplier.Name == "" {
+		return nil, fmt.Errorf("supplier name is required")
+	}
+	status := strings.ToLower(strings.TrimSpace(input.Status))
+	switch status {
+	case "":
+		if supplier.Status == "" {
+			supplier.Status = models.SupplierStatusActive
+		}
+	case models.SupplierStatusActive, models.SupplierStatusInactive:
+		if status != supplier.Status && (supplier.Status == models.SupplierStatusPendingVerification || supplier.Status == models.SupplierStatusRejected) {
+			return nil, fmt.Errorf("a supplier awaiting or refused verification can only change status through approval")
+		}
+		supplier.Status = status
+	default:
+		return nil, fmt.Errorf("status must be 'active' or 'inactive'")
+	}
+
+	supplier.RegistrationNumber = trimmedOrNil(input.RegistrationNumber)
+	supplier.TaxID = trimmedOrNil(input.TaxID)
+	supplier.ContactPerson = trimmedOrNil(input.ContactPerson)
+	supplier.Email = trimmedOrNil(input.Email)
+	supplier.Phone = trimmedOrNil(input.Phone)
+	supplier.Address = trimmedOrNil(input.Address)
+	supplier.BankName = trimmedOrNil(input.BankName)
+	supplier.BankBranch = trimmedOrNil(input.BankBranch)
+	supplier.BankAccountName = trimmedOrNil(input.BankAccountName)
+	supplier.BankAccountNumber = trimmedOrNil(input.BankAccountNumber)
+
+	categories := make([]models.SupplierCategory, 0, len(input.Categories))
+	seen := make(map[string]bool, len(input.Categories))
+	for _, category := range input.Categories {
+		category = strings.TrimSpace(category)
+		if category == "" || seen[strings.ToLower(category)] {
+			continue
+		}
+		seen[strings.ToLower(category)] = true
+		categories = append(categories, models.SupplierCategory{SupplierID: supplier.ID, Category: category})
+	}
+	return categories, nil
+}
+
+// checkSupplierUniqueness makes sure no other supplier uses the same name, registration number, tax ID or email.
+func checkSupplierUniqueness(db *gorm.DB, w http.ResponseWriter, supplier *models.Supplier) bool {
+	conditions := []string{"LOWER(name) = LOWER(?)"}
+	args := []interface{}{supplier.Name}
+	if supplier.RegistrationNumber != nil {
+		conditions = append(conditions, "registration_number = ?")
+		args = append(args, *supplier.RegistrationNumber)
+	}
+	if supplier.TaxID != nil {
+		conditions = append(conditions, "tax_id = ?")
+		args = append(args, *supplier.TaxID)
+	}
+	if supplier.Email != nil {
+		conditions = append(conditions, "LOWER(email) = LOWER(?)")
+		args = append(args, *supplier.Email)
+	}
+
+	var count int64
+	if err := db.Model(&models.Supplier{}).Where("id <> ?", supplier.ID).
+		Where(strings.Join(conditions, " OR "), args...).Count(&count).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check existing suppliers: "+err.Error())
+		return false
+	}
+	if count > 0 {
+		RespondWithError(w, http.StatusConflict, "Another supplier already uses this name, registration number, tax ID or email.")
+		return false
+	}
+	return true
+}
+
+// loadSupplier fetches the supplier from the {id} URL parameter with its categories, users and documents.
+func (h *SupplierHandler) loadSupplier(w http.ResponseWriter, r *http.Request) (*models.Supplier, bool) {
+	supplierID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return nil, false
+	}
+	var supplier models.Supplier
+	if err := h.DB.Preload("Categories").Preload("Users").Preload("Documents").First(&supplier, supplierID).Error; err != nil {
+		respondWithLookupError(w, err, "Supplier")
+		return nil, false
+	}
+	return &supplier, true
+}
+
+// ListSuppliers returns the supplier registry. Optional ?status= and ?category= query parameters filter it.
+// GET /api/suppliers
+func (h *SupplierHandler) ListSuppliers(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and admins can view the supplier registry.")
+		return
+	}
+
+	query := h.DB.Model(&models.Supplier{}).Preload("Categories")
+	if status := strings.TrimSpace(r.URL.Query().Get("status")); status != "" {
+		query = query.Where("status = ?", strings.ToLower(status))
+	}
+	if category := strings.TrimSpace(r.URL.Query().Get("category")); category != "" {
+		query = query.Where("id IN (?)", h.DB.Model(&models.SupplierCategory{}).Select("supplier_id").Where("LOWER(category) = LOWER(?)", category))
+	}
+
+	var suppliers []models.Supplier
+	if err := query.Order("name ASC").Find(&suppliers).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve suppliers: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, suppliers)
+}
+
+// GetSupplier returns a supplier organisation with its categories and users.
+// Supplier users may read their own organisation.
+// GET /api/suppliers/{id}
+func (h *SupplierHandler) GetSupplier(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	supplier, ok := h.loadSupplier(w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") && !actsForSupplier(currentUser, supplier.ID) {
+		RespondWithError(w, http.StatusNotFound, "Supplier not found")
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, supplier)
+}
+
+// CreateSupplier adds a supplier organisation to the registry.
+// POST /api/suppliers
+func (h *SupplierHandler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and admins can register suppliers.")
+		return
+	}
+
+	var input SupplierInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	var supplier models.Supplier
+	categories, err := supplierFromInput(&input, &supplier)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !checkSupplierUniqueness(h.DB, w, &supplier) {
+		return
+	}
+
+	supplier.Categories = categories
+	if err := h.DB.Create(&supplier).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to create supplier: "+err.Error())
+		return
+	}
+	log.Printf("CreateSupplier: Registered SupplierID %d (%s) by UserID %d", supplier.ID, supplier.Name, currentUser.ID)
+
+	RespondWithJSON(w, http.StatusCreated, supplier)
+}
+
+// UpdateSupplier replaces a supplier organisation's details and categories.
+// PUT /api/suppliers/{id}
+func (h *SupplierHandler) UpdateSupplier(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and admins can change suppliers.")
+		return
+	}
+	supplier, ok := h.loadSupplier(w, r)
+	if !ok {
+		return
+	}
+
+	var input SupplierInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	categories, err := supplierFromInput(&input, supplier)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !checkSupplierUniqueness(h.DB, w, supplier) {
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Categories", "Users", "Documents").Save(supplier).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("supplier_id = ?", supplier.ID).Delete(&models.SupplierCategory{}).Error; err != nil {
+			return err
+		}
+		if len(categories) > 0 {
+			return tx.Create(&categories).Error
+		}
+		return nil
+	})
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update supplier: "+err.Error())
+		return
+	}
+
+	supplier.Categories = categories
+	RespondWithJSON(w, http.StatusOK, supplier)
+}
+
+// DeleteSupplier removes a supplier organisation that has no bids or purchase orders and unlinks its users.
+// DELETE /api/suppliers/{id}
+func (h *SupplierHandler) DeleteSupplier(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only admins can delete suppliers.")
+		return
+	}
+	supplier, ok := h.loadSupplier(w, r)
+	if !ok {
+		return
+	}
+
+	var bids, orders int64
+	if err := h.DB.Model(&models.Bid{}).Where("supplier_id = ?", supplier.ID).Count(&bids).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check supplier bids: "+err.Error())
+		return
+	}
+	if err := h.DB.Model(&models.PurchaseOrder{}).Where("supplier_id = ?", supplier.ID).Count(&orders).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check supplier purchase orders: "+err.Error())
+		return
+	}
+	if bids > 0 || orders > 0 {
+		RespondWithError(w, http.StatusConflict, "A supplier with bids or purchase orders cannot be deleted; set it inactive instead.")
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("supplier_id = ?", supplier.ID).Update("supplier_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("supplier_id = ?", supplier.ID).Delete(&models.SupplierCategory{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Supplier{}, supplier.ID).Error
+	})
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to delete supplier: "+err.Error())
+		return
+	}
+	log.Printf("DeleteSupplier: Deleted SupplierID %d by UserID %d", supplier.ID, currentUser.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddSupplierUser links a supplier user to a supplier organisation so they can act for it.
+// POST /api/suppliers/{id}/users
+func (h *SupplierHandler) AddSupplierUser(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and admins can link supplier users.")
+		return
+	}
+	supplier, ok := h.loadSupplier(w, r)
+	if !ok {
+		return
+	}
+
+	var input SupplierUserInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	var user models.User
+	if err := h.DB.First(&user, input.UserID).Error; err != nil {
+		respondWithLookupError(w, err, "User")
+		return
+	}
+	if !hasRole(&user, "supplier") {
+		RespondWithError(w, http.StatusBadRequest, "Only users with the supplier role can be linked to a supplier.")
+		return
+	}
+	if user.SupplierID != nil {
+		if *user.SupplierID == supplier.ID {
+			RespondWithError(w, http.StatusConflict, "User is already linked to this supplier.")
+		} else {
+			RespondWithError(w, http.StatusConflict, "User is already linked to another supplier.")
+		}
+		return
+	}
+
+	result := h.DB.Model(&models.User{}).Where("id = ? AND supplier_id IS NULL", user.ID).Update("supplier_id", supplier.ID)
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to link user: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusConflict, "User was linked to a supplier by someone else, please reload.")
+		return
+	}
+	log.Printf("AddSupplierUser: UserID %d linked to SupplierID %d by UserID %d", user.ID, supplier.ID, currentUser.ID)
+
+	user.SupplierID = &supplier.ID
+	RespondWithJSON(w, http.StatusOK, user)
+}
+
+// RemoveSupplierUser unlinks a user from a supplier organisation.
+// DELETE /api/suppliers/{id}/users/{userId}
+func (h *SupplierHandler) RemoveSupplierUser(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "admin") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and admins can unlink supplier users.")
+		return
+	}
+	supplierID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(w, r, "userId")
+	if !ok {
+		return
+	}
+
+	result := h.DB.Model(&models.User{}).Where("id = ? AND supplier_id = ?", userID, supplierID).Update("supplier_id", nil)
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to unlink user: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusNotFound, "User is not linked to this supplier")
+		return
+	}
+	log.Printf("RemoveSupplierUser: UserID %d unlinked from SupplierID %d by UserID %d", userID, supplierID, currentUser.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// defaultScorecardMonths is the rolling window of a supplier scorecard unless ?months= says otherwise.
+const defaultScorecardMonths = 12
+
+// SupplierRatingInput is the request body for rating a supplier on a goods received note.
+// Each score runs from 1 (poor) to 5 (excellent).
+type SupplierRatingInput struct {
+	Timeliness int     `json:"timeliness"`
+	Quality    int     `json:"quality"`
+	Compliance int     `json:"compliance"`
+	Comment    *string `json:"comment,omitempty"`
+}
+
+// SupplierScorecard summarises a supplier's performance over a rolling window.
+// Percentages and averages are nil when there is nothing to base them on.
+type SupplierScorecard struct {
+	SupplierID  int64     `json:"supplier_id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+
+	// Goods received notes in the window. On-time delivery only counts notes against purchase
+	// orders with an expected delivery date; the rejection rate only counts inspected notes.
+	Deliveries            int64    `json:"deliveries"`
+	DeliveriesWithDueDate int64    `json:"deliveries_with_due_date"`
+	OnTimeDeliveries      int64    `json:"on_time_deliveries"`
+	OnTimeDeliveryPercent *float64 `json:"on_time_delivery_percent"`
+	InspectedDeliveries   int64    `json:"inspected_deliveries"`
+	RejectedDeliveries    int64    `json:"rejected_deliveries"`
+	RejectionRatePercent  *float64 `json:"rejection_rate_percent"`
+
+	// Invoices in the window and how many failed three-way matching.
+	Invoices               int64    `json:"invoices"`
+	InvoicesWithVariance   int64    `json:"invoices_with_variance"`
+	InvoiceVariancePercent *float64 `json:"invoice_variance_percent"`
+
+	// Receiver ratings in the window. AverageRating is the mean of the three averages.
+	Ratings           int64    `json:"ratings"`
+	AverageTimeliness *float64 `json:"average_timeliness"`
+	AverageQuality    *float64 `json:"average_quality"`
+	AverageCompliance *float64 `json:"average_compliance"`
+	AverageRating     *float64 `json:"average_rating"`
+}
+
+// TenderBid is a bid as listed to evaluators, with the supplier's scorecard alongside it.
+type TenderBid struct {
+	models.Bid
+	SupplierScorecard *SupplierScorecard `json:"supplier_scorecard,omitempty"`
+}
+
+// percentOf returns part as a percentage of whole, rounded to two decimals, or nil when whole is zero.
+func percentOf(part, whole int64) *float64 {
+	if whole == 0 {
+		return nil
+	}
+	percent := math.Round(float64(part)/float64(whole)*10000) / 100
+	return &percent
+}
+
+// roundedAverage rounds an average to two decimals, keeping nil as nil.
+func roundedAverage(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	rounded := math.Round(*value*100) / 100
+	return &rounded
+}
+
+// supplierScorecards computes the scorecards of several suppliers over [since, until] in one
+// query per source, so listing many bids does not cost a query per bid.
+func supplierScorecards(db *gorm.DB, supplierIDs []int64, since, until time.Time) (map[int64]*SupplierScorecard, error) {
+	scorecards := make(map[int64]*SupplierScorecard, len(supplierIDs))
+	for _, id := range supplierIDs {
+		scorecards[id] = &SupplierScorecard{SupplierID: id, PeriodStart: since, PeriodEnd: until}
+	}
+	if len(supplierIDs) == 0 {
+		return scorecards, nil
+	}
+
+	var deliveryRows []struct {
+		SupplierID  int64
+		Total       int64
+		WithDueDate int64
+		OnTime      int64
+		Inspected   int64
+		Rejected    int64
+	}
+	err := db.Table("deliveries").
+		Select(`purchase_orders.supplier_id AS supplier_id,
+			COUNT(deliveries.id) AS total,
+			SUM(CASE WHEN purchase_orders.expected_delivery_date IS NOT NULL THEN 1 ELSE 0 END) AS with_due_date,
+			SUM(CASE WHEN purchase_orders.expected_delivery_date IS NOT NULL
+				AND date(deliveries.delivery_date) <= date(purchase_orders.expected_delivery_date) THEN 1 ELSE 0 END) AS on_time,
+			SUM(CASE WHEN deliveries.compliance_status IS NOT NULL THEN 1 ELSE 0 END) AS inspected,
+			SUM(CASE WHEN deliveries.compliance_status = ? THEN 1 ELSE 0 END) AS rejected`, models.ComplianceStatusNonCompliant).
+		Joins("JOIN purchase_orders ON purchase_orders.id = deliveries.purchase_order_id").
+		Where("purchase_orders.supplier_id IN ?", supplierIDs).
+		Where("julianday(deliveries.delivery_date) BETWEEN julianday(?) AND julianday(?)", since, until).
+		Group("purchase_orders.supplier_id").
+		Scan(&deliveryRows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range deliveryRows {
+		scorecard := scorecards[row.SupplierID]
+		scorecard.Deliveries = row.Total
+		scorecard.DeliveriesWithDueDate = row.WithDueDate
+		scorecard.OnTimeDeliveries = row.OnTime
+		scorecard.OnTimeDeliveryPercent = percentOf(row.OnTime, row.WithDueDate)
+		scorecard.InspectedDeliveries = row.Inspected
+		scorecard.RejectedDeliveries = row.Rejected
+		scorecard.RejectionRatePercent = percentOf(row.Rejected, row.Inspected)
+	}
+
+	var invoiceRows []struct {
+		SupplierID   int64
+		Total        int64
+		WithVariance int64
+	}
+	err = db.Model(&models.Invoice{}).
+		Select("supplier_id, COUNT(id) AS total, SUM(CASE WHEN has_variance THEN 1 ELSE 0 END) AS with_variance").
+		Where("supplier_id IN ?", supplierIDs).
+		Where("julianday(invoice_date) BETWEEN julianday(?) AND julianday(?)", since, until).
+		Group("supplier_id").
+		Scan(&invoiceRows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range invoiceRows {
+		scorecard := scorecards[row.SupplierID]
+		scorecard.Invoices = row.Total
+		scorecard.InvoicesWithVariance = row.WithVariance
+		scorecard.InvoiceVariancePercent = percentOf(row.WithVariance, row.Total)
+	}
+
+	var ratingRows []struct {
+		SupplierID int64
+		Total      int64
+		Timeliness *float64
+		Quality    *float64
+		Compliance *float64
+	}
+	err = db.Model(&models.SupplierRating{}).
+		Select("supplier_id, COUNT(id) AS total, AVG(timeliness) AS timeliness, AVG(quality) AS quality, AVG(compliance) AS compliance").
+		Where("supplier_id IN ?", supplierIDs).
+		Where("julianday(created_at) BETWEEN julianday(?) AND julianday(?)", since, until).
+		Group("supplier_id").
+		Scan(&ratingRows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range ratingRows {
+		scorecard := scorecards[row.SupplierID]
+		scorecard.Ratings = row.Total
+		scorecard.AverageTimeliness = roundedAverage(row.Timeliness)
+		scorecard.AverageQuality = roundedAverage(row.Quality)
+		scorecard.AverageCompliance = roundedAverage(row.Compliance)
+		if row.Timeliness != nil && row.Quality != nil && row.Compliance != nil {
+			overall := (*row.Timeliness + *row.Quality + *row.Compliance) / 3
+			scorecard.AverageRating = roundedAverage(&overall)
+		}
+	}
+
+	return scorecards, nil
+}
+
+// RateDelivery lets the receiver rate the supplier on timeliness, quality and compliance for one
+// goods received note. Each note can be rated once.
+// POST /api/purchase-orders/{id}/deliveries/{deliveryId}/rating
+func (h *PurchaseOrderHandler) RateDelivery(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "requester") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and requesters can rate deliveries.")
+		return
+	}
+	order, ok := h.loadReceivablePurchaseOrder(w, r, currentUser)
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseIDParam(w, r, "deliveryId")
+	if !ok {
+		return
+	}
+	var delivery models.Delivery
+	if err := h.DB.Where("id = ? AND purchase_order_id = ?", deliveryID, order.ID).First(&delivery).Error; err != nil {
+		respondWithLookupError(w, err, "Delivery")
+		return
+	}
+
+	var input SupplierRatingInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	for _, score := range []int{input.Timeliness, input.Quality, input.Compliance} {
+		if score < 1 || score > 5 {
+			RespondWithError(w, http.StatusBadRequest, "timeliness, quality and compliance must each be between 1 and 5.")
+			return
+		}
+	}
+
+	var existing models.SupplierRating
+	err := h.DB.Where("delivery_id = ?", delivery.ID).First(&existing).Error
+	if err == nil {
+		RespondWithError(w, http.StatusConflict, "This delivery has already been rated.")
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to check existing ratings: "+err.Error())
+		return
+	}
+
+	rating := models.SupplierRating{
+		SupplierID:      order.SupplierID,
+		PurchaseOrderID: order.ID,
+		DeliveryID:      delivery.ID,
+		RatedByID:       currentUser.ID,
+		Timeliness:      input.Timeliness,
+		Quality:         input.Quality,
+		Compliance:      input.Compliance,
+		Comment:         trimmedOrNil(input.Comment),
+	}
+	if err := h.DB.Create(&rating).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to save rating: "+err.Error())
+		return
+	}
+	log.Printf("RateDelivery: %s (DeliveryID %d) rated for SupplierID %d by UserID %d", delivery.GRNNumber, delivery.ID, order.SupplierID, currentUser.ID)
+
+	RespondWithJSON(w, http.StatusCreated, rating)
+}
+
+// GetSupplierScorecard returns a supplier's rolling performance scorecard over the last
+// ?months= months (default 12, at most 60).
+// GET /api/suppliers/{id}/scorecard
+func (h *SupplierHandler) GetSupplierScorecard(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	supplierID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer", "evaluator", "approver", "admin") && !actsForSupplier(currentUser, supplierID) {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: You cannot view this supplier's scorecard.")
+		return
+	}
+	var supplier models.Supplier
+	if err := h.DB.First(&supplier, supplierID).Error; err != nil {
+		respondWithLookupError(w, err, "Supplier")
+		return
+	}
+
+	months := defaultScorecardMonths
+	if value := r.URL.Query().Get("months"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 60 {
+			RespondWithError(w, http.StatusBadRequest, "months must be a whole number between 1 and 60.")
+			return
+		}
+		months = parsed
+	}
+
+	now := time.Now().UTC()
+	scorecards, err := supplierScorecards(h.DB, []int64{supplier.ID}, now.AddDate(0, -months, 0), now)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to compute scorecard: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, scorecards[supplier.ID])
+}
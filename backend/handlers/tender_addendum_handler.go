@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"procurement/models"
+)
+
+// errTenderNotAmendable is returned from the addendum transaction when the tender closed or changed status underneath it.
+var errTenderNotAmendable = errors.New("tender can no longer be amended")
+
+// AddendumInput is the request body for issuing an addendum. Fields left out stay unchanged;
+// an addendum with no field changes publishes its summary as a notice to suppliers.
+type AddendumInput struct {
+	Summary               string     `json:"summary"`
+	Title                 *string    `json:"title,omitempty"`
+	Description           *string    `json:"description,omitempty"`
+	Category              *string    `json:"category,omitempty"`
+	Budget                *float64   `json:"budget,omitempty"`
+	EvaluationMethod      *string    `json:"evaluation_method,omitempty"`
+	ClosingDate           *time.Time `json:"closing_date,omitempty"`           // May only extend the closing date
+	BidOpeningDate        *time.Time `json:"bid_opening_date,omitempty"`       // Cannot be before the closing date
+	ClarificationDeadline *time.Time `json:"clarification_deadline,omitempty"` // Cannot be after the closing date
+}
+
+// addendumText returns the recorded form of a text field, nil when empty.
+func addendumText(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return value
+}
+
+// addendumAmount returns the recorded form of an amount field.
+func addendumAmount(value *float64) *string {
+	if value == nil {
+		return nil
+	}
+	text := strconv.FormatFloat(*value, 'f', -1, 64)
+	return &text
+}
+
+// addendumTime returns the recorded form of a date field.
+func addendumTime(value *time.Time) *string {
+	if value == nil {
+		return nil
+	}
+	text := value.UTC().Format(time.RFC3339)
+	return &text
+}
+
+// sameAddendumValue reports whether two recorded values are equal.
+func sameAddendumValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// diffTender compares an addendum with the tender it amends. It returns the changed fields and
+// the column updates that apply them.
+func diffTender(tender *models.Tender, input *AddendumInput) ([]models.TenderAddendumChange, map[string]interface{}) {
+	changes := []models.TenderAddendumChange{}
+	updates := map[string]interface{}{}
+	fields := []struct {
+		field    string
+		given    bool
+		oldValue *string
+		newValue *string
+		value    interface{}
+	}{
+		{"title", input.Title != nil, addendumText(&tender.Title), addendumText(input.Title), input.Title},
+		{"description", input.Description != nil, addendumText(tender.Description), addendumText(input.Description), input.Description},
+		{"category", input.Category != nil, addendumText(tender.Category), addendumText(input.Category), input.Category},
+		{"budget", input.Budget != nil, addendumAmount(tender.Budget), addendumAmount(input.Budget), input.Budget},
+		{"evaluation_method", input.EvaluationMethod != nil, addendumText(tender.EvaluationMethod), addendumText(input.EvaluationMethod), input.EvaluationMethod},
+		{"closing_date", input.ClosingDate != nil, addendumTime(tender.ClosingDate), addendumTime(input.ClosingDate), input.ClosingDate},
+		{"bid_opening_date", input.BidOpeningDate != nil, addendumTime(tender.BidOpeningDate), addendumTime(input.BidOpeningDate), input.BidOpeningDate},
+		{"clarification_deadline", input.ClarificationDeadline != nil, addendumTime(tender.ClarificationDeadline), addendumTime(input.ClarificationDeadline), input.ClarificationDeadline},
+	}
+	for _, f := range fields {
+		if !f.given || sameAddendumValue(f.oldValue, f.newValue) {
+			continue
+		}
+		changes = append(changes, models.TenderAddendumChange{Field: f.field, OldValue: f.oldValue, NewValue: f.newValue})
+		updates[f.field] = f.value
+	}
+	return changes, updates
+}
+
+// validateAddendum checks an addendum against the tender it amends.
+func validateAddendum(tender *models.Tender, input *AddendumInput) error {
+	if strings.TrimSpace(input.Summary) == "" {
+		return fmt.Errorf("a summary of the addendum is required")
+	}
+	if input.Title != nil && strings.TrimSpace(*input.Title) == "" {
+		return fmt.Errorf("title cannot be empty")
+	}
+	if input.Budget != nil && *input.Budget < 0 {
+		return fmt.Errorf("budget cannot be negative")
+	}
+	closingDate := tender.ClosingDate
+	if input.ClosingDate != nil {
+		if closingDate != nil && input.ClosingDate.Before(*closingDate) {
+			return fmt.Errorf("an addendum can only extend the closing date")
+		}
+		closingDate = input.ClosingDate
+	}
+	bidOpeningDate := tender.BidOpeningDate
+	if input.BidOpeningDate != nil {
+		bidOpeningDate = input.BidOpeningDate
+	}
+	clarificationDeadline := tender.ClarificationDeadline
+	if input.ClarificationDeadline != nil {
+		clarificationDeadline = input.ClarificationDeadline
+	}
+	return validateTenderDates(closingDate, bidOpeningDate, clarificationDeadline)
+}
+
+// latestAddendum returns the most recent addendum to a tender, or nil if it has none.
+func latestAddendum(db *gorm.DB, tenderID int64) (*models.TenderAddendum, error) {
+	var addendum models.TenderAddendum
+	err := db.Where("tender_id = ?", tenderID).Order("number DESC").First(&addendum).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &addendum, nil
+}
+
+// unacknowledgedAddendum returns the tender's latest addendum if the supplier has not acknowledged it, or nil.
+func unacknowledgedAddendum(db *gorm.DB, tenderID, supplierID int64) (*models.TenderAddendum, error) {
+	addendum, err := latestAddendum(db, tenderID)
+	if err != nil || addendum == nil {
+		return nil, err
+	}
+	var count int64
+	if err := db.Model(&models.TenderAddendumAcknowledgement{}).
+		Where("addendum_id = ? AND supplier_id = ?", addendum.ID, supplierID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, nil
+	}
+	return addendum, nil
+}
+
+// participatesInTender reports whether a supplier registered interest in or bid on a tender.
+func participatesInTender(db *gorm.DB, tenderID, supplierID int64) (bool, error) {
+	var count int64
+	if err := db.Model(&models.TenderInterest{}).Where("tender_id = ? AND supplier_id = ?", tenderID, supplierID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count == 0 {
+		if err := db.Model(&models.Bid{}).Where("tender_id = ? AND supplier_id = ?", tenderID, supplierID).Count(&count).Error; err != nil {
+			return false, err
+		}
+	}
+	return count > 0, nil
+}
+
+// participatingSupplierEmails returns the email addresses of the suppliers that registered
+// interest in or bid on a tender: their supplier users and the organisations' own addresses.
+func participatingSupplierEmails(db *gorm.DB, tenderID int64) ([]string, error) {
+	supplierIDs := db.Model(&models.TenderInterest{}).Select("supplier_id").Where("tender_id = ?", tenderID)
+	bidderIDs := db.Model(&models.Bid{}).Select("supplier_id").Where("tender_id = ?", tenderID)
+
+	var userEmails, supplierEmails []string
+	if err := db.Model(&models.User{}).
+		Where("role = ? AND (supplier_id IN (?) OR supplier_id IN (?))", "supplier", supplierIDs, bidderIDs).
+		Pluck("email", &userEmails).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&models.Supplier{}).
+		Where("email IS NOT NULL AND (id IN (?) OR id IN (?))", supplierIDs, bidderIDs).
+		Pluck("email", &supplierEmails).Error; err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var recipients []string
+	for _, email := range append(userEmails, supplierEmails...) {
+		key := strings.ToLower(strings.TrimSpace(email))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		recipients = append(recipients, email)
+	}
+	return recipients, nil
+}
+
+// loadPublishedTender fetches the tender from the {id} URL parameter and makes sure it is published.
+// It writes the error response itself and returns nil on failure.
+func loadPublishedTender(db *gorm.DB, w http.ResponseWriter, r *http.Request, action string) *models.Tender {
+	tenderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return nil
+	}
+	var tender models.Tender
+	if err := db.First(&tender, tenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return nil
+	}
+	if status := tenderStatusOf(&tender); status != models.TenderStatusPublished {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Only a published tender can %s (current status: %s).", action, status))
+		return nil
+	}
+	return &tender
+}
+
+// CreateAddendum issues a numbered addendum to a published tender before its closing date. The
+// changed fields are applied to the tender and recorded on the addendum, and the suppliers that
+// registered interest or bid are notified. They must acknowledge it before they can bid.
+// POST /api/tenders/{id}/addenda
+func (h *TenderHandler) CreateAddendum(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can issue addenda.")
+		return
+	}
+	tender := loadPublishedTender(h.DB, w, r, "be amended")
+	if tender == nil {
+		return
+	}
+	if tender.ClosingDate != nil && !tender.ClosingDate.After(time.Now()) {
+		RespondWithError(w, http.StatusConflict, "A tender cannot be amended after its closing date.")
+		return
+	}
+
+	var input AddendumInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	for _, date := range []**time.Time{&input.ClosingDate, &input.BidOpeningDate, &input.ClarificationDeadline} {
+		if *date != nil {
+			utc := (*date).UTC()
+			*date = &utc
+		}
+	}
+	if err := validateAddendum(tender, &input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid addendum: "+err.Error())
+		return
+	}
+	changes, updates := diffTender(tender, &input)
+
+	addendum := models.TenderAddendum{
+		TenderID:   tender.ID,
+		Summary:    strings.TrimSpace(input.Summary),
+		Changes:    changes,
+		IssuedByID: currentUser.ID,
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var lastNumber int
+		if err := tx.Model(&models.TenderAddendum{}).Where("tender_id = ?", tender.ID).
+			Select("COALESCE(MAX(number), 0)").Scan(&lastNumber).Error; err != nil {
+			return err
+		}
+		addendum.Number = lastNumber + 1
+		if err := tx.Create(&addendum).Error; err != nil {
+			return err
+		}
+
+		// The tender must still be open; the update bumps updated_at even for a notice
+		now := time.Now().UTC()
+		updates["updated_at"] = now
+		result := tx.Model(&models.Tender{}).Where("id = ? AND status = ? AND (closing_date IS NULL OR closing_date > ?)", tender.ID, models.TenderStatusPublished, now).
+			UpdateColumns(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errTenderNotAmendable
+		}
+		// Move the scheduled closing and bid opening to the amended dates
+		var amended models.Tender
+		if err := tx.First(&amended, tender.ID).Error; err != nil {
+			return err
+		}
+		return scheduleTenderJobs(tx, &amended, true)
+	})
+	if err != nil {
+		if errors.Is(err, errTenderNotAmendable) {
+			RespondWithError(w, http.StatusConflict, "The tender closed or changed status while the addendum was being issued.")
+		} else if strings.Contains(strings.ToLower(err.Error()), "unique") {
+			RespondWithError(w, http.StatusConflict, "Another addendum was issued at the same time; try again.")
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to issue addendum: "+err.Error())
+		}
+		return
+	}
+	log.Printf("CreateAddendum: Addendum %d (ID %d) with %d changes issued for TenderID %d by UserID %d", addendum.Number, addendum.ID, len(changes), tender.ID, currentUser.ID)
+
+	// Notification failures are logged; the addendum stands regardless
+	recipients, err := participatingSupplierEmails(h.DB, tender.ID)
+	if err != nil {
+		log.Printf("CreateAddendum: failed to find suppliers to notify about addendum ID %d: %v", addendum.ID, err)
+	}
+	title := tender.Title
+	if input.Title != nil {
+		title = *input.Title
+	}
+	for _, recipient := range recipients {
+		if err := h.Email.SendTenderAddendumEmail(recipient, title, addendum.Number, addendum.Summary); err != nil {
+			log.Printf("CreateAddendum: failed to notify %s about addendum ID %d: %v", recipient, addendum.ID, err)
+		}
+	}
+
+	RespondWithJSON(w, http.StatusCreated, addendum)
+}
+
+// ListAddenda returns the addenda of a tender in order. Supplier users also see whether their
+// organisation has acknowledged each one.
+// GET /api/tenders/{id}/addenda
+func (h *TenderHandler) ListAddenda(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	tenderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	var tender models.Tender
+	if err := h.DB.First(&tender, tenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return
+	}
+
+	var addenda []models.TenderAddendum
+	if err := h.DB.Where("tender_id = ?", tender.ID).Order("number ASC").Find(&addenda).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve addenda: "+err.Error())
+		return
+	}
+
+	if hasRole(currentUser, "supplier") && currentUser.SupplierID != nil && len(addenda) > 0 {
+		var acknowledgedIDs []int64
+		if err := h.DB.Model(&models.TenderAddendumAcknowledgement{}).
+			Where("supplier_id = ? AND addendum_id IN (?)", *currentUser.SupplierID,
+				h.DB.Model(&models.TenderAddendum{}).Select("id").Where("tender_id = ?", tender.ID)).
+			Pluck("addendum_id", &acknowledgedIDs).Error; err != nil {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve acknowledgements: "+err.Error())
+			return
+		}
+		acknowledged := make(map[int64]bool, len(acknowledgedIDs))
+		for _, id := range acknowledgedIDs {
+			acknowledged[id] = true
+		}
+		for i := range addenda {
+			value := acknowledged[addenda[i].ID]
+			addenda[i].Acknowledged = &value
+		}
+	}
+
+	RespondWithJSON(w, http.StatusOK, addenda)
+}
+
+// AcknowledgeAddendum records the supplier's organisation acknowledging an addendum.
+// Acknowledging again is harmless and returns the original acknowledgement.
+// POST /api/tenders/{id}/addenda/{number}/acknowledge
+func (h *TenderHandler) AcknowledgeAddendum(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "supplier") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only suppliers can acknowledge addenda.")
+		return
+	}
+	supplierID, ok := requireSupplierOrganisation(w, currentUser)
+	if !ok {
+		return
+	}
+	tender := loadPublishedTender(h.DB, w, r, "have its addenda acknowledged")
+	if tender == nil {
+		return
+	}
+	number, ok := parseIDParam(w, r, "number")
+	if !ok {
+		return
+	}
+
+	var addendum models.TenderAddendum
+	if err := h.DB.Where("tender_id = ? AND number = ?", tender.ID, number).First(&addendum).Error; err != nil {
+		respondWithLookupError(w, err, "Addendum")
+		return
+	}
+
+	acknowledgement := models.TenderAddendumAcknowledgement{
+		AddendumID:       addendum.ID,
+		SupplierID:       supplierID,
+		AcknowledgedByID: currentUser.ID,
+	}
+	if err := h.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&acknowledgement).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to record acknowledgement: "+err.Error())
+		return
+	}
+	if err := h.DB.Where("addendum_id = ? AND supplier_id = ?", addendum.ID, supplierID).First(&acknowledgement).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve acknowledgement: "+err.Error())
+		return
+	}
+	log.Printf("AcknowledgeAddendum: SupplierID %d acknowledged addendum %d of TenderID %d (UserID %d)", supplierID, addendum.Number, tender.ID, currentUser.ID)
+
+	RespondWithJSON(w, http.StatusOK, acknowledgement)
+}
+
+// RegisterTenderInterest records the supplier's organisation as interested in a published tender,
+// so that it is notified of addenda and clarifications before it bids.
+// POST /api/tenders/{id}/interest
+func (h *TenderHandler) RegisterTenderInterest(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "supplier") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only suppliers can register interest in tenders.")
+		return
+	}
+	supplierID, ok := requireSupplierOrganisation(w, currentUser)
+	if !ok {
+		return
+	}
+	tender := loadPublishedTender(h.DB, w, r, "take registrations of interest")
+	if tender == nil {
+		return
+	}
+
+	interest := models.TenderInterest{TenderID: tender.ID, SupplierID: supplierID, RegisteredByID: currentUser.ID}
+	if err := h.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&interest).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to register interest: "+err.Error())
+		return
+	}
+	if err := h.DB.Where("tender_id = ? AND supplier_id = ?", tender.ID, supplierID).First(&interest).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve interest: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, interest)
+}
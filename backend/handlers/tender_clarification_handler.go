@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"procurement/models"
+)
+
+// maxClarificationLength caps the length of clarification questions and answers.
+const maxClarificationLength = 4000
+
+// ClarificationInput is the request body for asking a clarification question.
+type ClarificationInput struct {
+	Question string `json:"question"`
+}
+
+// ClarificationAnswerInput is the request body for answering a clarification question.
+type ClarificationAnswerInput struct {
+	Answer string `json:"answer"`
+}
+
+// clarificationCutoff is the last moment suppliers may ask about a tender: its clarification
+// deadline, or its closing date when no separate deadline is set.
+func clarificationCutoff(tender *models.Tender) *time.Time {
+	if tender.ClarificationDeadline != nil {
+		return tender.ClarificationDeadline
+	}
+	return tender.ClosingDate
+}
+
+// anonymiseClarification removes who asked and answered a question before it is shown to a supplier.
+func anonymiseClarification(clarification *models.TenderClarification, supplierID int64) {
+	own := clarification.AskedBySupplierID != nil && *clarification.AskedBySupplierID == supplierID
+	clarification.Own = &own
+	clarification.AskedBySupplierID = nil
+	clarification.AskedByID = nil
+	clarification.AnsweredByID = nil
+}
+
+// ListClarifications returns the clarifications of a tender. Procurement officers and admins see
+// every question with who asked it. Suppliers that registered interest in or bid on the tender see
+// the answered questions and their own organisation's open ones, without anyone's identity.
+// GET /api/tenders/{id}/clarifications
+func (h *TenderHandler) ListClarifications(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	tenderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+	var tender models.Tender
+	if err := h.DB.First(&tender, tenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return
+	}
+
+	query := h.DB.Where("tender_id = ?", tender.ID)
+	var supplierID int64
+	isSupplier := false
+	switch {
+	case hasRole(currentUser, "procurement_officer", "admin"):
+	case hasRole(currentUser, "supplier") && currentUser.SupplierID != nil:
+		supplierID = *currentUser.SupplierID
+		participates, err := participatesInTender(h.DB, tender.ID, supplierID)
+		if err != nil {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to check tender participation: "+err.Error())
+			return
+		}
+		if !participates {
+			RespondWithError(w, http.StatusForbidden, "Forbidden: Register interest in this tender to follow its clarifications.")
+			return
+		}
+		isSupplier = true
+		query = query.Where("answered_at IS NOT NULL OR asked_by_supplier_id = ?", supplierID)
+	default:
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers and participating suppliers can view tender clarifications.")
+		return
+	}
+
+	var clarifications []models.TenderClarification
+	if err := query.Order("asked_at ASC, id ASC").Find(&clarifications).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve clarifications: "+err.Error())
+		return
+	}
+	if isSupplier {
+		for i := range clarifications {
+			anonymiseClarification(&clarifications[i], supplierID)
+		}
+	}
+
+	RespondWithJSON(w, http.StatusOK, clarifications)
+}
+
+// AskClarification records a supplier's clarification question about a published tender, up to
+// its clarification cutoff. Asking registers the organisation's interest in the tender.
+// POST /api/tenders/{id}/clarifications
+func (h *TenderHandler) AskClarification(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "supplier") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only suppliers can ask clarification questions.")
+		return
+	}
+	supplierID, ok := requireSupplierOrganisation(w, currentUser)
+	if !ok {
+		return
+	}
+	tender := loadPublishedTender(h.DB, w, r, "take clarification questions")
+	if tender == nil {
+		return
+	}
+	if cutoff := clarificationCutoff(tender); cutoff != nil && !cutoff.After(time.Now()) {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Clarification questions for this tender closed at %s.", cutoff.UTC().Format(time.RFC3339)))
+		return
+	}
+
+	var input ClarificationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	question := strings.TrimSpace(input.Question)
+	if question == "" {
+		RespondWithError(w, http.StatusBadRequest, "A question is required.")
+		return
+	}
+	if len(question) > maxClarificationLength {
+		RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Questions are limited to %d characters.", maxClarificationLength))
+		return
+	}
+
+	clarification := models.TenderClarification{
+		TenderID:          tender.ID,
+		Question:          question,
+		AskedBySupplierID: &supplierID,
+		AskedByID:         &currentUser.ID,
+	}
+	if err := h.DB.Create(&clarification).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to record question: "+err.Error())
+		return
+	}
+	interest := models.TenderInterest{TenderID: tender.ID, SupplierID: supplierID, RegisteredByID: currentUser.ID}
+	if err := h.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&interest).Error; err != nil {
+		log.Printf("AskClarification: failed to register interest of SupplierID %d in TenderID %d: %v", supplierID, tender.ID, err)
+	}
+	log.Printf("AskClarification: ClarificationID %d asked on TenderID %d by SupplierID %d (UserID %d)", clarification.ID, tender.ID, supplierID, currentUser.ID)
+
+	anonymiseClarification(&clarification, supplierID)
+	RespondWithJSON(w, http.StatusCreated, clarification)
+}
+
+// AnswerClarification answers a clarification question and publishes it, anonymised, to every
+// participating supplier, who are also notified by email. A published answer is final; a
+// correction is made by issuing an addendum.
+// POST /api/tenders/{id}/clarifications/{clarificationId}/answer
+func (h *TenderHandler) AnswerClarification(w http.ResponseWriter, r *http.Request) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can answer clarification questions.")
+		return
+	}
+	tender := loadPublishedTender(h.DB, w, r, "have its clarifications answered")
+	if tender == nil {
+		return
+	}
+	clarificationID, ok := parseIDParam(w, r, "clarificationId")
+	if !ok {
+		return
+	}
+	var clarification models.TenderClarification
+	if err := h.DB.Where("id = ? AND tender_id = ?", clarificationID, tender.ID).First(&clarification).Error; err != nil {
+		respondWithLookupError(w, err, "Clarification")
+		return
+	}
+
+	var input ClarificationAnswerInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	answer := strings.TrimSpace(input.Answer)
+	if answer == "" {
+		RespondWithError(w, http.StatusBadRequest, "An answer is required.")
+		return
+	}
+	if len(answer) > maxClarificationLength {
+		RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Answers are limited to %d characters.", maxClarificationLength))
+		return
+	}
+
+	now := time.Now().UTC()
+	result := h.DB.Model(&models.TenderClarification{}).Where("id = ? AND answered_at IS NULL", clarification.ID).
+		Updates(map[string]interface{}{"answer": answer, "answered_by_id": currentUser.ID, "answered_at": now})
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to record answer: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusConflict, "This question has already been answered.")
+		return
+	}
+	clarification.Answer = &answer
+	clarification.AnsweredByID = &currentUser.ID
+	clarification.AnsweredAt = &now
+	log.Printf("AnswerClarification: ClarificationID %d on TenderID %d answered by UserID %d", clarification.ID, tender.ID, currentUser.ID)
+
+	// Notification failures are logged; the answer is published regardless
+	recipients, err := participatingSupplierEmails(h.DB, tender.ID)
+	if err != nil {
+		log.Printf("AnswerClarification: failed to find suppliers to notify about ClarificationID %d: %v", clarification.ID, err)
+	}
+	for _, recipient := range recipients {
+		if err := h.Email.SendTenderClarificationEmail(recipient, tender.Title, clarification.Question, answer); err != nil {
+			log.Printf("AnswerClarification: failed to notify %s about ClarificationID %d: %v", recipient, clarification.ID, err)
+		}
+	}
+
+	RespondWithJSON(w, http.StatusOK, clarification)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -11,16 +12,42 @@ import (
 	"gorm.io/gorm"
 
 	"procurement/models"
+	"procurement/services"
 )
 
 // TenderHandler holds dependencies for tender related handlers.
 type TenderHandler struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	Email services.EmailService // Tells suppliers about addenda
 }
 
-// NewTenderHandler creates a new TenderHandler with the given DB connection.
-func NewTenderHandler(db *gorm.DB) *TenderHandler {
-	return &TenderHandler{DB: db}
+// NewTenderHandler creates a new TenderHandler with the given DB connection and email service.
+func NewTenderHandler(db *gorm.DB, email services.EmailService) *TenderHandler {
+	return &TenderHandler{DB: db, Email: email}
+}
+
+// validateTenderDates checks that a tender's dates are in order: clarification questions stop no
+// later than bids do, and sealed bids are opened no earlier than bids stop arriving.
+func validateTenderDates(closingDate, bidOpeningDate, clarificationDeadline *time.Time) error {
+	if closingDate != nil && bidOpeningDate != nil && bidOpeningDate.Before(*closingDate) {
+		return fmt.Errorf("bid opening date cannot be before the closing date")
+	}
+	if closingDate != nil && clarificationDeadline != nil && clarificationDeadline.After(*closingDate) {
+		return fmt.Errorf("clarification deadline cannot be after the closing date")
+	}
+	return nil
+}
+
+// clearTenderLifecycle drops the status and its timestamps from client input; only the
+// transition endpoints set them.
+func clearTenderLifecycle(tender *models.Tender) {
+	tender.Status = nil
+	tender.PublishedDate = nil
+	tender.ClosedAt = nil
+	tender.EvaluationStartedAt = nil
+	tender.AwardedAt = nil
+	tender.CancelledAt = nil
+	tender.CancellationReason = nil
 }
 
 // CreateTender handles the creation of a new tender.
@@ -36,8 +63,21 @@ func (h *TenderHandler) CreateTender(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Every tender starts as a draft; it moves on only through its transition endpoints
+	if tenderInput.Status != nil && tenderStatusOf(&tenderInput) != models.TenderStatusDraft {
+		RespondWithError(w, http.StatusBadRequest, "Tenders are created as drafts; publish them with POST /api/tenders/{id}/publish.")
+		return
+	}
+	clearTenderLifecycle(&tenderInput)
+	draft := models.TenderStatusDraft
+	tenderInput.Status = &draft
+
 	// TODO: Add validation logic here if needed
 	// E.g., ensure required fields like Title, ClosingDate are present
+	if err := validateTenderDates(tenderInput.ClosingDate, tenderInput.BidOpeningDate, tenderInput.ClarificationDeadline); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid tender dates: "+err.Error())
+		return
+	}
 
 	// Set CreatedByUserID from the authenticated user's ID in the request context
 	userIDFromContext := r.Context().Value("userID")
@@ -60,6 +100,34 @@ func (h *TenderHandler) CreateTender(w http.ResponseWriter, r *http.Request) {
 	// CreatedByUserID is already *int64, so assign the address of the int64 from context
 	tenderInput.CreatedByUserID = &userIDInt64FromCtx
 
+	// Validate supplied line items, or seed them from the linked requisition
+	if len(tenderInput.Items) > 0 {
+		for i := range tenderInput.Items {
+			tenderInput.Items[i].ID = 0
+			if err := validateTenderItem(&tenderInput.Items[i]); err != nil {
+				RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid tender item %d: %s", i+1, err.Error()))
+				return
+			}
+		}
+	} else if tenderInput.RequisitionID != nil {
+		var reqItems []models.RequisitionItem
+		if err := h.DB.Where("requisition_id = ?", *tenderInput.RequisitionID).Order("id ASC").Find(&reqItems).Error; err != nil {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve requisition items: "+err.Error())
+			return
+		}
+		tenderInput.Items = tenderItemsFromRequisition(reqItems)
+	}
+
+	// Validate any evaluation criteria supplied with the tender. Their weights are only
+	// checked for completeness when the tender is published.
+	for i := range tenderInput.EvaluationCriteria {
+		tenderInput.EvaluationCriteria[i].ID = 0
+		if err := validateCriterion(&tenderInput.EvaluationCriteria[i]); err != nil {
+			RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid evaluation criterion %d: %s", i+1, err.Error()))
+			return
+		}
+	}
+
 	// Save the tender to the database
 	if err := h.DB.Create(&tenderInput).Error; err != nil {
 		w.Header().Set("Content-Type", "application/json")
@@ -95,7 +163,7 @@ func (h *TenderHandler) GetTenders(w http.ResponseWriter, r *http.Request) {
 
 	if strings.EqualFold(user.Role, "supplier") {
 		log.Println("GetTenders: Applying supplier-specific filters")
-		query = query.Where("status IN (?, ?)", "published", "open").Where("closing_date > ?", time.Now())
+		query = query.Where("status = ?", models.TenderStatusPublished).Where("closing_date > ?", time.Now())
 
 		// Filtering by category for suppliers
 		category := r.URL.Query().Get("category")
@@ -135,7 +203,7 @@ func (h *TenderHandler) GetTenderByID(w http.ResponseWriter, r *http.Request) {
 
 	// Preload Requisition and its Items. 
 	// The Tender model must have a 'Requisition' field, and the Requisition model an 'Items' field.
-	if err := h.DB.Preload("Requisition").Preload("Requisition.Items").First(&tender, tenderID).Error; err != nil {
+	if err := h.DB.Preload("Requisition").Preload("Requisition.Items").Preload("Items").Preload("EvaluationCriteria").First(&tender, tenderID).Error; err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		if err == gorm.ErrRecordNotFound {
 			w.WriteHeader(http.StatusNotFound)
@@ -146,10 +214,19 @@ func (h *TenderHandler) GetTenderByID(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	// Draft tenders, with their items and criteria, stay hidden from everyone but officers
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return
+	}
+	if !tenderVisibleTo(currentUser, &tender) {
+		respondWithLookupError(w, gorm.ErrRecordNotFound, "Tender")
+		return
+	}
 
-	// Count the number of bids for this tender
+	// Count the number of active bids for this tender
 	var bidCount int64
-	h.DB.Model(&models.Bid{}).Where("tender_id = ?", tender.ID).Count(&bidCount)
+	h.DB.Model(&models.Bid{}).Where("tender_id = ? AND status NOT IN ?", tender.ID, inactiveBidStatuses).Count(&bidCount)
 	tender.BiddersInvitedCount = int(bidCount)
 
 	w.Header().Set("Content-Type", "application/json")
@@ -157,10 +234,14 @@ func (h *TenderHandler) GetTenderByID(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(tender)
 }
 
-// UpdateTender handles updating an existing tender by its ID.
+// UpdateTender handles updating a draft tender by its ID. Once a tender is published it can only
+// be changed by issuing an addendum (see CreateAddendum).
 // PUT /api/tenders/:id
 func (h *TenderHandler) UpdateTender(w http.ResponseWriter, r *http.Request) {
-	tenderID := chi.URLParam(r, "id")
+	existingTender := loadDraftTender(h.DB, w, r, "details")
+	if existingTender == nil {
+		return
+	}
 	var tenderInput models.Tender
 
 	// Decode the JSON request body
@@ -171,25 +252,36 @@ func (h *TenderHandler) UpdateTender(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var existingTender models.Tender
-	// Check if the tender exists
-	if err := h.DB.First(&existingTender, tenderID).Error; err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		if err == gorm.ErrRecordNotFound {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Tender not found"})
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to retrieve tender: " + err.Error()})
-		}
+	// Assign the ID from the path to ensure we're updating the correct record.
+	// Items, criteria and bids have their own endpoints and are never written from here.
+	tenderInput.ID = existingTender.ID
+	tenderInput.Items = nil
+	tenderInput.EvaluationCriteria = nil
+	tenderInput.Bids = nil
+	if tenderInput.Status != nil && tenderStatusOf(&tenderInput) != models.TenderStatusDraft {
+		RespondWithError(w, http.StatusBadRequest, "The status of a tender is changed through its transition endpoints, such as POST /api/tenders/{id}/publish.")
+		return
+	}
+	clearTenderLifecycle(&tenderInput)
+
+	closingDate, bidOpeningDate, clarificationDeadline := existingTender.ClosingDate, existingTender.BidOpeningDate, existingTender.ClarificationDeadline
+	if tenderInput.ClosingDate != nil {
+		closingDate = tenderInput.ClosingDate
+	}
+	if tenderInput.BidOpeningDate != nil {
+		bidOpeningDate = tenderInput.BidOpeningDate
+	}
+	if tenderInput.ClarificationDeadline != nil {
+		clarificationDeadline = tenderInput.ClarificationDeadline
+	}
+	if err := validateTenderDates(closingDate, bidOpeningDate, clarificationDeadline); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid tender dates: "+err.Error())
 		return
 	}
 
-	// Assign the ID from the path to ensure we're updating the correct record.
-	tenderInput.ID = existingTender.ID
 
 	// Update the tender record in the database
-	if err := h.DB.Model(&existingTender).Updates(tenderInput).Error; err != nil {
+	if err := h.DB.Model(existingTender).Updates(tenderInput).Error; err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to update tender: " + err.Error()})
@@ -197,7 +289,7 @@ func (h *TenderHandler) UpdateTender(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch the updated record to return it
-	if err := h.DB.First(&existingTender, tenderID).Error; err != nil {
+	if err := h.DB.First(existingTender, existingTender.ID).Error; err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Failed to retrieve updated tender: " + err.Error()})
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"procurement/models"
+
+	"gorm.io/gorm"
+)
+
+// tenderStatusOf returns the tender's status, treating an unset status as 'draft'.
+func tenderStatusOf(tender *models.Tender) string {
+	if tender.Status == nil || *tender.Status == "" {
+		return "draft"
+	}
+	return strings.ToLower(*tender.Status)
+}
+
+// validateTenderItem checks the fields every tender line item must carry.
+func validateTenderItem(item *models.TenderItem) error {
+	if strings.TrimSpace(item.Description) == "" {
+		return fmt.Errorf("item description is required")
+	}
+	if item.Quantity <= 0 {
+		return fmt.Errorf("item quantity must be greater than zero")
+	}
+	if strings.TrimSpace(item.Unit) == "" {
+		return fmt.Errorf("item unit is required")
+	}
+	return nil
+}
+
+// loadDraftTender fetches the tender from the {id} URL parameter and makes sure the current
+// user may change what it describes (subject, e.g. "tender items"). It writes the error
+// response itself and returns nil on failure.
+func loadDraftTender(db *gorm.DB, w http.ResponseWriter, r *http.Request, subject string) *models.Tender {
+	currentUser, ok := getAuthenticatedUser(db, w, r)
+	if !ok {
+		return nil
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can manage "+subject+".")
+		return nil
+	}
+
+	tenderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return nil
+	}
+
+	var tender models.Tender
+	if err := db.First(&tender, tenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return nil
+	}
+
+	if status := tenderStatusOf(&tender); status != "draft" {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("The %s of a tender can only be changed while it is a draft (current status: %s).", subject, status))
+		return nil
+	}
+	return &tender
+}
+
+// tenderVisibleTo reports whether a user may see a tender. Draft tenders are only visible to
+// procurement officers and admins; to anyone else they do not exist until they are published.
+func tenderVisibleTo(user *models.User, tender *models.Tender) bool {
+	return tenderStatusOf(tender) != models.TenderStatusDraft || hasRole(user, "procurement_officer", "admin")
+}
+
+// loadVisibleTender loads the tender named by the id URL parameter when the current user may see
+// it. It writes the error response and returns nil otherwise, answering 404 for a hidden draft.
+func loadVisibleTender(db *gorm.DB, w http.ResponseWriter, r *http.Request) *models.Tender {
+	currentUser, ok := getAuthenticatedUser(db, w, r)
+	if !ok {
+		return nil
+	}
+	tenderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return nil
+	}
+
+	var tender models.Tender
+	if err := db.First(&tender, tenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return nil
+	}
+	if !tenderVisibleTo(currentUser, &tender) {
+		respondWithLookupError(w, gorm.ErrRecordNotFound, "Tender")
+		return nil
+	}
+	return &tender
+}
+
+// ListTenderItems returns the line items of a tender.
+// GET /api/tenders/{id}/items
+func (h *TenderHandler) ListTenderItems(w http.ResponseWriter, r *http.Request) {
+	tender := loadVisibleTender(h.DB, w, r)
+	if tender == nil {
+		return
+	}
+
+	var items []models.TenderItem
+	if err := h.DB.Where("tender_id = ?", tender.ID).Order("id ASC").Find(&items).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve tender items: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, items)
+}
+
+// CreateTenderItem adds a line item to a draft tender.
+// POST /api/tenders/{id}/items
+func (h *TenderHandler) CreateTenderItem(w http.ResponseWriter, r *http.Request) {
+	tender := loadDraftTender(h.DB, w, r, "tender items")
+	if tender == nil {
+		return
+	}
+
+	var item models.TenderItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if err := validateTenderItem(&item); err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	item.ID = 0
+	item.TenderID = tender.ID
+	if err := h.DB.Create(&item).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to create tender item: "+err.Error())
+		return
+	}
+	log.Printf("CreateTenderItem: Created TenderItemID %d for TenderID %d", item.ID, tender.ID)
+
+	RespondWithJSON(w, http.StatusCreated, item)
+}
+
+// UpdateTenderItem changes a line item of a draft tender.
+// PUT /api/tenders/{id}/items/{itemId}
+func (h *TenderHandler) UpdateTenderItem(w http.ResponseWriter, r *http.Request) {
+	tender := loadDraftTender(h.DB, w, r, "tender items")
+	if tender == nil {
+		return
+	}
+	itemID, ok := parseIDParam(w, r, "itemId")
+	if !ok {
+		return
+	}
+
+	var item models.TenderItem
+	if err := h.DB.Where("id = ? AND tender_id = ?", itemID, tender.ID).First(&item).Error; err != nil {
+		respondWithLookupError(w, err, "Tender item")
+		return
+	}
+
+	var input models.TenderItem
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	if err := validateTenderItem(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	item.RequisitionItemID = input.RequisitionItemID
+	item.Description = input.Description
+	item.Quantity = input.Quantity
+	item.Unit = input.Unit
+	item.EstimatedUnitPrice = input.EstimatedUnitPrice
+	item.SpecificationText = input.SpecificationText
+	if err := h.DB.Save(&item).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update tender item: "+err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, item)
+}
+
+// DeleteTenderItem removes a line item from a draft tender.
+// DELETE /api/tenders/{id}/items/{itemId}
+func (h *TenderHandler) DeleteTenderItem(w http.ResponseWriter, r *http.Request) {
+	tender := loadDraftTender(h.DB, w, r, "tender items")
+	if tender == nil {
+		return
+	}
+	itemID, ok := parseIDParam(w, r, "itemId")
+	if !ok {
+		return
+	}
+
+	result := h.DB.Where("id = ? AND tender_id = ?", itemID, tender.ID).Delete(&models.TenderItem{})
+	if result.Error != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to delete tender item: "+result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		RespondWithError(w, http.StatusNotFound, "Tender item not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// tenderItemsFromRequisition builds tender line items from the items of a requisition.
+func tenderItemsFromRequisition(reqItems []models.RequisitionItem) []models.TenderItem {
+	items := make([]models.TenderItem, 0, len(reqItems))
+	for _, reqItem := range reqItems {
+		reqItemID := reqItem.ID
+		items = append(items, models.TenderItem{
+			RequisitionItemID:  &reqItemID,
+			Description:        reqItem.Description,
+			Quantity:           reqItem.Quantity,
+			Unit:               reqItem.Unit,
+			EstimatedUnitPrice: reqItem.EstimatedUnitPrice,
+		})
+	}
+	return items
+}
+
+// matchBidItemsToTender links every bid item to a line item of the tender and checks that the
+// quantity and unit match. Bid items may reference the tender item directly or through the
+// requisition item it was seeded from. Each tender item may be priced at most once per bid.
+func matchBidItemsToTender(bidItems []models.BidItem, tenderItems []models.TenderItem) error {
+	if len(tenderItems) == 0 {
+		return fmt.Errorf("tender has no line items to bid against")
+	}
+
+	byID := make(map[int64]*models.TenderItem, len(tenderItems))
+	byReqItemID := make(map[int64]*models.TenderItem, len(tenderItems))
+	for i := range tenderItems {
+		byID[tenderItems[i].ID] = &tenderItems[i]
+		if tenderItems[i].RequisitionItemID != nil {
+			byReqItemID[*tenderItems[i].RequisitionItemID] = &tenderItems[i]
+		}
+	}
+
+	seen := make(map[int64]bool, len(bidItems))
+	for i := range bidItems {
+		var tenderItem *models.TenderItem
+		switch {
+		case bidItems[i].TenderItemID != nil:
+			tenderItem = byID[*bidItems[i].TenderItemID]
+		case bidItems[i].RequisitionItemID != nil:
+			tenderItem = byReqItemID[*bidItems[i].RequisitionItemID]
+		}
+		if tenderItem == nil {
+			return fmt.Errorf("bid item %d does not match any item of this tender", i+1)
+		}
+		if seen[tenderItem.ID] {
+			return fmt.Errorf("bid item %d duplicates tender item %d", i+1, tenderItem.ID)
+		}
+		seen[tenderItem.ID] = true
+
+		if bidItems[i].Quantity != tenderItem.Quantity {
+			return fmt.Errorf("bid item %d quantity %g does not match tender item quantity %g", i+1, bidItems[i].Quantity, tenderItem.Quantity)
+		}
+		if !strings.EqualFold(strings.TrimSpace(bidItems[i].Unit), strings.TrimSpace(tenderItem.Unit)) {
+			return fmt.Errorf("bid item %d unit '%s' does not match tender item unit '%s'", i+1, bidItems[i].Unit, tenderItem.Unit)
+		}
+
+		tenderItemID := tenderItem.ID
+		bidItems[i].TenderItemID = &tenderItemID
+		bidItems[i].RequisitionItemID = tenderItem.RequisitionItemID
+		if strings.TrimSpace(bidItems[i].Description) == "" {
+			bidItems[i].Description = tenderItem.Description
+		}
+	}
+	return nil
+}
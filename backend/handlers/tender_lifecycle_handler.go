@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"procurement/models"
+)
+
+// tenderTransitions lists the statuses a tender may move to from each status. Awarded and
+// cancelled tenders are final.
+var tenderTransitions = map[string][]string{
+	models.TenderStatusDraft:      {models.TenderStatusPublished, models.TenderStatusCancelled},
+	models.TenderStatusPublished:  {models.TenderStatusClosed, models.TenderStatusCancelled},
+	models.TenderStatusClosed:     {models.TenderStatusEvaluation, models.TenderStatusCancelled},
+	models.TenderStatusEvaluation: {models.TenderStatusAwarded, models.TenderStatusCancelled},
+}
+
+// tenderStatusStamps is the timestamp column set when a tender enters each status.
+var tenderStatusStamps = map[string]string{
+	models.TenderStatusPublished:  "published_date",
+	models.TenderStatusClosed:     "closed_at",
+	models.TenderStatusEvaluation: "evaluation_started_at",
+	models.TenderStatusAwarded:    "awarded_at",
+	models.TenderStatusCancelled:  "cancelled_at",
+}
+
+// tenderStatusExpr is the SQL counterpart of tenderStatusOf.
+const tenderStatusExpr = "COALESCE(NULLIF(LOWER(status), ''), 'draft')"
+
+// canTransitionTender reports whether a tender may move from one status to another.
+func canTransitionTender(from, to string) bool {
+	for _, next := range tenderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// tenderOpenForBids reports whether a tender is taking bids: published, with its closing date
+// still to come.
+func tenderOpenForBids(tender *models.Tender) bool {
+	return tenderStatusOf(tender) == models.TenderStatusPublished && tender.ClosingDate != nil && tender.ClosingDate.After(time.Now())
+}
+
+// advanceTenderStatus moves a tender from one status to another, stamping the time it entered
+// the new status along with any extra columns. The update is conditional on the current status;
+// it reports false when the tender was no longer in the from status. Pass a transaction to make
+// the move part of a larger change.
+func advanceTenderStatus(db *gorm.DB, tenderID int64, from, to string, extra map[string]interface{}) (bool, error) {
+	if !canTransitionTender(from, to) {
+		return false, fmt.Errorf("a tender cannot move from '%s' to '%s'", from, to)
+	}
+	updates := map[string]interface{}{"status": to, tenderStatusStamps[to]: time.Now().UTC()}
+	for column, value := range extra {
+		updates[column] = value
+	}
+	result := db.Model(&models.Tender{}).Where("id = ? AND "+tenderStatusExpr+" = ?", tenderID, from).Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// transitionTender moves a tender to the given status on behalf of a user and responds with the
+// updated tender. It writes the response itself and reports whether the tender moved.
+func (h *TenderHandler) transitionTender(w http.ResponseWriter, tender *models.Tender, to string, extra map[string]interface{}, userID int64) bool {
+	from := tenderStatusOf(tender)
+	if !canTransitionTender(from, to) {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Cannot move a tender from '%s' to '%s'.", from, to))
+		return false
+	}
+
+	moved, err := advanceTenderStatus(h.DB, tender.ID, from, to, extra)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update tender status: "+err.Error())
+		return false
+	}
+	if !moved {
+		RespondWithError(w, http.StatusConflict, "Tender status was changed by someone else, please reload.")
+		return false
+	}
+	log.Printf("Tender: TenderID %d moved from %s to %s by UserID %d", tender.ID, from, to, userID)
+
+	if err := h.DB.First(tender, tender.ID).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Tender status updated but could not be reloaded: "+err.Error())
+		return false
+	}
+	RespondWithJSON(w, http.StatusOK, tender)
+	return true
+}
+
+// loadTenderForTransition fetches the tender from the {id} URL parameter for a procurement
+// officer about to change its status (action, e.g. "close tenders"). It writes the error
+// response itself and returns nil on failure.
+func (h *TenderHandler) loadTenderForTransition(w http.ResponseWriter, r *http.Request, action string) (*models.Tender, *models.User) {
+	currentUser, ok := getAuthenticatedUser(h.DB, w, r)
+	if !ok {
+		return nil, nil
+	}
+	if !hasRole(currentUser, "procurement_officer") {
+		RespondWithError(w, http.StatusForbidden, "Forbidden: Only procurement officers can "+action+".")
+		return nil, nil
+	}
+	tenderID, ok := parseIDParam(w, r, "id")
+	if !ok {
+		return nil, nil
+	}
+	var tender models.Tender
+	if err := h.DB.First(&tender, tenderID).Error; err != nil {
+		respondWithLookupError(w, err, "Tender")
+		return nil, nil
+	}
+	return &tender, currentUser
+}
+
+// PublishTender opens a draft tender for bids. It needs line items, complete evaluation criteria
+// and a closing date in the future.
+// POST /api/tenders/{id}/publish
+func (h *TenderHandler) PublishTender(w http.ResponseWriter, r *http.Request) {
+	tender, currentUser := h.loadTenderForTransition(w, r, "publish tenders")
+	if tender == nil {
+		return
+	}
+	if status := tenderStatusOf(tender); status != models.TenderStatusDraft {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Only a draft tender can be published (current status: %s).", status))
+		return
+	}
+
+	if tender.ClosingDate == nil || !tender.ClosingDate.After(time.Now()) {
+		RespondWithError(w, http.StatusBadRequest, "Tender cannot be published: its closing date must be in the future.")
+		return
+	}
+	var itemCount int64
+	if err := h.DB.Model(&models.TenderItem{}).Where("tender_id = ?", tender.ID).Count(&itemCount).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to count tender items: "+err.Error())
+		return
+	}
+	if itemCount == 0 {
+		RespondWithError(w, http.StatusBadRequest, "Tender cannot be published: it has no line items.")
+		return
+	}
+	if err := validateCriteriaForPublish(h.DB, tender.ID); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Tender cannot be published: "+err.Error())
+		return
+	}
+
+	if !h.transitionTender(w, tender, models.TenderStatusPublished, nil, currentUser.ID) {
+		return
+	}
+	// The scheduler closes the tender and starts its bid opening when their dates come
+	if err := scheduleTenderJobs(h.DB, tender, true); err != nil {
+		log.Printf("PublishTender: failed to schedule the deadlines of TenderID %d: %v", tender.ID, err)
+	}
+}
+
+// CloseTender stops a published tender taking bids once its closing date has passed. The
+// scheduler does this at the closing date; this endpoint lets procurement close it by hand. Its
+// bids stay sealed until the panel opens them, which starts the evaluation (see OpenTenderBids).
+// POST /api/tenders/{id}/close
+func (h *TenderHandler) CloseTender(w http.ResponseWriter, r *http.Request) {
+	tender, currentUser := h.loadTenderForTransition(w, r, "close tenders")
+	if tender == nil {
+		return
+	}
+	if tenderStatusOf(tender) == models.TenderStatusPublished && tender.ClosingDate != nil && tender.ClosingDate.After(time.Now()) {
+		RespondWithError(w, http.StatusConflict, fmt.Sprintf("Tender cannot be closed before its closing date at %s.", tender.ClosingDate.UTC().Format(time.RFC3339)))
+		return
+	}
+	h.transitionTender(w, tender, models.TenderStatusClosed, nil, currentUser.ID)
+}
+
+// TenderCancellationInput is the request body for cancelling a tender.
+type TenderCancellationInput struct {
+	Reason string `json:"reason"`
+}
+
+// CancelTender abandons a tender that has not been awarded. A reason is required and recorded.
+// POST /api/tenders/{id}/cancel
+func (h *TenderHandler) CancelTender(w http.ResponseWriter, r *http.Request) {
+	tender, currentUser := h.loadTenderForTransition(w, r, "cancel tenders")
+	if tender == nil {
+		return
+	}
+	var input TenderCancellationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid input: "+err.Error())
+		return
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		RespondWithError(w, http.StatusBadRequest, "A reason for the cancellation is required.")
+		return
+	}
+	h.transitionTender(w, tender, models.TenderStatusCancelled, map[string]interface{}{"cancellation_reason": reason}, currentUser.ID)
+}
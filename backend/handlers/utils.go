@@ -2,10 +2,19 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"procurement/models"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // SanitizeFilename removes or replaces characters that are problematic for filenames.
@@ -55,3 +64,67 @@ func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 		// Logging the error is important.
 	}
 }
+
+// getAuthenticatedUser loads the user identified by the "userID" context value.
+// On failure it writes the error response itself and returns false.
+func getAuthenticatedUser(db *gorm.DB, w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	userID, ok := r.Context().Value("userID").(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found or invalid in context")
+		return nil, false
+	}
+
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve user details: "+err.Error())
+		return nil, false
+	}
+	return &user, true
+}
+
+// hasRole reports whether the user has any of the given roles (case-insensitive).
+func hasRole(user *models.User, roles ...string) bool {
+	for _, role := range roles {
+		if strings.EqualFold(user.Role, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIDParam parses the named chi URL parameter as an int64.
+// On failure it writes a 400 response and returns false.
+func parseIDParam(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid "+name+" format: "+err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+// respondWithLookupError writes a 404 for missing records and a 500 otherwise.
+func respondWithLookupError(w http.ResponseWriter, err error, entity string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		RespondWithError(w, http.StatusNotFound, entity+" not found")
+		return
+	}
+	RespondWithError(w, http.StatusInternalServerError, "Failed to retrieve "+strings.ToLower(entity)+": "+err.Error())
+}
+
+// nextDocumentNumber hands out the next number of a yearly document series, e.g. "PO-2025-00001".
+// Call it inside the transaction that creates the document so a rollback gives the number back.
+func nextDocumentNumber(tx *gorm.DB, prefix string, now time.Time) (string, error) {
+	name := fmt.Sprintf("%s-%d", prefix, now.Year())
+	sequence := models.NumberSequence{Name: name, LastValue: 1}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"last_value": gorm.Expr("last_value + 1")}),
+	}).Create(&sequence).Error; err != nil {
+		return "", err
+	}
+	if err := tx.First(&sequence, "name = ?", name).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%05d", name, sequence.LastValue), nil
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -14,6 +19,7 @@ import (
 	"procurement/handlers"
 	appMiddleware "procurement/middleware"
 	"procurement/models"
+	"procurement/services"
 )
 
 func serveFrontend(r *chi.Mux, staticPath string) {
@@ -33,6 +39,38 @@ func serveFrontend(r *chi.Mux, staticPath string) {
 	})
 }
 
+// invoiceMatchTolerance reads the three-way match tolerance from INVOICE_MATCH_TOLERANCE_PERCENT
+// and returns it as a fraction. It defaults to 2%.
+func invoiceMatchTolerance() float64 {
+	const defaultPercent = 2.0
+	value := os.Getenv("INVOICE_MATCH_TOLERANCE_PERCENT")
+	if value == "" {
+		return defaultPercent / 100
+	}
+	percent, err := strconv.ParseFloat(value, 64)
+	if err != nil || percent < 0 {
+		log.Printf("WARNING: Invalid INVOICE_MATCH_TOLERANCE_PERCENT %q, using %g%%.", value, defaultPercent)
+		return defaultPercent / 100
+	}
+	return percent / 100
+}
+
+// schedulerPollInterval reads how often the scheduler looks for due jobs from
+// SCHEDULER_POLL_SECONDS. It defaults to 30 seconds.
+func schedulerPollInterval() time.Duration {
+	const defaultSeconds = 30
+	value := os.Getenv("SCHEDULER_POLL_SECONDS")
+	if value == "" {
+		return defaultSeconds * time.Second
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		log.Printf("WARNING: Invalid SCHEDULER_POLL_SECONDS %q, using %d.", value, defaultSeconds)
+		return defaultSeconds * time.Second
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, reading from environment")
@@ -43,19 +81,82 @@ func main() {
 	}
 	log.Println("Database connection and initialization successful.")
 	db := database.GetDB()
+	if err := database.RenameFilePathColumns(db); err != nil {
+		log.Fatalf("Failed to rename file path columns: %v", err)
+	}
 	if err := db.AutoMigrate(
 		&models.User{},
+		&models.Supplier{},
+		&models.SupplierCategory{},
+		&models.SupplierDocument{},
+		&models.SupplierDebarment{},
 		&models.Requisition{},
 		&models.RequisitionItem{},
+		&models.ApprovalChain{},
+		&models.ApprovalChainLevel{},
+		&models.ApprovalStep{},
 		&models.Tender{},
+		&models.TenderItem{},
+		&models.TenderEvaluationCriterion{},
+		&models.TenderAddendum{},
+		&models.TenderAddendumAcknowledgement{},
+		&models.TenderInterest{},
+		&models.TenderClarification{},
 		&models.Bid{},
 		&models.BidItem{},
+		&models.TenderSealingKey{},
+		&models.BidOpening{},
+		&models.BidOpeningAttendee{},
+		&models.BidOpeningRegisterEntry{},
+		&models.BidEvaluationResult{},
+		&models.EvaluationPanel{},
+		&models.UserEvaluationPanelMembership{},
+		&models.ConflictOfInterestDeclaration{},
+		&models.EvaluationPanelRecommendation{},
+		&models.EvaluationPanelSignature{},
+		&models.PurchaseOrder{},
+		&models.PurchaseOrderItem{},
+		&models.Delivery{},
+		&models.SupplierRating{},
+		&models.Invoice{},
+		&models.InvoiceItem{},
+		&models.Asset{},
+		&models.Attachment{},
+		&models.NumberSequence{},
 		&models.PasswordReset{},
 		&models.Session{},
+		&models.ScheduledJob{},
+		&models.Report{},
 	); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 	log.Println("Database migration successful.")
+	if err := database.MigrateSupplierOrganisations(db); err != nil {
+		log.Fatalf("Failed to migrate supplier organisations: %v", err)
+	}
+	if err := database.MigrateLegacyBidItemFiles(db); err != nil {
+		log.Fatalf("Failed to migrate bid item files: %v", err)
+	}
+	if err := database.MigrateRequisitionApprovals(db); err != nil {
+		log.Fatalf("Failed to migrate requisition approvals: %v", err)
+	}
+
+	blobStore, err := services.GetBlobStore()
+	if err != nil {
+		log.Fatalf("Failed to create blob store: %v", err)
+	}
+	emailService, err := services.GetEmailService()
+	if err != nil {
+		log.Fatalf("Failed to create email service: %v", err)
+	}
+
+	// Cancelled on SIGINT or SIGTERM to stop the scheduler and the server
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	scheduler := handlers.NewScheduler(db, emailService, schedulerPollInterval())
+	if err := scheduler.Start(ctx); err != nil {
+		log.Fatalf("Failed to start scheduler: %v", err)
+	}
 
 	r := chi.NewRouter()
 	c := cors.New(cors.Options{
@@ -97,6 +198,18 @@ func main() {
 		
 		// Register user routes
 		handlers.RegisterUserRoutes(apiRouter)
+
+		signatureService, err := services.NewHMACSignatureService()
+		if err != nil {
+			log.Fatalf("Failed to create signature service: %v", err)
+		}
+		bidSealer, err := services.NewX25519BidSealingService()
+		if err != nil {
+			log.Fatalf("Failed to create bid sealing service: %v", err)
+		}
+		// Signed file links carry their own authorisation
+		attachmentHandler := handlers.NewAttachmentHandler(db, blobStore, signatureService, bidSealer)
+		apiRouter.Get("/files/{attachmentId}/signed", attachmentHandler.DownloadSignedFile)
 		
 		// Protected routes
 		apiRouter.Group(func(authRouter chi.Router) {
@@ -113,14 +226,115 @@ func main() {
 			authRouter.Get("/requisitions", handlers.ListRequisitionsHandler)
 			authRouter.Get("/requisitions/{id}", handlers.GetRequisitionHandler)
 			authRouter.Post("/requisitions/{id}/action", handlers.HandleRequisitionAction)
+			authRouter.Post("/requisitions/{id}/submit", handlers.SubmitRequisitionHandler)
 
-			tenderHandler := handlers.NewTenderHandler(db)
+			approvalChainHandler := handlers.NewApprovalChainHandler(db)
+			authRouter.Get("/approval-chains", approvalChainHandler.ListApprovalChains)
+			authRouter.Post("/approval-chains", approvalChainHandler.CreateApprovalChain)
+			authRouter.Get("/approval-chains/{id}", approvalChainHandler.GetApprovalChain)
+			authRouter.Put("/approval-chains/{id}", approvalChainHandler.UpdateApprovalChain)
+			authRouter.Delete("/approval-chains/{id}", approvalChainHandler.DeleteApprovalChain)
+
+			tenderHandler := handlers.NewTenderHandler(db, emailService)
 			authRouter.Post("/tenders", tenderHandler.CreateTender)
 			authRouter.Get("/tenders/{id}", tenderHandler.GetTenderByID)
-			bidHandler := handlers.NewBidHandler(db)
+			authRouter.Get("/tenders/{id}/items", tenderHandler.ListTenderItems)
+			authRouter.Post("/tenders/{id}/items", tenderHandler.CreateTenderItem)
+			authRouter.Put("/tenders/{id}/items/{itemId}", tenderHandler.UpdateTenderItem)
+			authRouter.Delete("/tenders/{id}/items/{itemId}", tenderHandler.DeleteTenderItem)
+			authRouter.Put("/tenders/{id}", tenderHandler.UpdateTender)
+			authRouter.Post("/tenders/{id}/publish", tenderHandler.PublishTender)
+			authRouter.Post("/tenders/{id}/close", tenderHandler.CloseTender)
+			authRouter.Post("/tenders/{id}/cancel", tenderHandler.CancelTender)
+			authRouter.Get("/tenders/{id}/addenda", tenderHandler.ListAddenda)
+			authRouter.Post("/tenders/{id}/addenda", tenderHandler.CreateAddendum)
+			authRouter.Post("/tenders/{id}/addenda/{number}/acknowledge", tenderHandler.AcknowledgeAddendum)
+			authRouter.Post("/tenders/{id}/interest", tenderHandler.RegisterTenderInterest)
+			authRouter.Get("/tenders/{id}/clarifications", tenderHandler.ListClarifications)
+			authRouter.Post("/tenders/{id}/clarifications", tenderHandler.AskClarification)
+			authRouter.Post("/tenders/{id}/clarifications/{clarificationId}/answer", tenderHandler.AnswerClarification)
+			evaluationHandler := handlers.NewEvaluationHandler(db, signatureService)
+			authRouter.Get("/tenders/{id}/criteria", evaluationHandler.ListCriteria)
+			authRouter.Post("/tenders/{id}/criteria", evaluationHandler.CreateCriterion)
+			authRouter.Put("/tenders/{id}/criteria/{criterionId}", evaluationHandler.UpdateCriterion)
+			authRouter.Delete("/tenders/{id}/criteria/{criterionId}", evaluationHandler.DeleteCriterion)
+			authRouter.Get("/tenders/{id}/scores", evaluationHandler.ListTenderScores)
+			authRouter.Get("/tenders/{id}/bids/{bidId}/scores", evaluationHandler.GetBidScores)
+			authRouter.Put("/tenders/{id}/bids/{bidId}/scores", evaluationHandler.SaveBidScores)
+			authRouter.Post("/tenders/{id}/bids/{bidId}/scores/submit", evaluationHandler.SubmitBidScores)
+			authRouter.Get("/tenders/{id}/panels", evaluationHandler.ListPanels)
+			authRouter.Post("/tenders/{id}/panels", evaluationHandler.CreatePanel)
+			authRouter.Get("/panels/{panelId}", evaluationHandler.GetPanel)
+			authRouter.Post("/panels/{panelId}/members", evaluationHandler.AddPanelMember)
+			authRouter.Delete("/panels/{panelId}/members/{userId}", evaluationHandler.RemovePanelMember)
+			authRouter.Post("/panels/{panelId}/declarations", evaluationHandler.FileDeclarations)
+			authRouter.Get("/panels/{panelId}/recommendations", evaluationHandler.ListRecommendations)
+			authRouter.Post("/panels/{panelId}/recommendations", evaluationHandler.CreateRecommendation)
+			authRouter.Get("/recommendations/{recommendationId}", evaluationHandler.GetRecommendation)
+			authRouter.Post("/recommendations/{recommendationId}/signatures", evaluationHandler.SignRecommendation)
+			authRouter.Post("/recommendations/{recommendationId}/award", evaluationHandler.AwardRecommendation)
+			bidHandler := handlers.NewBidHandler(db, blobStore, bidSealer, signatureService)
 			authRouter.Post("/tenders/{tenderId}/bids", bidHandler.CreateBid)
 			authRouter.Get("/tenders/{tenderId}/bids", bidHandler.ListTenderBids)
+			authRouter.Put("/tenders/{tenderId}/bids/{bidId}", bidHandler.ReplaceBid)
+			authRouter.Post("/tenders/{tenderId}/bids/{bidId}/withdraw", bidHandler.WithdrawBid)
+			authRouter.Post("/tenders/{id}/open", bidHandler.OpenTenderBids)
+			authRouter.Get("/tenders/{id}/opening", bidHandler.GetBidOpening)
+			authRouter.Get("/tenders/{id}/opening/register", bidHandler.DownloadOpeningRegister)
 			authRouter.Get("/my-bids", bidHandler.ListMyBids)
+			purchaseOrderHandler := handlers.NewPurchaseOrderHandler(db)
+			authRouter.Get("/purchase-orders", purchaseOrderHandler.ListPurchaseOrders)
+			authRouter.Post("/purchase-orders", purchaseOrderHandler.CreatePurchaseOrder)
+			authRouter.Get("/purchase-orders/{id}", purchaseOrderHandler.GetPurchaseOrder)
+			authRouter.Put("/purchase-orders/{id}", purchaseOrderHandler.UpdatePurchaseOrder)
+			authRouter.Post("/purchase-orders/{id}/issue", purchaseOrderHandler.IssuePurchaseOrder)
+			authRouter.Post("/purchase-orders/{id}/acknowledge", purchaseOrderHandler.AcknowledgePurchaseOrder)
+			authRouter.Post("/purchase-orders/{id}/close", purchaseOrderHandler.ClosePurchaseOrder)
+			authRouter.Get("/purchase-orders/{id}/deliveries", purchaseOrderHandler.ListDeliveries)
+			authRouter.Post("/purchase-orders/{id}/deliveries", purchaseOrderHandler.CreateDelivery)
+			authRouter.Post("/purchase-orders/{id}/deliveries/{deliveryId}/rating", purchaseOrderHandler.RateDelivery)
+			invoiceHandler := handlers.NewInvoiceHandler(db, invoiceMatchTolerance())
+			authRouter.Get("/purchase-orders/{id}/invoices", invoiceHandler.ListPurchaseOrderInvoices)
+			authRouter.Post("/purchase-orders/{id}/invoices", invoiceHandler.CreateInvoice)
+			authRouter.Get("/invoices", invoiceHandler.ListInvoices)
+			authRouter.Get("/invoices/{invoiceId}", invoiceHandler.GetInvoice)
+			authRouter.Post("/invoices/{invoiceId}/match", invoiceHandler.MatchInvoice)
+			authRouter.Post("/invoices/{invoiceId}/approve", invoiceHandler.ApproveInvoice)
+			authRouter.Post("/invoices/{invoiceId}/pay", invoiceHandler.PayInvoice)
+			supplierHandler := handlers.NewSupplierHandler(db, blobStore)
+			authRouter.Get("/suppliers", supplierHandler.ListSuppliers)
+			authRouter.Post("/suppliers", supplierHandler.CreateSupplier)
+			authRouter.Get("/suppliers/{id}", supplierHandler.GetSupplier)
+			authRouter.Put("/suppliers/{id}", supplierHandler.UpdateSupplier)
+			authRouter.Delete("/suppliers/{id}", supplierHandler.DeleteSupplier)
+			authRouter.Post("/suppliers/{id}/approve", supplierHandler.ApproveSupplier)
+			authRouter.Post("/suppliers/{id}/reject", supplierHandler.RejectSupplier)
+			authRouter.Post("/suppliers/{id}/documents", supplierHandler.UploadSupplierDocuments)
+			authRouter.Get("/suppliers/{id}/documents/{documentId}", supplierHandler.DownloadSupplierDocument)
+			authRouter.Get("/suppliers/{id}/scorecard", supplierHandler.GetSupplierScorecard)
+			authRouter.Get("/suppliers/{id}/debarments", supplierHandler.ListSupplierDebarments)
+			authRouter.Post("/suppliers/{id}/debarments", supplierHandler.DebarSupplier)
+			authRouter.Get("/debarments/active", supplierHandler.ListDebarredSuppliers)
+			authRouter.Post("/debarments/{debarmentId}/lift", supplierHandler.LiftDebarment)
+			authRouter.Post("/suppliers/{id}/users", supplierHandler.AddSupplierUser)
+			authRouter.Delete("/suppliers/{id}/users/{userId}", supplierHandler.RemoveSupplierUser)
+			authRouter.Get("/attachments", attachmentHandler.ListAttachments)
+			authRouter.Post("/attachments", attachmentHandler.UploadAttachments)
+			authRouter.Get("/attachments/{attachmentId}/download", attachmentHandler.DownloadAttachment)
+			authRouter.Delete("/attachments/{attachmentId}", attachmentHandler.DeleteAttachment)
+			authRouter.Get("/files/{attachmentId}", attachmentHandler.DownloadAttachment)
+			authRouter.Post("/files/{attachmentId}/link", attachmentHandler.CreateFileLink)
+			assetHandler := handlers.NewAssetHandler(db)
+			authRouter.Get("/assets", assetHandler.ListAssets)
+			authRouter.Post("/assets", assetHandler.CreateAsset)
+			authRouter.Get("/assets/{id}", assetHandler.GetAsset)
+			authRouter.Put("/assets/{id}", assetHandler.UpdateAsset)
+			authRouter.Delete("/assets/{id}", assetHandler.DeleteAsset)
+			authRouter.Get("/assets/{id}/depreciation", assetHandler.GetAssetDepreciation)
+			authRouter.Get("/scheduled-jobs", scheduler.ListScheduledJobs)
+			reportHandler := handlers.NewReportHandler(db)
+			authRouter.Get("/reports", reportHandler.ListReports)
+			authRouter.Get("/reports/{id}", reportHandler.GetReport)
 			authRouter.Get("/dashboard/requisition-stats", handlers.GetRequisitionStatsHandler)
 			authRouter.Get("/dashboard/recent-requisitions", handlers.GetRecentRequisitionsHandler)
 			authRouter.Get("/dashboard/live-tenders", handlers.GetLiveTendersHandler)
@@ -132,8 +346,24 @@ func main() {
 	})
 
 	serveFrontend(r, "frontend/dist")
+	server := &http.Server{Addr: ":8080", Handler: r}
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		log.Println("Shutting down: waiting for requests and scheduled jobs to finish...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("WARNING: Server shutdown did not complete: %v", err)
+		}
+	}()
+
 	log.Println("Server starting on :8080, serving API and Frontend")
-	if err := http.ListenAndServe(":8080", r); err != nil {
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("Could not start server: %s\n", err)
 	}
+	<-shutdownDone
+	scheduler.Wait()
+	log.Println("Server stopped.")
 }
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Approval step decisions.
+const (
+	ApprovalDecisionApproved = "approved"
+	ApprovalDecisionRejected = "rejected"
+)
+
+// ApprovalChain is a configurable sequence of approval levels a requisition must pass. A new
+// requisition is routed to the first active chain, by priority, whose rules it matches; rules
+// left unset match every requisition.
+type ApprovalChain struct {
+	ID              int64    `json:"id" gorm:"primaryKey"`
+	Name            string   `json:"name" gorm:"not null"`
+	Description     *string  `json:"description,omitempty"`
+	Priority        int      `json:"priority" gorm:"not null;index"` // Lower priorities are tried first
+	RequisitionType *string  `json:"requisition_type,omitempty"`     // Matches requisitions of this type
+	Department      *string  `json:"department,omitempty"`           // Matches requisitions from this department
+	MinTotalValue   *float64 `json:"min_total_value,omitempty"`      // Matches totals at or above this value
+	MaxTotalValue   *float64 `json:"max_total_value,omitempty"`      // Matches totals below this value
+	IsActive        bool     `json:"is_active" gorm:"not null"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Associations
+	Levels []ApprovalChainLevel `json:"levels" gorm:"foreignKey:ChainID;constraint:OnDelete:CASCADE"`
+}
+
+// ApprovalChainLevel is one level of an approval chain. It is approved by any user with the
+// approver role or by one of the named approvers; at least one of the two is set.
+type ApprovalChainLevel struct {
+	ID              int64   `json:"id" gorm:"primaryKey"`
+	ChainID         int64   `json:"chain_id" gorm:"not null;uniqueIndex:idx_chain_level"`
+	Level           int     `json:"level" gorm:"not null;uniqueIndex:idx_chain_level"` // 1 for the first level to approve
+	Name            *string `json:"name,omitempty"`                                    // E.g. 'Head of department'
+	ApproverRole    *string `json:"approver_role,omitempty"`
+	ApproverUserIDs []int64 `json:"approver_user_ids,omitempty" gorm:"serializer:json"`
+}
+
+// ApprovalStep records one approver's decision on a requisition at one level of its chain.
+type ApprovalStep struct {
+	ID            int64     `json:"id" gorm:"primaryKey"`
+	RequisitionID int64     `json:"requisition_id" gorm:"index;not null"`
+	Level         int       `json:"level" gorm:"not null"`
+	ApproverID    int64     `json:"approver_id" gorm:"index;not null"`
+	Decision      string    `json:"decision" gorm:"not null"` // 'approved' or 'rejected'
+	Comment       *string   `json:"comment,omitempty"`        // Required when rejecting
+	DecidedAt     time.Time `json:"decided_at" gorm:"not null"`
+
+	// Associations
+	Approver *User `json:"approver,omitempty" gorm:"foreignKey:ApproverID"`
+}
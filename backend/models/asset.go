@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Asset statuses.
+const (
+	AssetStatusActive      = "active"
+	AssetStatusInactive    = "inactive"
+	AssetStatusMaintenance = "maintenance"
+	AssetStatusDisposed    = "disposed"
+)
+
+// Depreciation methods.
+const (
+	DepreciationMethodStraightLine    = "straight_line"
+	DepreciationMethodReducingBalance = "reducing_balance"
+)
+
+// Asset corresponds to the Assets table.
+// It is an entry in the fixed asset register, optionally linked to the requisition, purchase
+// order and goods received note (GRN) it was procured through.
+type Asset struct {
+	ID          int64   `json:"id" gorm:"primaryKey"`
+	AmrID       string  `json:"amr_id" gorm:"column:amr_id;uniqueIndex;not null"`  // Asset management register number
+	EmrID       *string `json:"emr_id,omitempty" gorm:"column:emr_id;uniqueIndex"` // Equipment maintenance register number (nullable)
+	Description string  `json:"description" gorm:"not null"`
+	Category    *string `json:"category,omitempty"`
+	Status      string  `json:"status" gorm:"default:'active';not null"` // 'active', 'inactive', 'maintenance' or 'disposed'
+	Location    *string `json:"location,omitempty"`
+
+	PurchaseDate     *time.Time `json:"purchase_date,omitempty"`
+	PurchasePrice    *float64   `json:"purchase_price,omitempty"`
+	SupplierID       *int64     `json:"supplier_id,omitempty" gorm:"index"`
+	CapitalizedValue *float64   `json:"capitalized_value,omitempty"` // Purchase price plus capitalised ancillary costs
+
+	// Depreciation settings
+	DepreciationMethod     *string  `json:"depreciation_method,omitempty"`      // 'straight_line' or 'reducing_balance'
+	DepreciationUsefulLife *int     `json:"depreciation_useful_life,omitempty"` // In years
+	DepreciationAnnualRate *float64 `json:"depreciation_annual_rate,omitempty"` // Percentage per year, for reducing balance
+
+	// Procurement linkage
+	RequisitionID   *int64 `json:"requisition_id,omitempty" gorm:"index"`
+	PurchaseOrderID *int64 `json:"purchase_order_id,omitempty" gorm:"index"`
+	DeliveryID      *int64 `json:"delivery_id,omitempty" gorm:"index"` // GRN the asset was received on
+
+	CreatedByUserID *int64    `json:"created_by_user_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
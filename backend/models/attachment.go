@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Entity types an attachment can belong to.
+const (
+	AttachmentEntityRequisition   = "requisition"
+	AttachmentEntityTender        = "tender"
+	AttachmentEntityBid           = "bid"
+	AttachmentEntityPurchaseOrder = "purchase_order"
+	AttachmentEntityInvoice       = "invoice"
+)
+
+// Document types recorded on attachments created by the system itself.
+const (
+	AttachmentTypeSpecificationSheet = "specification_sheet"
+	AttachmentTypeItemImage          = "item_image"
+)
+
+// Attachment corresponds to the Attachments table.
+// It is a file attached to a requisition, tender, bid, purchase order or invoice.
+type Attachment struct {
+	ID               int64     `json:"id" gorm:"primaryKey"`
+	EntityType       string    `json:"entity_type" gorm:"not null;index:idx_attachment_entity"`
+	EntityID         int64     `json:"entity_id" gorm:"not null;index:idx_attachment_entity"`
+	FileName         string    `json:"file_name" gorm:"not null"`
+	StorageKey       string    `json:"-"` // Object key in the blob store, never exposed
+	ContentType      string    `json:"content_type"`
+	Size             int64     `json:"size"`
+	DocumentType     *string   `json:"document_type,omitempty"` // e.g. 'quotation', 'specification_sheet'
+	Description      *string   `json:"description,omitempty"`
+	UploadedByUserID *int64    `json:"uploaded_by_user_id,omitempty" gorm:"column:uploaded_by_user_id"`
+	UploadDate       time.Time `json:"upload_date" gorm:"column:upload_date;autoCreateTime"`
+	Sealed           bool      `json:"sealed" gorm:"not null;default:false"` // Content encrypted until bid opening
+}
@@ -8,18 +8,27 @@ import (
 type Bid struct {
 	ID                   int64      `json:"id" gorm:"primaryKey"`
 	TenderID             int64      `json:"tender_id" gorm:"index;not null"`
-	SupplierID           int64      `json:"supplier_id" gorm:"index;not null"`
+	SupplierID           int64      `json:"supplier_id" gorm:"index;not null"` // Supplier organisation, not the submitting user
+	SubmittedByID        *int64     `json:"submitted_by_id,omitempty" gorm:"index"` // Supplier user who submitted the bid
 	BidAmount            float64    `json:"bid_amount" gorm:"not null"`
 	SubmissionDate       time.Time  `json:"submission_date" gorm:"autoCreateTime"`
 	TechnicalProposalURL *string    `json:"technical_proposal_url,omitempty"`
 	FinancialProposalURL *string    `json:"financial_proposal_url,omitempty"`
 	Notes                *string    `json:"notes,omitempty"`
-	Status               string     `json:"status" gorm:"default:'submitted';not null"` // e.g., 'submitted', 'under_review', 'shortlisted', 'rejected', 'awarded', 'withdrawn'
+	Status               string     `json:"status" gorm:"default:'submitted';not null"` // e.g., 'submitted', 'under_review', 'shortlisted', 'rejected', 'awarded', 'withdrawn', 'superseded'
+	// Version numbers a supplier's bids on a tender; a replacement points at the bid it superseded
+	Version              int        `json:"version" gorm:"not null;default:1"`
+	ReplacesBidID        *int64     `json:"replaces_bid_id,omitempty" gorm:"index"`
+	WithdrawnAt          *time.Time `json:"withdrawn_at,omitempty"`
+	// While Sealed, BidAmount and the items' OfferedUnitPrice are zero and the real values are
+	// only held encrypted in SealedContent until the tender's bid opening date.
+	Sealed               bool       `json:"sealed" gorm:"not null;default:false"`
+	SealedContent        []byte     `json:"-"`
 	CreatedAt            time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt            time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
 
 	// Associations
 	Tender   Tender `json:"tender,omitempty" gorm:"foreignKey:TenderID"`
-	Supplier User   `json:"supplier,omitempty" gorm:"foreignKey:SupplierID"`
+	Supplier Supplier `json:"supplier,omitempty" gorm:"foreignKey:SupplierID"`
 	Items    []BidItem `json:"items,omitempty" gorm:"foreignKey:BidID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` // A bid comprises multiple items
 }
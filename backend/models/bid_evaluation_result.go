@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// BidEvaluationResult corresponds to the BidEvaluationResults table.
+// It holds one evaluator's score for one bid against one evaluation criterion.
+// Results are editable until the evaluator submits them, after which SubmittedAt is set and they are locked.
+type BidEvaluationResult struct {
+	ID             int64      `json:"id" gorm:"primaryKey"`
+	BidID          int64      `json:"bid_id" gorm:"not null;uniqueIndex:idx_bid_criterion_evaluator"`
+	CriterionID    int64      `json:"criterion_id" gorm:"not null;uniqueIndex:idx_bid_criterion_evaluator"`
+	EvaluatorID    int64      `json:"evaluator_id" gorm:"not null;uniqueIndex:idx_bid_criterion_evaluator;index"`
+	Score          float64    `json:"score" gorm:"not null"` // 0 up to the criterion's max score (100 if unset)
+	Comments       *string    `json:"comments,omitempty"`
+	EvaluationDate time.Time  `json:"evaluation_date" gorm:"autoUpdateTime"`
+	SubmittedAt    *time.Time `json:"submitted_at,omitempty"` // Set when the evaluator submits; locks the score
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+
+	// Associations
+	Criterion *TenderEvaluationCriterion `json:"criterion,omitempty" gorm:"foreignKey:CriterionID"`
+}
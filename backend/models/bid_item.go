@@ -5,12 +5,11 @@ import "time"
 // BidItem represents a specific item within a supplier's bid, corresponding to an item in the tender.
 // It includes the supplier's offered price and specifications for that item.
 // This table will store the details for each item in a bid.
-// The combination of BidID and RequisitionItemID (or a similar unique identifier for the tender item)
-// should ideally be unique if suppliers bid on pre-defined tender items.
-// If suppliers can add ad-hoc items, RequisitionItemID might be nullable.
+// Each BidItem maps to exactly one TenderItem of the bid's tender.
 type BidItem struct {
 	ID                  int64      `json:"id" gorm:"primaryKey"`
 	BidID               int64      `json:"bid_id" gorm:"index;not null"` // Foreign key to the Bid
+	TenderItemID        *int64     `json:"tender_item_id,omitempty" gorm:"index"` // Foreign key to the TenderItem being priced
 	RequisitionItemID   *int64     `json:"requisition_item_id,omitempty" gorm:"index"` // Foreign key to the original RequisitionItem, if applicable
 	Description         string     `json:"description" gorm:"not null"` // Can be copied from RequisitionItem or provided by supplier
 	Quantity            float64    `json:"quantity" gorm:"not null"`      // Typically copied from RequisitionItem
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Bid opening statuses.
+const (
+	BidOpeningStatusAwaitingQuorum = "awaiting_quorum" // Panel members are still joining the opening
+	BidOpeningStatusOpened         = "opened"          // The bids were opened and the register recorded
+)
+
+// BidOpening is the formal opening of a tender's bids. Panel members join it one by one; once a
+// quorum of the panel has joined, every bid is unsealed at once and the opening register is
+// recorded. A tender has a single opening and nothing about it changes once it is opened.
+type BidOpening struct {
+	ID                int64      `json:"id" gorm:"primaryKey"`
+	TenderID          int64      `json:"tender_id" gorm:"uniqueIndex;not null"`
+	EvaluationPanelID int64      `json:"evaluation_panel_id" gorm:"not null"`
+	Status            string     `json:"status" gorm:"default:'awaiting_quorum';not null"`
+	Quorum            int        `json:"quorum" gorm:"not null"` // Panel members required to open the bids
+	OpenedAt          *time.Time `json:"opened_at,omitempty"`
+	RegisterDigest    *string    `json:"register_digest,omitempty"` // Signature over the register, set when opened
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+
+	// Associations
+	Attendees []BidOpeningAttendee      `json:"attendees,omitempty" gorm:"foreignKey:BidOpeningID;constraint:OnDelete:CASCADE"`
+	Register  []BidOpeningRegisterEntry `json:"register,omitempty" gorm:"foreignKey:BidOpeningID;constraint:OnDelete:CASCADE"`
+}
+
+// BidOpeningAttendee records a panel member joining a bid opening.
+type BidOpeningAttendee struct {
+	BidOpeningID int64     `json:"bid_opening_id" gorm:"primaryKey;autoIncrement:false"`
+	UserID       int64     `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	JoinedAt     time.Time `json:"joined_at"`
+}
+
+// BidOpeningRegisterEntry is one bid as read out at the opening: who bid, for how much, when the
+// bid was received and which documents came with it.
+type BidOpeningRegisterEntry struct {
+	ID           int64     `json:"id" gorm:"primaryKey"`
+	BidOpeningID int64     `json:"bid_opening_id" gorm:"index;not null"`
+	BidID        int64     `json:"bid_id" gorm:"not null"`
+	SupplierID   int64     `json:"supplier_id" gorm:"not null"`
+	SupplierName string    `json:"supplier_name" gorm:"not null"`
+	BidAmount    float64   `json:"bid_amount" gorm:"not null"`
+	ReceivedAt   time.Time `json:"received_at" gorm:"not null"`
+	Documents    []string  `json:"documents" gorm:"serializer:json"` // "document type: file name" for each attachment
+}
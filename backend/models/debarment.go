@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Debarment statuses. An active debarment stops lapsing once its end date passes and is then
+// marked expired; lifted means an officer ended it early.
+const (
+	DebarmentStatusActive  = "active"
+	DebarmentStatusExpired = "expired"
+	DebarmentStatusLifted  = "lifted"
+)
+
+// SupplierDebarment bars a supplier from bidding and from receiving purchase orders between
+// StartDate and EndDate. A nil EndDate debars the supplier indefinitely (blacklisting).
+type SupplierDebarment struct {
+	ID         int64      `json:"id" gorm:"primaryKey"`
+	SupplierID int64      `json:"supplier_id" gorm:"not null;index"`
+	Reason     string     `json:"reason" gorm:"not null"`
+	StartDate  time.Time  `json:"start_date" gorm:"not null"`
+	EndDate    *time.Time `json:"end_date,omitempty"`
+	Status     string     `json:"status" gorm:"default:'active';not null;index"`
+	IssuedByID int64      `json:"issued_by_id" gorm:"not null"`
+
+	LiftedAt   *time.Time `json:"lifted_at,omitempty"`
+	LiftedByID *int64     `json:"lifted_by_id,omitempty"`
+	LiftReason *string    `json:"lift_reason,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Associations
+	Supplier *Supplier `json:"supplier,omitempty" gorm:"foreignKey:SupplierID"`
+}
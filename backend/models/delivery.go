@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Compliance statuses recorded on a goods received note. Non-compliant receipts are rejected
+// goods: they count as rejections on the supplier scorecard and not towards the ordered quantity.
+const (
+	ComplianceStatusCompliant    = "compliant"
+	ComplianceStatusNonCompliant = "non_compliant"
+)
+
+// Delivery corresponds to the Deliveries table.
+// Each record is a goods received note (GRN) for a quantity of one purchase order item;
+// an item may be received across several partial deliveries.
+type Delivery struct {
+	ID                  int64     `json:"id" gorm:"primaryKey"`
+	PurchaseOrderID     int64     `json:"purchase_order_id" gorm:"index;not null"`
+	PurchaseOrderItemID *int64    `json:"purchase_order_item_id,omitempty" gorm:"index"`
+	GRNNumber           string    `json:"grn_number" gorm:"column:grn_number;uniqueIndex;not null"` // e.g. GRN-2025-00001
+	DeliveryDate        time.Time `json:"delivery_date"`
+	ReceivedByID        *int64    `json:"received_by_id,omitempty" gorm:"index"`
+	QuantityReceived    float64   `json:"quantity_received" gorm:"not null"`
+	ComplianceStatus    *string   `json:"compliance_status,omitempty"` // 'compliant' or 'non_compliant'
+	Notes               *string   `json:"notes,omitempty"`
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Evaluation criterion types, matching the CHECK constraint of the legacy TenderEvaluationCriteria table.
+const (
+	CriterionTypeTechnical  = "technical"
+	CriterionTypeCommercial = "commercial"
+	CriterionTypeDelivery   = "delivery"
+	CriterionTypeCompliance = "compliance"
+)
+
+// TenderEvaluationCriterion corresponds to the TenderEvaluationCriteria table.
+// It describes one weighted criterion bids for a tender are scored against.
+// The weights of all criteria of a tender must add up to 100 before it can be published.
+type TenderEvaluationCriterion struct {
+	ID                  int64     `json:"id" gorm:"primaryKey"`
+	TenderID            int64     `json:"tender_id" gorm:"not null;uniqueIndex:idx_tender_criterion"`
+	Type                string    `json:"type" gorm:"not null;uniqueIndex:idx_tender_criterion"` // 'technical', 'commercial', 'delivery', 'compliance'
+	CriterionText       string    `json:"criterion_text" gorm:"not null;uniqueIndex:idx_tender_criterion"`
+	Weight              float64   `json:"weight" gorm:"not null"` // Percentage weight, 0-100
+	SubCriteriaName     *string   `json:"sub_criteria_name,omitempty" gorm:"uniqueIndex:idx_tender_criterion"`
+	SubCriteriaMaxScore *int      `json:"sub_criteria_max_score,omitempty"`
+	IsMandatory         bool      `json:"is_mandatory" gorm:"default:false"` // Bids failing a mandatory criterion are disqualified
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName keeps the table name aligned with the legacy schema's plural.
+func (TenderEvaluationCriterion) TableName() string {
+	return "tender_evaluation_criteria"
+}
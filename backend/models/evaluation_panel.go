@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// EvaluationPanel corresponds to the EvaluationPanels table.
+// A tender has at most one active panel, whose members evaluate the bids.
+type EvaluationPanel struct {
+	ID           int64     `json:"id" gorm:"primaryKey"`
+	TenderID     int64     `json:"tender_id" gorm:"index;not null"`
+	PanelName    *string   `json:"panel_name,omitempty"`
+	CreationDate time.Time `json:"creation_date" gorm:"autoCreateTime"`
+	Status       string    `json:"status" gorm:"default:'active';not null"` // 'active', 'dissolved'
+	CreatedByID  *int64    `json:"created_by_id,omitempty"`                 // Procurement officer who set up the panel
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Associations
+	Members      []UserEvaluationPanelMembership `json:"members,omitempty" gorm:"foreignKey:EvaluationPanelID;constraint:OnDelete:CASCADE"`
+	Declarations []ConflictOfInterestDeclaration `json:"declarations,omitempty" gorm:"foreignKey:EvaluationPanelID;constraint:OnDelete:CASCADE"`
+}
+
+// UserEvaluationPanelMembership corresponds to the UserEvaluationPanelMembership table.
+type UserEvaluationPanelMembership struct {
+	UserID            int64     `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	EvaluationPanelID int64     `json:"evaluation_panel_id" gorm:"primaryKey;autoIncrement:false"`
+	RoleInPanel       *string   `json:"role_in_panel,omitempty"` // E.g., 'chair', 'member', 'secretary'
+	AssignedDate      time.Time `json:"assigned_date" gorm:"autoCreateTime"`
+
+	// Associations
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// ConflictOfInterestDeclaration records a panel member's declaration about one bidding supplier.
+// Declarations are immutable once filed; a member must declare against every supplier that bid
+// on the tender, without any conflict, before they can see the bids.
+type ConflictOfInterestDeclaration struct {
+	ID                int64     `json:"id" gorm:"primaryKey"`
+	EvaluationPanelID int64     `json:"evaluation_panel_id" gorm:"not null;uniqueIndex:idx_panel_user_supplier"`
+	UserID            int64     `json:"user_id" gorm:"not null;uniqueIndex:idx_panel_user_supplier"`
+	SupplierID        int64     `json:"supplier_id" gorm:"not null;uniqueIndex:idx_panel_user_supplier"`
+	HasConflict       bool      `json:"has_conflict" gorm:"not null"`
+	Details           *string   `json:"details,omitempty"` // Nature of the conflict, required if HasConflict
+	DeclaredAt        time.Time `json:"declared_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Recommendation statuses.
+const (
+	RecommendationStatusPendingApproval = "pending_approval" // Awaiting signatures from panel members
+	RecommendationStatusSigned          = "signed"           // Every panel member has signed
+	RecommendationStatusDissented       = "dissented"        // At least one member dissented; a new recommendation is needed
+	RecommendationStatusAwarded         = "awarded"          // The tender was awarded on the strength of this recommendation
+)
+
+// EvaluationPanelRecommendation corresponds to the EvaluationPanelRecommendations table.
+// It is the panel's award recommendation for a tender, which every panel member must sign.
+type EvaluationPanelRecommendation struct {
+	ID                 int64     `json:"id" gorm:"primaryKey"`
+	EvaluationPanelID  int64     `json:"evaluation_panel_id" gorm:"index;not null"`
+	RecommendedBidID   *int64    `json:"recommended_bid_id,omitempty" gorm:"index"`
+	RecommendedByID    int64     `json:"recommended_by_id" gorm:"not null"` // Panel member who raised the recommendation
+	RecommendationDate time.Time `json:"recommendation_date" gorm:"autoCreateTime"`
+	Justification      string    `json:"justification" gorm:"not null"`
+	OverallSummary     *string   `json:"overall_summary,omitempty"`
+	Status             string    `json:"status" gorm:"default:'pending_approval';not null"`
+	UpdatedAt          time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Associations
+	RecommendedBid *Bid                       `json:"recommended_bid,omitempty" gorm:"foreignKey:RecommendedBidID"`
+	Signatures     []EvaluationPanelSignature `json:"signatures,omitempty" gorm:"foreignKey:RecommendationID;constraint:OnDelete:CASCADE"`
+}
+
+// EvaluationPanelSignature corresponds to the EvaluationPanelSignatures table.
+// It records one panel member's sign-off on, or dissent from, a recommendation.
+type EvaluationPanelSignature struct {
+	RecommendationID int64     `json:"recommendation_id" gorm:"primaryKey;autoIncrement:false"`
+	UserID           int64     `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Dissent          bool      `json:"dissent" gorm:"not null;default:false"`
+	DigitalSignature string    `json:"digital_signature" gorm:"not null"` // Digest binding the member to the recommendation's content
+	SignedAt         time.Time `json:"signed_at"`
+	Comments         *string   `json:"comments,omitempty"` // Required when dissenting
+}
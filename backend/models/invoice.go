@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Invoice statuses.
+const (
+	InvoiceStatusPending            = "pending"              // Submitted; not yet matched, or matched with variances
+	InvoiceStatusMatched            = "matched"              // Every line agrees with the purchase order and goods accepted
+	InvoiceStatusApprovedForPayment = "approved_for_payment" // Approved by finance
+	InvoiceStatusPaid               = "paid"                 // Payment made
+)
+
+// Invoice corresponds to the Invoices table.
+// It is a supplier's bill against a purchase order, three-way matched against the order and its GRNs.
+type Invoice struct {
+	ID               int64      `json:"id" gorm:"primaryKey"`
+	PurchaseOrderID  int64      `json:"purchase_order_id" gorm:"index;not null"`
+	SupplierID       int64      `json:"supplier_id" gorm:"not null;uniqueIndex:idx_supplier_invoice_number"`
+	InvoiceNumber    string     `json:"invoice_number" gorm:"not null;uniqueIndex:idx_supplier_invoice_number"` // The supplier's own invoice number
+	InvoiceDate      time.Time  `json:"invoice_date" gorm:"not null"`
+	DueDate          *time.Time `json:"due_date,omitempty"`
+	Subtotal         float64    `json:"subtotal" gorm:"not null"`
+	TaxAmount        float64    `json:"tax_amount" gorm:"not null;default:0"`
+	TotalAmount      float64    `json:"total_amount" gorm:"not null"`
+	Status           string     `json:"status" gorm:"default:'pending';not null"`
+	HasVariance      bool       `json:"has_variance" gorm:"not null;default:false"` // Set by the last match when any line is outside tolerance
+	MatchedAt        *time.Time `json:"matched_at,omitempty"`
+	SubmittedByID    *int64     `json:"submitted_by_id,omitempty"`
+	ApprovedByID     *int64     `json:"approved_by_id,omitempty"`
+	ApprovedAt       *time.Time `json:"approved_at,omitempty"`
+	PaymentDate      *time.Time `json:"payment_date,omitempty"`
+	PaymentReference *string    `json:"payment_reference,omitempty"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Associations
+	Items []InvoiceItem `json:"items,omitempty" gorm:"foreignKey:InvoiceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// InvoiceItem is one billed line of an invoice, against one purchase order item.
+// The variance fields are filled in by three-way matching.
+type InvoiceItem struct {
+	ID                  int64     `json:"id" gorm:"primaryKey"`
+	InvoiceID           int64     `json:"invoice_id" gorm:"index;not null"`
+	PurchaseOrderItemID int64     `json:"purchase_order_item_id" gorm:"index;not null"`
+	Quantity            float64   `json:"quantity" gorm:"not null"`
+	UnitPrice           float64   `json:"unit_price" gorm:"not null"`
+	LineTotal           float64   `json:"line_total" gorm:"not null"`
+	OrderedUnitPrice    float64   `json:"ordered_unit_price"` // Unit price on the purchase order
+	ReceivedQuantity    float64   `json:"received_quantity"`  // Accepted on GRNs and not yet billed on another invoice
+	QuantityVariance    float64   `json:"quantity_variance"`  // Invoiced quantity minus ReceivedQuantity
+	PriceVariance       float64   `json:"price_variance"`     // Invoiced unit price minus OrderedUnitPrice
+	WithinTolerance     bool      `json:"within_tolerance"`   // Both variances within the configured tolerance
+	VarianceNote        *string   `json:"variance_note,omitempty"`
+	CreatedAt           time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// NumberSequence holds the last value handed out for a named document number series,
+// such as the purchase order numbers of one year.
+type NumberSequence struct {
+	Name      string `json:"name" gorm:"primaryKey"`
+	LastValue int64  `json:"last_value" gorm:"not null;default:0"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Purchase order statuses. A purchase order moves strictly forward through them.
+const (
+	PurchaseOrderStatusDraft        = "draft"        // Being prepared by procurement, not yet visible to the supplier
+	PurchaseOrderStatusIssued       = "issued"       // Sent to the supplier
+	PurchaseOrderStatusAcknowledged = "acknowledged" // Supplier has accepted the order
+	PurchaseOrderStatusClosed       = "closed"       // Fulfilled and closed by procurement
+)
+
+// Delivery statuses of a purchase order item, rolled up from the quantity accepted on its goods
+// received notes; goods rejected as non-compliant do not count.
+const (
+	DeliveryStatusPending  = "pending"  // Nothing accepted yet
+	DeliveryStatusPartial  = "partial"  // Some, but not all, of the ordered quantity accepted
+	DeliveryStatusComplete = "complete" // The full ordered quantity accepted
+)
+
+// PurchaseOrder corresponds to the PurchaseOrders table.
+// It is raised from an awarded bid and copies the bid's items as its line items.
+type PurchaseOrder struct {
+	ID                   int64      `json:"id" gorm:"primaryKey"`
+	RecommendationID     *int64     `json:"recommendation_id,omitempty" gorm:"uniqueIndex"` // Award recommendation the order rests on
+	BidID                *int64     `json:"bid_id,omitempty" gorm:"uniqueIndex"`            // Awarded bid the order was generated from
+	TenderID             *int64     `json:"tender_id,omitempty" gorm:"index"`
+	SupplierID           int64      `json:"supplier_id" gorm:"index;not null"`
+	PONumber             string     `json:"po_number" gorm:"column:po_number;uniqueIndex;not null"` // e.g. PO-2025-00001
+	OrderDate            time.Time  `json:"order_date" gorm:"autoCreateTime"`
+	Status               string     `json:"status" gorm:"default:'draft';not null"`
+	TotalAmount          float64    `json:"total_amount" gorm:"not null"`
+	Currency             string     `json:"currency" gorm:"default:'TZS';not null"`
+	PaymentTerms         *string    `json:"payment_terms,omitempty"`
+	DeliveryAddress      *string    `json:"delivery_address,omitempty"`
+	ExpectedDeliveryDate *time.Time `json:"expected_delivery_date,omitempty"` // Deliveries after this date count as late on the supplier scorecard
+	CreatedByID          *int64     `json:"created_by_id,omitempty"`
+	IssuedAt             *time.Time `json:"issued_at,omitempty"`
+	AcknowledgedAt       *time.Time `json:"acknowledged_at,omitempty"`
+	ClosedAt             *time.Time `json:"closed_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Associations
+	Items []PurchaseOrderItem `json:"items,omitempty" gorm:"foreignKey:PurchaseOrderID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// PurchaseOrderItem corresponds to the PurchaseOrderItems table.
+// Each item is copied from one BidItem of the awarded bid.
+type PurchaseOrderItem struct {
+	ID                int64     `json:"id" gorm:"primaryKey"`
+	PurchaseOrderID   int64     `json:"purchase_order_id" gorm:"index;not null"`
+	BidItemID         *int64    `json:"bid_item_id,omitempty" gorm:"index"`
+	RequisitionItemID *int64    `json:"requisition_item_id,omitempty" gorm:"index"`
+	Description       string    `json:"description" gorm:"not null"`
+	Quantity          float64   `json:"quantity" gorm:"not null"`
+	Unit              string    `json:"unit" gorm:"not null"`
+	UnitPrice         float64   `json:"unit_price" gorm:"not null"`
+	TotalPrice        float64   `json:"total_price" gorm:"not null"`
+	DeliveryStatus    string    `json:"delivery_status" gorm:"default:'pending';not null"` // 'pending', 'partial' or 'complete'
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
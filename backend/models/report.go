@@ -0,0 +1,21 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Report kinds.
+const (
+	ReportKindSupplierScorecards = "supplier_scorecards" // Scorecard of every supplier for a calendar month
+)
+
+// Report is the stored output of a recurring report job, one per kind and period.
+type Report struct {
+	ID          int64           `json:"id" gorm:"primaryKey"`
+	Kind        string          `json:"kind" gorm:"not null;uniqueIndex:idx_report_kind_period"`
+	PeriodStart time.Time       `json:"period_start" gorm:"not null;uniqueIndex:idx_report_kind_period"`
+	PeriodEnd   time.Time       `json:"period_end" gorm:"not null"`
+	Data        json.RawMessage `json:"data,omitempty" gorm:"type:text"`
+	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -6,13 +6,13 @@ import "time"
 type RequisitionStatus string
 
 const (
-	RequisitionStatusPendingApproval1 RequisitionStatus = "pending_approval_1"
-	RequisitionStatusPendingApproval2 RequisitionStatus = "pending_approval_2"
-	RequisitionStatusApproved         RequisitionStatus = "Approved"
-	RequisitionStatusRejected         RequisitionStatus = "rejected"
-	RequisitionStatusPendingTender    RequisitionStatus = "pending_tender" // Or 'awaiting_tender', 'ready_for_tender'
-	RequisitionStatusTendered         RequisitionStatus = "tendered"
-	RequisitionStatusClosed           RequisitionStatus = "closed" // e.g., after tender awarded or PR cancelled
+	RequisitionStatusDraft           RequisitionStatus = "draft"
+	RequisitionStatusPendingApproval RequisitionStatus = "pending_approval" // Awaiting the approvers of CurrentApprovalLevel
+	RequisitionStatusApproved        RequisitionStatus = "Approved"
+	RequisitionStatusRejected        RequisitionStatus = "rejected"
+	RequisitionStatusPendingTender   RequisitionStatus = "pending_tender" // Or 'awaiting_tender', 'ready_for_tender'
+	RequisitionStatusTendered        RequisitionStatus = "tendered"
+	RequisitionStatusClosed          RequisitionStatus = "closed" // e.g., after tender awarded or PR cancelled
 )
 
 // Requisition corresponds to the Requisitions table
@@ -23,21 +23,25 @@ type Requisition struct {
 	AAC           *string           `json:"aac,omitempty"`            // 'A', 'F', 'P' (nullable)
 	MaterialGroup *string           `json:"material_group,omitempty"` // (nullable)
 	ExchangeRate  *float64          `json:"exchange_rate,omitempty"`  // (nullable)
-	Status        RequisitionStatus `json:"status" gorm:"type:varchar(50);default:'pending_approval_1'"`
+	Department    *string           `json:"department,omitempty"`     // Requesting department; defaults to the requester's
+	TotalValue    float64           `json:"total_value"`              // Estimated value of all items, used to route approval
+	Status        RequisitionStatus `json:"status" gorm:"type:varchar(50);default:'pending_approval'"`
 
 	// Approval fields
-	ApproverOneID   *int64     `json:"approver_one_id,omitempty" gorm:"index"` // ID of the first approver
-	ApprovedOneAt   *time.Time `json:"approved_one_at,omitempty"`              // Timestamp of first approval
-	ApproverTwoID   *int64     `json:"approver_two_id,omitempty" gorm:"index"` // ID of the second approver
-	ApprovedTwoAt   *time.Time `json:"approved_two_at,omitempty"`              // Timestamp of second approval
-	RejectionReason *string    `json:"rejection_reason,omitempty"`             // Reason if rejected
+	ApprovalChainID      *int64  `json:"approval_chain_id,omitempty" gorm:"index"` // Chain the requisition was routed to
+	CurrentApprovalLevel int     `json:"current_approval_level"`                   // Level awaiting a decision; 0 once decided
+	RejectionReason      *string `json:"rejection_reason,omitempty"`               // Reason if rejected
 
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
 	// Associations
-	Items []RequisitionItem `json:"items" gorm:"foreignKey:RequisitionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Items         []RequisitionItem `json:"items" gorm:"foreignKey:RequisitionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ApprovalChain *ApprovalChain    `json:"approval_chain,omitempty" gorm:"foreignKey:ApprovalChainID"`
+	ApprovalSteps []ApprovalStep    `json:"approval_steps,omitempty" gorm:"foreignKey:RequisitionID;constraint:OnDelete:CASCADE"`
 	// User  User              `json:"user,omitempty" gorm:"foreignKey:UserID"` // Optional: to preload user details
+
+	CanDecide bool `json:"can_decide" gorm:"-"` // Whether the current user may approve or reject the current level
 }
 
 // RequisitionItem corresponds to the RequisitionItems table
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Scheduled job kinds.
+const (
+	JobKindCloseTender             = "close_tender"              // Closes a published tender at its closing date
+	JobKindStartBidOpening         = "start_bid_opening"         // Starts a closed tender's bid opening at its bid opening date
+	JobKindPurgeExpiredTokens      = "purge_expired_tokens"      // Deletes expired password resets and sessions
+	JobKindLiftExpiredDebarments   = "lift_expired_debarments"   // Marks debarments past their end date as expired
+	JobKindSupplierScorecardReport = "supplier_scorecard_report" // Records the previous month's supplier scorecards
+)
+
+// Scheduled job statuses.
+const (
+	ScheduledJobStatusPending = "pending" // Waiting for its run time
+	ScheduledJobStatusRunning = "running" // Claimed by the scheduler until LockedUntil
+	ScheduledJobStatusDone    = "done"    // A one-off job that ran successfully
+	ScheduledJobStatusFailed  = "failed"  // A one-off job that kept failing and was given up
+)
+
+// Recurrences of scheduled jobs.
+const (
+	JobRecurrenceHourly  = "hourly"
+	JobRecurrenceDaily   = "daily"
+	JobRecurrenceMonthly = "monthly"
+)
+
+// ScheduledJob is a unit of background work run by the scheduler once its run time has passed.
+// Jobs live in the database so they survive restarts. A kind has at most one job per entity;
+// rescheduling moves the existing job rather than adding another.
+type ScheduledJob struct {
+	ID          int64      `json:"id" gorm:"primaryKey"`
+	Kind        string     `json:"kind" gorm:"not null;uniqueIndex:idx_job_kind_entity"`
+	EntityID    int64      `json:"entity_id" gorm:"not null;default:0;uniqueIndex:idx_job_kind_entity"` // Tender the job acts on; 0 for housekeeping jobs
+	Recurrence  *string    `json:"recurrence,omitempty"`                                                // Hourly, daily or monthly; unset for one-off jobs
+	RunAt       time.Time  `json:"run_at" gorm:"index;not null"`
+	Status      string     `json:"status" gorm:"default:'pending';not null"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"` // Failed attempts at the current run
+	LastError   *string    `json:"last_error,omitempty"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"` // A running job whose lock has lapsed is picked up again
+	LastRunAt   *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// Supplier statuses. Self-registered suppliers start in pending_verification and can only bid
+// once a procurement officer has approved them.
+const (
+	SupplierStatusPendingVerification = "pending_verification"
+	SupplierStatusActive              = "active"
+	SupplierStatusInactive            = "inactive"
+	SupplierStatusRejected            = "rejected"
+)
+
+// Compliance document types a supplier submits when registering.
+const (
+	SupplierDocumentIncorporation   = "certificate_of_incorporation"
+	SupplierDocumentTaxClearance    = "tax_clearance"
+	SupplierDocumentBusinessLicence = "business_licence"
+	SupplierDocumentOther           = "other"
+)
+
+// Supplier corresponds to the Suppliers table.
+// It is a vendor organisation; the users who act for it are linked through User.SupplierID,
+// and bids, purchase orders and invoices belong to the organisation rather than to one user.
+type Supplier struct {
+	ID                 int64   `json:"id" gorm:"primaryKey"`
+	Name               string  `json:"name" gorm:"uniqueIndex;not null"`
+	RegistrationNumber *string `json:"registration_number,omitempty" gorm:"uniqueIndex"`  // Company registration number
+	TaxID              *string `json:"tax_id,omitempty" gorm:"column:tax_id;uniqueIndex"` // Taxpayer identification number
+	ContactPerson      *string `json:"contact_person,omitempty"`
+	Email              *string `json:"email,omitempty" gorm:"uniqueIndex"`
+	Phone              *string `json:"phone,omitempty"`
+	Address            *string `json:"address,omitempty"`
+	Status             string  `json:"status" gorm:"default:'active';not null"`
+
+	// Bank details for payments
+	BankName          *string `json:"bank_name,omitempty"`
+	BankBranch        *string `json:"bank_branch,omitempty"`
+	BankAccountName   *string `json:"bank_account_name,omitempty"`
+	BankAccountNumber *string `json:"bank_account_number,omitempty"`
+
+	// Onboarding review
+	VerifiedByID    *int64     `json:"verified_by_id,omitempty"`
+	VerifiedAt      *time.Time `json:"verified_at,omitempty"`
+	RejectionReason *string    `json:"rejection_reason,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Associations
+	Categories []SupplierCategory `json:"categories,omitempty" gorm:"foreignKey:SupplierID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Users      []User             `json:"users,omitempty" gorm:"foreignKey:SupplierID"`
+	Documents  []SupplierDocument `json:"documents,omitempty" gorm:"foreignKey:SupplierID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// SupplierCategory records one category of goods or services a supplier offers.
+type SupplierCategory struct {
+	SupplierID int64  `json:"supplier_id" gorm:"primaryKey;autoIncrement:false"`
+	Category   string `json:"category" gorm:"primaryKey"`
+}
+
+// SupplierDocument is a compliance document uploaded for a supplier, such as its certificate of
+// incorporation or tax clearance.
+type SupplierDocument struct {
+	ID           int64      `json:"id" gorm:"primaryKey"`
+	SupplierID   int64      `json:"supplier_id" gorm:"not null;index"`
+	DocumentType string     `json:"document_type" gorm:"not null"`
+	FileName     string     `json:"file_name" gorm:"not null"`
+	StorageKey   string     `json:"-" gorm:"not null"` // Object key in the blob store, never exposed
+	ContentType  *string    `json:"content_type,omitempty"`
+	Size         int64      `json:"size"`
+	ExpiryDate   *time.Time `json:"expiry_date,omitempty"`
+	UploadedByID *int64     `json:"uploaded_by_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// SupplierRating is a receiver's 1-5 rating of a supplier for one goods received note.
+// Ratings feed the supplier scorecard alongside delivery and invoice history.
+type SupplierRating struct {
+	ID              int64     `json:"id" gorm:"primaryKey"`
+	SupplierID      int64     `json:"supplier_id" gorm:"not null;index"`
+	PurchaseOrderID int64     `json:"purchase_order_id" gorm:"not null;index"`
+	DeliveryID      int64     `json:"delivery_id" gorm:"not null;uniqueIndex"` // One rating per goods received note
+	RatedByID       int64     `json:"rated_by_id" gorm:"not null"`
+	Timeliness      int       `json:"timeliness" gorm:"not null"`
+	Quality         int       `json:"quality" gorm:"not null"`
+	Compliance      int       `json:"compliance" gorm:"not null"`
+	Comment         *string   `json:"comment,omitempty"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -2,6 +2,16 @@ package models
 
 import "time"
 
+// Tender statuses. A tender moves forward through them; it can be cancelled until it is awarded.
+const (
+	TenderStatusDraft      = "draft"      // Being prepared by procurement, not visible to suppliers
+	TenderStatusPublished  = "published"  // Open for bids until the closing date
+	TenderStatusClosed     = "closed"     // Past the closing date, bids sealed until the opening
+	TenderStatusEvaluation = "evaluation" // Bids opened and being evaluated by the panel
+	TenderStatusAwarded    = "awarded"    // Awarded to the recommended bid
+	TenderStatusCancelled  = "cancelled"  // Abandoned before award
+)
+
 // Tender corresponds to the Tenders table in the database.
 // It represents a formal invitation for suppliers to submit a bid to supply goods or services.
 type Tender struct {
@@ -11,11 +21,17 @@ type Tender struct {
 	Description        *string    `json:"description,omitempty"`
 	Category           *string    `json:"category,omitempty"`         // E.g., 'goods', 'services', 'works', 'consultancy'
 	Budget             *float64   `json:"budget,omitempty"`           // Estimated budget for the tender
-	Status             *string    `json:"status,omitempty" gorm:"default:'draft'"` // E.g., 'draft', 'published', 'evaluation', 'awarded', 'cancelled'
+	Status             *string    `json:"status,omitempty" gorm:"default:'draft'"` // One of the TenderStatus constants, changed only by its transition endpoints
 	PublishedDate      *time.Time `json:"published_date,omitempty"`   // Date when the tender is made public
 	ClosingDate        *time.Time `json:"closing_date,omitempty"`     // Deadline for bid submissions
 	EvaluationMethod   *string    `json:"evaluation_method,omitempty"`// E.g., 'least_cost', 'quality_cost_based'
 	BidOpeningDate     *time.Time `json:"bid_opening_date,omitempty"` // Date when bids will be opened
+	ClarificationDeadline *time.Time `json:"clarification_deadline,omitempty"` // Last moment for clarification questions; the closing date if unset
+	ClosedAt           *time.Time `json:"closed_at,omitempty"`             // When bidding was closed
+	EvaluationStartedAt *time.Time `json:"evaluation_started_at,omitempty"` // When the bids were opened for evaluation
+	AwardedAt          *time.Time `json:"awarded_at,omitempty"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+	CancellationReason *string    `json:"cancellation_reason,omitempty"`
 	CreatedByUserID    *int64     `json:"created_by_user_id,omitempty"` // User who created the tender
 	CreatedAt          time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt          time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
@@ -27,19 +43,12 @@ type Tender struct {
 	// Has-many relationship: A Tender can have multiple Bids
 	Bids []Bid `json:"bids,omitempty" gorm:"foreignKey:TenderID"`
 
+	// Line items suppliers bid against
+	Items []TenderItem `json:"items,omitempty" gorm:"foreignKey:TenderID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// Weighted criteria the bids are evaluated against
+	EvaluationCriteria []TenderEvaluationCriterion `json:"evaluation_criteria,omitempty" gorm:"foreignKey:TenderID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
 	// Fields to be populated programmatically, not stored in DB
 	BiddersInvitedCount int `json:"bidders_invited_count" gorm:"-"`
 }
-
-// TenderItem might be needed if tenders have their own line items distinct from requisition items
-// For now, we assume the tender refers to the overall requisition or its items implicitly.
-// If a tender can consolidate multiple requisitions or specify items differently,
-// a TenderItem struct and a has-many relationship would be necessary.
-// type TenderItem struct {
-// 	ID          int64   `json:"id" gorm:"primaryKey"`
-// 	TenderID    int64   `json:"tender_id" gorm:"index;not null"`
-// 	Description string  `json:"description" gorm:"not null"`
-// 	Quantity    float64 `json:"quantity"`
-// 	Unit        string  `json:"unit"`
-// 	// ... other fields like estimated cost if different from requisition
-// }
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// TenderAddendum corresponds to the TenderAddendums table.
+// It is a formal, numbered change to a published tender. Suppliers must acknowledge a tender's
+// latest addendum before they can bid on it.
+type TenderAddendum struct {
+	ID         int64                  `json:"id" gorm:"primaryKey"`
+	TenderID   int64                  `json:"tender_id" gorm:"not null;uniqueIndex:idx_tender_addendum_number"`
+	Number     int                    `json:"number" gorm:"not null;uniqueIndex:idx_tender_addendum_number"` // 1 for a tender's first addendum
+	Summary    string                 `json:"summary" gorm:"not null"`                                       // What changed and why, as published to suppliers
+	Changes    []TenderAddendumChange `json:"changes" gorm:"serializer:json"`
+	IssuedByID int64                  `json:"issued_by_id" gorm:"not null"`
+	IssuedAt   time.Time              `json:"issued_at" gorm:"autoCreateTime"`
+
+	// Set for supplier users: whether their organisation has acknowledged the addendum
+	Acknowledged *bool `json:"acknowledged,omitempty" gorm:"-"`
+}
+
+// TenderAddendumChange is one tender field changed by an addendum. Values are nil when the field was empty.
+type TenderAddendumChange struct {
+	Field    string  `json:"field"`
+	OldValue *string `json:"old_value"`
+	NewValue *string `json:"new_value"`
+}
+
+// TenderAddendumAcknowledgement records a supplier organisation acknowledging an addendum.
+type TenderAddendumAcknowledgement struct {
+	AddendumID       int64     `json:"addendum_id" gorm:"primaryKey;autoIncrement:false"`
+	SupplierID       int64     `json:"supplier_id" gorm:"primaryKey;autoIncrement:false"`
+	AcknowledgedByID int64     `json:"acknowledged_by_id" gorm:"not null"` // Supplier user who acknowledged
+	AcknowledgedAt   time.Time `json:"acknowledged_at" gorm:"autoCreateTime"`
+}
+
+// TenderInterest records a supplier organisation registering interest in a published tender, so
+// that it is told about addenda and can follow the tender's clarifications.
+type TenderInterest struct {
+	TenderID       int64     `json:"tender_id" gorm:"primaryKey;autoIncrement:false"`
+	SupplierID     int64     `json:"supplier_id" gorm:"primaryKey;autoIncrement:false"`
+	RegisteredByID int64     `json:"registered_by_id" gorm:"not null"`
+	RegisteredAt   time.Time `json:"registered_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// TenderClarification corresponds to the TenderClarifications table.
+// It is a clarification question a supplier asked about a tender and the procurement officer's
+// answer. Answers are published to every participating supplier without saying who asked.
+type TenderClarification struct {
+	ID                int64      `json:"id" gorm:"primaryKey"`
+	TenderID          int64      `json:"tender_id" gorm:"index;not null"`
+	Question          string     `json:"question" gorm:"not null"`
+	AskedBySupplierID *int64     `json:"asked_by_supplier_id,omitempty" gorm:"index"` // Withheld from suppliers
+	AskedByID         *int64     `json:"asked_by_id,omitempty"`                       // Supplier user who asked; withheld from suppliers
+	AskedAt           time.Time  `json:"asked_at" gorm:"autoCreateTime"`
+	Answer            *string    `json:"answer,omitempty"`
+	AnsweredByID      *int64     `json:"answered_by_id,omitempty"` // Procurement officer who answered; withheld from suppliers
+	AnsweredAt        *time.Time `json:"answered_at,omitempty"`    // When the answer was published
+
+	// Set for supplier users: whether their organisation asked the question
+	Own *bool `json:"own,omitempty" gorm:"-"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// TenderItem represents a line item of a tender that suppliers price in their bids.
+// Items are usually seeded from the tender's requisition but can be edited while the tender is a draft.
+type TenderItem struct {
+	ID                 int64     `json:"id" gorm:"primaryKey"`
+	TenderID           int64     `json:"tender_id" gorm:"index;not null"`            // Foreign key to the Tender
+	RequisitionItemID  *int64    `json:"requisition_item_id,omitempty" gorm:"index"` // Originating RequisitionItem, if any
+	Description        string    `json:"description" gorm:"not null"`
+	Quantity           float64   `json:"quantity" gorm:"not null"`
+	Unit               string    `json:"unit" gorm:"not null"`
+	EstimatedUnitPrice *float64  `json:"estimated_unit_price,omitempty"`
+	SpecificationText  *string   `json:"specification_text,omitempty"`
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TenderSealingKey is the key pair the bids of a tender are sealed with. Bid contents are
+// encrypted to the public key when submitted; the private key is stored wrapped by the server's
+// master key and is only unwrapped when the evaluation panel opens the bids.
+type TenderSealingKey struct {
+	TenderID          int64      `json:"tender_id" gorm:"primaryKey;autoIncrement:false"`
+	PublicKey         []byte     `json:"-" gorm:"not null"`
+	WrappedPrivateKey []byte     `json:"-" gorm:"not null"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	ReleasedAt        *time.Time `json:"released_at,omitempty"` // When the bids were opened
+}
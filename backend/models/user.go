@@ -14,6 +14,7 @@ type User struct {
 	Department    *string   `json:"department,omitempty" gorm:"column:department"`
 	ContactNumber *string   `json:"contact_number,omitempty" gorm:"column:contactNumber"`
 	IsActive      bool      `json:"is_active" gorm:"column:isActive;default:true"`
+	SupplierID    *int64    `json:"supplier_id,omitempty" gorm:"column:supplier_id;index"` // Supplier organisation a supplier user acts for
 	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// sealedFormatV1 prefixes content sealed by X25519BidSealingService.
+const sealedFormatV1 byte = 1
+
+// BidSealingService seals bid contents to a per-tender key pair. Anyone can seal to the public
+// key, but opening needs the private key, which is only ever stored wrapped by a master key.
+type BidSealingService interface {
+	// NewKeyPair creates a tender key pair and returns the public key and the wrapped private key.
+	NewKeyPair() (publicKey, wrappedPrivateKey []byte, err error)
+	// Seal encrypts plaintext to a tender public key.
+	Seal(publicKey, plaintext []byte) ([]byte, error)
+	// Open unwraps a tender private key and decrypts content sealed to its public key.
+	Open(wrappedPrivateKey, sealed []byte) ([]byte, error)
+}
+
+// X25519BidSealingService seals with an ephemeral X25519 key agreement and AES-256-GCM, and
+// wraps tender private keys with AES-256-GCM under the master key.
+type X25519BidSealingService struct {
+	masterKey []byte
+}
+
+// NewX25519BidSealingService creates an X25519BidSealingService.
+// It uses BID_SEALING_KEY (32 bytes, base64) as the master key, which is required. Only when
+// APP_ENV=development may it be left out, and a master key is then derived from JWT_SECRET_KEY;
+// such a key is as weak as that secret, so it must never protect real bids.
+func NewX25519BidSealingService() (*X25519BidSealingService, error) {
+	if encoded := os.Getenv("BID_SEALING_KEY"); encoded != "" {
+		masterKey, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(masterKey) != 32 {
+			return nil, errors.New("BID_SEALING_KEY must be 32 bytes encoded as base64")
+		}
+		return &X25519BidSealingService{masterKey: masterKey}, nil
+	}
+
+	if !strings.EqualFold(os.Getenv("APP_ENV"), "development") {
+		return nil, errors.New("BID_SEALING_KEY environment variable is not set (generate one with `openssl rand -base64 32`, or set APP_ENV=development to use a development key)")
+	}
+	secret := os.Getenv("JWT_SECRET_KEY")
+	if secret == "" {
+		return nil, errors.New("neither BID_SEALING_KEY nor JWT_SECRET_KEY environment variable is set")
+	}
+	log.Println("WARNING: BID_SEALING_KEY environment variable not set. Deriving a development bid sealing key from JWT_SECRET_KEY; sealed bids are not protected.")
+	return newDerivedBidSealingService(secret)
+}
+
+// newDerivedBidSealingService creates an X25519BidSealingService whose master key is derived
+// from secret.
+func newDerivedBidSealingService(secret string) (*X25519BidSealingService, error) {
+	masterKey, err := hkdf.Key(sha256.New, []byte(secret), nil, "procurement bid sealing master key", 32)
+	if err != nil {
+		return nil, err
+	}
+	return &X25519BidSealingService{masterKey: masterKey}, nil
+}
+
+// gcmSeal encrypts plaintext with AES-256-GCM under key, returning nonce || ciphertext.
+func gcmSeal(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// gcmOpen reverses gcmSeal.
+func gcmOpen(key, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("sealed content is truncated")
+	}
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+}
+
+// contentKey derives the AES key for one sealed item from the X25519 shared secret, bound to
+// both public keys.
+func contentKey(shared, ephemeralPublic, tenderPublic []byte) ([]byte, error) {
+	salt := append(append([]byte{}, ephemeralPublic...), tenderPublic...)
+	return hkdf.Key(sha256.New, shared, salt, "procurement bid sealing v1", 32)
+}
+
+// NewKeyPair creates an X25519 key pair for a tender.
+func (s *X25519BidSealingService) NewKeyPair() ([]byte, []byte, error) {
+	privateKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKey := privateKey.PublicKey().Bytes()
+	wrapped, err := gcmSeal(s.masterKey, privateKey.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return publicKey, wrapped, nil
+}
+
+// Seal encrypts plaintext to the tender public key. The result is
+// version || ephemeral public key || nonce || ciphertext.
+func (s *X25519BidSealingService) Seal(publicKey, plaintext []byte) ([]byte, error) {
+	tenderPublic, err := ecdh.X25519().NewPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tender public key: %w", err)
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := ephemeral.ECDH(tenderPublic)
+	if err != nil {
+		return nil, err
+	}
+	ephemeralPublic := ephemeral.PublicKey().Bytes()
+	key, err := contentKey(shared, ephemeralPublic, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := gcmSeal(key, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	sealed := make([]byte, 0, 1+len(ephemeralPublic)+len(ciphertext))
+	sealed = append(sealed, sealedFormatV1)
+	sealed = append(sealed, ephemeralPublic...)
+	return append(sealed, ciphertext...), nil
+}
+
+// Open unwraps the tender private key and decrypts content produced by Seal.
+func (s *X25519BidSealingService) Open(wrappedPrivateKey, sealed []byte) ([]byte, error) {
+	const publicKeySize = 32
+	if len(sealed) < 1+publicKeySize || sealed[0] != sealedFormatV1 {
+		return nil, errors.New("unrecognised sealed content")
+	}
+	privateBytes, err := gcmOpen(s.masterKey, wrappedPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap tender key: %w", err)
+	}
+	privateKey, err := ecdh.X25519().NewPrivateKey(privateBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralPublic := sealed[1 : 1+publicKeySize]
+	peer, err := ecdh.X25519().NewPublicKey(ephemeralPublic)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := privateKey.ECDH(peer)
+	if err != nil {
+		return nil, err
+	}
+	key, err := contentKey(shared, ephemeralPublic, privateKey.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return gcmOpen(key, sealed[1+publicKeySize:])
+}
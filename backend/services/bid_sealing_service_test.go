@@ -0,0 +1,159 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func newTestSealer(t *testing.T, masterKey byte) *X25519BidSealingService {
+	t.Helper()
+	t.Setenv("BID_SEALING_KEY", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{masterKey}, 32)))
+	sealer, err := NewX25519BidSealingService()
+	if err != nil {
+		t.Fatalf("NewX25519BidSealingService: %v", err)
+	}
+	return sealer
+}
+
+func TestBidSealingRoundTrip(t *testing.T) {
+	sealer := newTestSealer(t, 1)
+	publicKey, wrapped, err := sealer.NewKeyPair()
+	if err != nil {
+		t.Fatalf("NewKeyPair: %v", err)
+	}
+
+	for _, plaintext := range [][]byte{[]byte(`{"total_price":125000}`), {}} {
+		sealed, err := sealer.Seal(publicKey, plaintext)
+		if err != nil {
+			t.Fatalf("Seal: %v", err)
+		}
+		if len(plaintext) > 0 && bytes.Contains(sealed, plaintext) {
+			t.Error("sealed content contains the plaintext")
+		}
+		opened, err := sealer.Open(wrapped, sealed)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Errorf("opened %q, want %q", opened, plaintext)
+		}
+	}
+
+	first, _ := sealer.Seal(publicKey, []byte("same"))
+	second, _ := sealer.Seal(publicKey, []byte("same"))
+	if bytes.Equal(first, second) {
+		t.Error("sealing the same plaintext twice gave the same output")
+	}
+}
+
+func TestBidSealingRejectsAnotherTendersKey(t *testing.T) {
+	sealer := newTestSealer(t, 1)
+	publicKey, _, err := sealer.NewKeyPair()
+	if err != nil {
+		t.Fatalf("NewKeyPair: %v", err)
+	}
+	_, otherWrapped, err := sealer.NewKeyPair()
+	if err != nil {
+		t.Fatalf("NewKeyPair: %v", err)
+	}
+	sealed, err := sealer.Seal(publicKey, []byte("bid for tender one"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if _, err := sealer.Open(otherWrapped, sealed); err == nil {
+		t.Error("another tender's key opened the bid")
+	}
+}
+
+func TestBidSealingRejectsTampering(t *testing.T) {
+	sealer := newTestSealer(t, 1)
+	publicKey, wrapped, err := sealer.NewKeyPair()
+	if err != nil {
+		t.Fatalf("NewKeyPair: %v", err)
+	}
+	sealed, err := sealer.Seal(publicKey, []byte("bid content"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		offset int
+	}{
+		{"version", 0},
+		{"ephemeral public key", 5},
+		{"nonce", 1 + 32 + 2},
+		{"ciphertext", len(sealed) - 20},
+		{"tag", len(sealed) - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := append([]byte{}, sealed...)
+			tampered[tt.offset] ^= 0x01
+			if _, err := sealer.Open(wrapped, tampered); err == nil {
+				t.Error("tampered content was opened")
+			}
+		})
+	}
+
+	if _, err := sealer.Open(wrapped, sealed[:len(sealed)-1]); err == nil {
+		t.Error("truncated content was opened")
+	}
+	if _, err := sealer.Open(wrapped, sealed[:20]); err == nil {
+		t.Error("content shorter than the header was opened")
+	}
+
+	tamperedWrapped := append([]byte{}, wrapped...)
+	tamperedWrapped[len(tamperedWrapped)-1] ^= 0x01
+	if _, err := sealer.Open(tamperedWrapped, sealed); err == nil {
+		t.Error("a tampered wrapped key was unwrapped")
+	}
+}
+
+func TestBidSealingRejectsAnotherMasterKey(t *testing.T) {
+	sealer := newTestSealer(t, 1)
+	publicKey, wrapped, err := sealer.NewKeyPair()
+	if err != nil {
+		t.Fatalf("NewKeyPair: %v", err)
+	}
+	sealed, err := sealer.Seal(publicKey, []byte("bid content"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if _, err := newTestSealer(t, 2).Open(wrapped, sealed); err == nil {
+		t.Error("another master key unwrapped the tender key")
+	}
+}
+
+func TestNewX25519BidSealingServiceConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		sealKey   string
+		appEnv    string
+		jwtSecret string
+		wantErr   bool
+	}{
+		{"valid key", base64.StdEncoding.EncodeToString(make([]byte, 32)), "", "", false},
+		{"key not base64", "not base64!", "", "", true},
+		{"key too short", base64.StdEncoding.EncodeToString(make([]byte, 16)), "", "", true},
+		{"no key outside development", "", "", "secret", true},
+		{"no key in production", "", "production", "secret", true},
+		{"no key in development", "", "development", "secret", false},
+		{"no key or secret in development", "", "development", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BID_SEALING_KEY", tt.sealKey)
+			t.Setenv("APP_ENV", tt.appEnv)
+			t.Setenv("JWT_SECRET_KEY", tt.jwtSecret)
+			sealer, err := NewX25519BidSealingService()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && sealer == nil {
+				t.Fatal("no service returned")
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrBlobNotFound is returned when nothing is stored under a key.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps uploaded files as objects under opaque, slash-separated keys such as
+// "attachments/bid/12/9f2c…_spec.pdf". The database only records keys, never filesystem paths,
+// so every backend replica can serve every file when the store is shared.
+type BlobStore interface {
+	// Put stores size bytes read from body under key, replacing any object already there.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Open returns the object under key. It can seek, so it can be served with http.ServeContent.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes the object under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// validBlobKey reports whether key is a relative, slash-separated key without empty, "." or ".."
+// segments, so no store can be tricked into reaching outside its root or bucket.
+func validBlobKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// LocalBlobStore stores objects as files under a root directory. It suits a single backend
+// instance, or several sharing the directory over a network filesystem.
+type LocalBlobStore struct {
+	Root string
+}
+
+// NewLocalBlobStore creates a LocalBlobStore rooted at root.
+func NewLocalBlobStore(root string) *LocalBlobStore {
+	return &LocalBlobStore{Root: root}
+}
+
+// filePath maps a key to its file under the root.
+func (s *LocalBlobStore) filePath(key string) (string, error) {
+	if !validBlobKey(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the object to a temporary file first and renames it into place, so readers never
+// see a partly written file.
+func (s *LocalBlobStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directory for %q: %w", key, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file for %q: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %q: %w", key, err)
+	}
+	if written != size {
+		return fmt.Errorf("failed to write %q: got %d bytes, expected %d", key, written, size)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to store %q: %w", key, err)
+	}
+	return nil
+}
+
+// Open opens the object's file.
+func (s *LocalBlobStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Delete removes the object's file.
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// GetBlobStore returns the blob store selected by BLOB_STORE: "local" (the default) keeps objects
+// under BLOB_LOCAL_DIR, ./uploads unless set, and "s3" keeps them in an S3-compatible bucket
+// configured as described on NewS3BlobStore.
+func GetBlobStore() (BlobStore, error) {
+	switch kind := strings.ToLower(strings.TrimSpace(os.Getenv("BLOB_STORE"))); kind {
+	case "", "local":
+		root := os.Getenv("BLOB_LOCAL_DIR")
+		if root == "" {
+			root = "./uploads"
+		}
+		return NewLocalBlobStore(root), nil
+	case "s3":
+		return NewS3BlobStore()
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q, expected local or s3", kind)
+	}
+}
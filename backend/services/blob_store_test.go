@@ -0,0 +1,93 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalBlobStoreRoundTrip(t *testing.T) {
+	store := NewLocalBlobStore(t.TempDir())
+	ctx := context.Background()
+	content := []byte("specification")
+
+	if err := store.Put(ctx, "attachments/bid/1/spec.pdf", bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	object, err := store.Open(ctx, "attachments/bid/1/spec.pdf")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	got, err := io.ReadAll(object)
+	object.Close()
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("read %q, %v", got, err)
+	}
+
+	if err := store.Put(ctx, "short.txt", strings.NewReader("abc"), 5, ""); err == nil {
+		t.Error("Put accepted a body shorter than its size")
+	}
+	if _, err := store.Open(ctx, "short.txt"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("a failed Put left an object behind: %v", err)
+	}
+
+	if err := store.Delete(ctx, "attachments/bid/1/spec.pdf"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Open(ctx, "attachments/bid/1/spec.pdf"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Open after delete: %v, want ErrBlobNotFound", err)
+	}
+	if err := store.Delete(ctx, "attachments/bid/1/spec.pdf"); err != nil {
+		t.Errorf("deleting a missing object: %v", err)
+	}
+}
+
+func TestLocalBlobStoreRejectsPathTraversal(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "uploads")
+	store := NewLocalBlobStore(root)
+	ctx := context.Background()
+
+	outside := filepath.Join(parent, "secret.txt")
+	if err := os.WriteFile(outside, []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []string{
+		"",
+		"../secret.txt",
+		"a/../../secret.txt",
+		"./secret.txt",
+		"/etc/passwd",
+		"a//b",
+		`..\secret.txt`,
+		"a/..",
+	}
+	for _, key := range keys {
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("Put accepted key %q", key)
+		}
+		if object, err := store.Open(ctx, key); err == nil {
+			object.Close()
+			t.Errorf("Open accepted key %q", key)
+		}
+		if err := store.Delete(ctx, key); err == nil {
+			t.Errorf("Delete accepted key %q", key)
+		}
+	}
+
+	if data, err := os.ReadFile(outside); err != nil || string(data) != "keep" {
+		t.Errorf("file outside the root was touched: %q, %v", data, err)
+	}
+	entries, _ := os.ReadDir(parent)
+	for _, entry := range entries {
+		if entry.Name() != "secret.txt" && entry.Name() != "uploads" {
+			t.Errorf("unexpected file %q created outside the root", entry.Name())
+		}
+	}
+}
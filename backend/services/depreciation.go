@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"procurement/models"
+)
+
+// maxDepreciationMonths bounds a reducing-balance schedule that has no useful life.
+const maxDepreciationMonths = 100 * 12
+
+// DepreciationPeriod is one month of a depreciation schedule.
+type DepreciationPeriod struct {
+	Period                  string    `json:"period"` // YYYY-MM
+	PeriodEnd               time.Time `json:"period_end"`
+	OpeningValue            float64   `json:"opening_value"`
+	Depreciation            float64   `json:"depreciation"`
+	AccumulatedDepreciation float64   `json:"accumulated_depreciation"`
+	ClosingValue            float64   `json:"closing_value"`
+}
+
+// round2 rounds an amount to two decimal places.
+func round2(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// DepreciationSchedule builds a month-by-month schedule for an asset costing cost.
+// A full month's charge is taken in the month of acquisition (start) and each charge falls on
+// the last day of its month.
+//
+// Straight-line spreads the cost evenly over usefulLifeYears, the final month absorbing rounding
+// so the asset is written down to zero. Reducing-balance charges annualRatePercent of the value
+// at the start of each year of the asset's life (counted from the month of acquisition), spread
+// evenly over that year's 12 months, so a 20% rate writes off exactly 20% a year; it runs for the
+// useful life when one is given, otherwise until the value falls below 1% of cost. No charge ever
+// takes the value below zero.
+func DepreciationSchedule(method string, cost float64, usefulLifeYears *int, annualRatePercent *float64, start time.Time) ([]DepreciationPeriod, error) {
+	if cost <= 0 {
+		return nil, errors.New("asset has no capitalized value or purchase price to depreciate")
+	}
+
+	months := maxDepreciationMonths
+	if usefulLifeYears != nil {
+		if *usefulLifeYears <= 0 {
+			return nil, errors.New("useful life must be a positive number of years")
+		}
+		months = *usefulLifeYears * 12
+	}
+
+	var monthlyCharge func(month int, opening float64) float64
+	switch method {
+	case models.DepreciationMethodStraightLine:
+		if usefulLifeYears == nil {
+			return nil, errors.New("straight-line depreciation needs a useful life")
+		}
+		straight := round2(cost / float64(months))
+		monthlyCharge = func(month int, opening float64) float64 {
+			if month == months-1 {
+				return opening
+			}
+			return math.Min(straight, opening)
+		}
+	case models.DepreciationMethodReducingBalance:
+		if annualRatePercent == nil || *annualRatePercent <= 0 || *annualRatePercent > 100 {
+			return nil, errors.New("reducing-balance depreciation needs an annual rate between 0 and 100 percent")
+		}
+		rate := *annualRatePercent / 100
+		var yearOpening, annualCharge float64
+		monthlyCharge = func(month int, opening float64) float64 {
+			if month%12 == 0 {
+				yearOpening = opening
+				annualCharge = round2(opening * rate)
+			}
+			charge := round2(annualCharge / 12)
+			if month%12 == 11 {
+				// The last month of the year takes the rounding, so the year adds up to its charge
+				charge = round2(annualCharge - (yearOpening - opening))
+			}
+			return math.Max(0, math.Min(charge, opening))
+		}
+	default:
+		return nil, errors.New("asset has no supported depreciation method")
+	}
+
+	firstMonth := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	schedule := make([]DepreciationPeriod, 0, months)
+	value := round2(cost)
+	accumulated := 0.0
+	for month := 0; month < months && value > 0; month++ {
+		if usefulLifeYears == nil && value < cost*0.01 {
+			break
+		}
+		charge := monthlyCharge(month, value)
+		periodStart := firstMonth.AddDate(0, month, 0)
+		accumulated = round2(accumulated + charge)
+		schedule = append(schedule, DepreciationPeriod{
+			Period:                  periodStart.Format("2006-01"),
+			PeriodEnd:               periodStart.AddDate(0, 1, -1),
+			OpeningValue:            value,
+			Depreciation:            charge,
+			AccumulatedDepreciation: accumulated,
+			ClosingValue:            round2(value - charge),
+		})
+		value = round2(value - charge)
+	}
+	return schedule, nil
+}
+
+// NetBookValueAt returns the accumulated depreciation and net book value of an asset on asOf,
+// counting every charge whose period has ended by then.
+func NetBookValueAt(schedule []DepreciationPeriod, cost float64, asOf time.Time) (accumulated, netBookValue float64) {
+	netBookValue = round2(cost)
+	for _, period := range schedule {
+		if period.PeriodEnd.After(asOf) {
+			break
+		}
+		accumulated = period.AccumulatedDepreciation
+		netBookValue = period.ClosingValue
+	}
+	return accumulated, netBookValue
+}
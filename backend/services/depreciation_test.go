@@ -0,0 +1,189 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"procurement/models"
+)
+
+func intPtr(v int) *int                         { return &v }
+func floatPtr(v float64) *float64               { return &v }
+func date(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+// yearTotal adds up the charges of the given year of an asset's life (0 for the first).
+func yearTotal(schedule []DepreciationPeriod, year int) float64 {
+	total := 0.0
+	for _, period := range schedule[year*12 : (year+1)*12] {
+		total += period.Depreciation
+	}
+	return round2(total)
+}
+
+func TestDepreciationSchedule(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		cost        float64
+		life        *int
+		rate        *float64
+		start       time.Time
+		periods     int
+		firstPeriod string
+		yearTotals  []float64 // Charges of each full year of the asset's life, in order
+		finalValue  float64
+	}{
+		{
+			name:        "straight line over one year",
+			method:      models.DepreciationMethodStraightLine,
+			cost:        1200,
+			life:        intPtr(1),
+			start:       date(2024, time.March, 15),
+			periods:     12,
+			firstPeriod: "2024-03",
+			yearTotals:  []float64{1200},
+			finalValue:  0,
+		},
+		{
+			name:        "straight line final month absorbs rounding",
+			method:      models.DepreciationMethodStraightLine,
+			cost:        1000,
+			life:        intPtr(3),
+			start:       date(2024, time.January, 1),
+			periods:     36,
+			firstPeriod: "2024-01",
+			yearTotals:  []float64{333.36, 333.36, 333.28},
+			finalValue:  0,
+		},
+		{
+			name:        "reducing balance writes off the stated rate each year",
+			method:      models.DepreciationMethodReducingBalance,
+			cost:        10000,
+			life:        intPtr(3),
+			rate:        floatPtr(20),
+			start:       date(2024, time.January, 1),
+			periods:     36,
+			firstPeriod: "2024-01",
+			yearTotals:  []float64{2000, 1600, 1280},
+			finalValue:  5120,
+		},
+		{
+			name:        "reducing balance with a partial first calendar year",
+			method:      models.DepreciationMethodReducingBalance,
+			cost:        10000,
+			life:        intPtr(2),
+			rate:        floatPtr(20),
+			start:       date(2024, time.July, 10),
+			periods:     24,
+			firstPeriod: "2024-07",
+			yearTotals:  []float64{2000, 1600},
+			finalValue:  6400,
+		},
+		{
+			name:        "reducing balance at 100% stops at zero",
+			method:      models.DepreciationMethodReducingBalance,
+			cost:        5000,
+			life:        intPtr(3),
+			rate:        floatPtr(100),
+			start:       date(2024, time.January, 1),
+			periods:     12,
+			firstPeriod: "2024-01",
+			yearTotals:  []float64{5000},
+			finalValue:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := DepreciationSchedule(tt.method, tt.cost, tt.life, tt.rate, tt.start)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(schedule) != tt.periods {
+				t.Fatalf("got %d periods, want %d", len(schedule), tt.periods)
+			}
+			if schedule[0].Period != tt.firstPeriod {
+				t.Errorf("first period %s, want %s", schedule[0].Period, tt.firstPeriod)
+			}
+			for year, want := range tt.yearTotals {
+				if got := yearTotal(schedule, year); got != want {
+					t.Errorf("year %d charges %.2f, want %.2f", year+1, got, want)
+				}
+			}
+			last := schedule[len(schedule)-1]
+			if last.ClosingValue != tt.finalValue {
+				t.Errorf("final value %.2f, want %.2f", last.ClosingValue, tt.finalValue)
+			}
+			if want := round2(tt.cost - tt.finalValue); last.AccumulatedDepreciation != want {
+				t.Errorf("accumulated depreciation %.2f, want %.2f", last.AccumulatedDepreciation, want)
+			}
+			for _, period := range schedule {
+				if period.Depreciation < 0 || period.ClosingValue < 0 {
+					t.Fatalf("period %s goes below zero: %+v", period.Period, period)
+				}
+			}
+		})
+	}
+}
+
+func TestDepreciationScheduleWithoutUsefulLifeStopsNearZero(t *testing.T) {
+	schedule, err := DepreciationSchedule(models.DepreciationMethodReducingBalance, 1000, nil, floatPtr(50), date(2024, time.January, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := schedule[len(schedule)-1]
+	if last.OpeningValue < 10 {
+		t.Errorf("schedule continued below 1%% of cost: last opening value %.2f", last.OpeningValue)
+	}
+	if last.ClosingValue <= 0 || last.ClosingValue >= 1000 {
+		t.Errorf("unexpected final value %.2f", last.ClosingValue)
+	}
+}
+
+func TestDepreciationScheduleErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		cost   float64
+		life   *int
+		rate   *float64
+	}{
+		{"no cost", models.DepreciationMethodStraightLine, 0, intPtr(3), nil},
+		{"straight line without useful life", models.DepreciationMethodStraightLine, 1000, nil, nil},
+		{"non-positive useful life", models.DepreciationMethodStraightLine, 1000, intPtr(0), nil},
+		{"reducing balance without rate", models.DepreciationMethodReducingBalance, 1000, intPtr(3), nil},
+		{"reducing balance rate above 100", models.DepreciationMethodReducingBalance, 1000, intPtr(3), floatPtr(120)},
+		{"unknown method", "sum_of_digits", 1000, intPtr(3), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DepreciationSchedule(tt.method, tt.cost, tt.life, tt.rate, date(2024, time.January, 1)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestNetBookValueAt(t *testing.T) {
+	schedule, err := DepreciationSchedule(models.DepreciationMethodReducingBalance, 10000, intPtr(2), floatPtr(20), date(2024, time.July, 10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		asOf        time.Time
+		accumulated float64
+		netBook     float64
+	}{
+		{date(2024, time.July, 30), 0, 10000},             // First charge falls on the last day of the month
+		{date(2024, time.December, 31), 1000.02, 8999.98}, // Six months of 166.67
+		{date(2025, time.June, 30), 2000, 8000},
+		{date(2030, time.January, 1), 3600, 6400},
+	}
+	for _, tt := range tests {
+		accumulated, netBook := NetBookValueAt(schedule, 10000, tt.asOf)
+		if math.Abs(accumulated-tt.accumulated) > 0.001 || math.Abs(netBook-tt.netBook) > 0.001 {
+			t.Errorf("as of %s: got %.2f / %.2f, want %.2f / %.2f", tt.asOf.Format("2006-01-02"), accumulated, netBook, tt.accumulated, tt.netBook)
+		}
+	}
+}
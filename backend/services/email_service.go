@@ -11,6 +11,9 @@ import (
 // EmailService defines the interface for email-related operations
 type EmailService interface {
 	SendPasswordResetEmail(to string, resetLink string) error
+	SendTenderAddendumEmail(to string, tenderTitle string, addendumNumber int, summary string) error
+	SendTenderClarificationEmail(to string, tenderTitle string, question string, answer string) error
+	SendBidOpeningDueEmail(to string, tenderTitle string, quorum int) error
 }
 
 // SMTPEmailService implements EmailService using SMTP
@@ -90,6 +93,123 @@ The Procurement Team
 	return smtp.SendMail(addr, auth, s.from, []string{to}, body.Bytes())
 }
 
+// SendTenderAddendumEmail tells a supplier that an addendum was issued for a tender
+func (s *SMTPEmailService) SendTenderAddendumEmail(to string, tenderTitle string, addendumNumber int, summary string) error {
+	const emailTemplate = `
+Subject: Addendum {{.Number}} to tender "{{.TenderTitle}}"
+
+Hello,
+
+Addendum {{.Number}} has been issued for the tender "{{.TenderTitle}}":
+
+{{.Summary}}
+
+Please review the addendum and acknowledge it before submitting your bid.
+
+Best regards,
+The Procurement Team
+`
+
+	tmpl, err := template.New("addendumEmail").Parse(emailTemplate)
+	if err != nil {
+		return err
+	}
+	data := struct {
+		TenderTitle string
+		Number      int
+		Summary     string
+	}{
+		TenderTitle: tenderTitle,
+		Number:      addendumNumber,
+		Summary:     summary,
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return err
+	}
+
+	auth := smtp.PlainAuth("", s.username, s.password, s.host)
+	addr := fmt.Sprintf("%s:%s", s.host, s.port)
+	return smtp.SendMail(addr, auth, s.from, []string{to}, body.Bytes())
+}
+
+// SendTenderClarificationEmail tells a supplier that a clarification was published for a tender
+func (s *SMTPEmailService) SendTenderClarificationEmail(to string, tenderTitle string, question string, answer string) error {
+	const emailTemplate = `
+Subject: Clarification published for tender "{{.TenderTitle}}"
+
+Hello,
+
+A clarification has been published for the tender "{{.TenderTitle}}".
+
+Question:
+{{.Question}}
+
+Answer:
+{{.Answer}}
+
+Best regards,
+The Procurement Team
+`
+
+	tmpl, err := template.New("clarificationEmail").Parse(emailTemplate)
+	if err != nil {
+		return err
+	}
+	data := struct {
+		TenderTitle string
+		Question    string
+		Answer      string
+	}{
+		TenderTitle: tenderTitle,
+		Question:    question,
+		Answer:      answer,
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return err
+	}
+
+	auth := smtp.PlainAuth("", s.username, s.password, s.host)
+	addr := fmt.Sprintf("%s:%s", s.host, s.port)
+	return smtp.SendMail(addr, auth, s.from, []string{to}, body.Bytes())
+}
+
+// SendBidOpeningDueEmail asks an evaluation panel member to join the opening of a tender's bids
+func (s *SMTPEmailService) SendBidOpeningDueEmail(to string, tenderTitle string, quorum int) error {
+	const emailTemplate = `
+Subject: Bid opening due for tender "{{.TenderTitle}}"
+
+Hello,
+
+The bids for the tender "{{.TenderTitle}}" are due to be opened. As a member of its evaluation
+panel, please join the bid opening. The bids are unsealed once {{.Quorum}} panel members have joined.
+
+Best regards,
+The Procurement Team
+`
+
+	tmpl, err := template.New("bidOpeningEmail").Parse(emailTemplate)
+	if err != nil {
+		return err
+	}
+	data := struct {
+		TenderTitle string
+		Quorum      int
+	}{
+		TenderTitle: tenderTitle,
+		Quorum:      quorum,
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return err
+	}
+
+	auth := smtp.PlainAuth("", s.username, s.password, s.host)
+	addr := fmt.Sprintf("%s:%s", s.host, s.port)
+	return smtp.SendMail(addr, auth, s.from, []string{to}, body.Bytes())
+}
+
 // MockEmailService is a mock implementation of EmailService for testing or development
 type MockEmailService struct {
 	LogEmails bool
@@ -110,6 +230,30 @@ func (s *MockEmailService) SendPasswordResetEmail(to string, resetLink string) e
 	return nil
 }
 
+// SendTenderAddendumEmail logs the email instead of sending it
+func (s *MockEmailService) SendTenderAddendumEmail(to string, tenderTitle string, addendumNumber int, summary string) error {
+	if s.LogEmails {
+		fmt.Printf("Mock email sent to %s about addendum %d to tender %q\n", to, addendumNumber, tenderTitle)
+	}
+	return nil
+}
+
+// SendTenderClarificationEmail logs the email instead of sending it
+func (s *MockEmailService) SendTenderClarificationEmail(to string, tenderTitle string, question string, answer string) error {
+	if s.LogEmails {
+		fmt.Printf("Mock email sent to %s about a clarification to tender %q\n", to, tenderTitle)
+	}
+	return nil
+}
+
+// SendBidOpeningDueEmail logs the email instead of sending it
+func (s *MockEmailService) SendBidOpeningDueEmail(to string, tenderTitle string, quorum int) error {
+	if s.LogEmails {
+		fmt.Printf("Mock email sent to %s about the bid opening of tender %q\n", to, tenderTitle)
+	}
+	return nil
+}
+
 // GetEmailService returns the appropriate email service based on environment
 func GetEmailService() (EmailService, error) {
 	// Always use mock service for now
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testAccessKeyID     = "test-access-key"
+	testSecretAccessKey = "test-secret-key"
+	testBucket          = "procurement"
+	testRegion          = "eu-west-1"
+)
+
+// fakeS3 is a minimal stand-in for an S3-compatible service such as MinIO. It checks the
+// Signature Version 4 of every request independently of the client and keeps objects in memory.
+type fakeS3 struct {
+	mu          sync.Mutex
+	objects     map[string][]byte
+	types       map[string]string
+	ranges      []string // Range headers of the GET requests, in order
+	ignoreRange bool     // Answer ranged GETs with the whole object, as some servers do
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+}
+
+// verifySignature rebuilds the canonical request from what arrived and checks the signature.
+func verifySignature(r *http.Request) error {
+	auth := r.Header.Get("Authorization")
+	const prefix = "AWS4-HMAC-SHA256 "
+	if !strings.HasPrefix(auth, prefix) {
+		return fmt.Errorf("unexpected authorization %q", auth)
+	}
+	fields := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(auth, prefix), ", ") {
+		name, value, _ := strings.Cut(part, "=")
+		fields[name] = value
+	}
+	amzDate := r.Header.Get("X-Amz-Date")
+	if len(amzDate) != len("20060102T150405Z") {
+		return fmt.Errorf("bad x-amz-date %q", amzDate)
+	}
+	day := amzDate[:8]
+	scope := day + "/" + testRegion + "/s3/aws4_request"
+	if fields["Credential"] != testAccessKeyID+"/"+scope {
+		return fmt.Errorf("bad credential %q", fields["Credential"])
+	}
+	if fields["SignedHeaders"] != "host;x-amz-content-sha256;x-amz-date" {
+		return fmt.Errorf("bad signed headers %q", fields["SignedHeaders"])
+	}
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	switch {
+	case r.Method == http.MethodPut && payloadHash != "UNSIGNED-PAYLOAD":
+		return fmt.Errorf("PUT payload hash %q, want UNSIGNED-PAYLOAD", payloadHash)
+	case r.Method != http.MethodPut && payloadHash != emptyPayloadHash:
+		return fmt.Errorf("%s payload hash %q, want the empty hash", r.Method, payloadHash)
+	}
+
+	canonical := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), r.URL.RawQuery,
+		"host:" + r.Host, "x-amz-content-sha256:" + payloadHash, "x-amz-date:" + amzDate, "",
+		fields["SignedHeaders"], payloadHash,
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonical))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+	key := hmacSHA256([]byte("AWS4"+testSecretAccessKey), day)
+	for _, part := range []string{testRegion, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	if want := hex.EncodeToString(hmacSHA256(key, stringToSign)); fields["Signature"] != want {
+		return errors.New("signature does not match")
+	}
+	return nil
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := verifySignature(r); err != nil {
+		http.Error(w, "SignatureDoesNotMatch: "+err.Error(), http.StatusForbidden)
+		return
+	}
+	bucketPrefix := "/" + testBucket + "/"
+	if !strings.HasPrefix(r.URL.Path, bucketPrefix) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, bucketPrefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, exists := f.objects[key]
+	switch r.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(body)) != r.ContentLength {
+			http.Error(w, "IncompleteBody", http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+	case http.MethodGet:
+		if !exists {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		f.ranges = append(f.ranges, r.Header.Get("Range"))
+		var start int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start); err == nil && !f.ignoreRange {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(data[start:])
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// newTestS3Store starts a fake S3 server and returns a path-style store pointing at it.
+func newTestS3Store(t *testing.T, secret string) (*S3BlobStore, *fakeS3) {
+	t.Helper()
+	fake := newFakeS3()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &S3BlobStore{
+		Endpoint:        endpoint,
+		Bucket:          testBucket,
+		Region:          testRegion,
+		AccessKeyID:     testAccessKeyID,
+		SecretAccessKey: secret,
+		PathStyle:       true,
+		Client:          server.Client(),
+	}, fake
+}
+
+func TestS3BlobStoreRoundTrip(t *testing.T) {
+	store, fake := newTestS3Store(t, testSecretAccessKey)
+	ctx := context.Background()
+	key := "attachments/bid/12/9f2c_spec sheet (v2).pdf"
+	content := []byte("0123456789abcdefghij")
+
+	if err := store.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := fake.types[key]; got != "application/pdf" {
+		t.Errorf("stored content type %q", got)
+	}
+
+	object, err := store.Open(ctx, key)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer object.Close()
+	all, err := io.ReadAll(object)
+	if err != nil || !bytes.Equal(all, content) {
+		t.Fatalf("read %q, %v; want %q", all, err, content)
+	}
+
+	if pos, err := object.Seek(-5, io.SeekEnd); err != nil || pos != 15 {
+		t.Fatalf("Seek: %d, %v", pos, err)
+	}
+	tail, err := io.ReadAll(object)
+	if err != nil || string(tail) != "fghij" {
+		t.Fatalf("read after seek %q, %v", tail, err)
+	}
+	if want := []string{"bytes=0-", "bytes=15-"}; strings.Join(fake.ranges, ",") != strings.Join(want, ",") {
+		t.Errorf("range requests %q, want %q", fake.ranges, want)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Open(ctx, key); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Open after delete: %v, want ErrBlobNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("deleting a missing object: %v", err)
+	}
+}
+
+func TestS3BlobStoreServerIgnoringRange(t *testing.T) {
+	store, fake := newTestS3Store(t, testSecretAccessKey)
+	fake.ignoreRange = true
+	ctx := context.Background()
+	content := []byte("hello, ranged world")
+	if err := store.Put(ctx, "a/b.txt", bytes.NewReader(content), int64(len(content)), ""); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	object, err := store.Open(ctx, "a/b.txt")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer object.Close()
+	if _, err := object.Seek(7, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	rest, err := io.ReadAll(object)
+	if err != nil || string(rest) != "ranged world" {
+		t.Fatalf("read %q, %v", rest, err)
+	}
+}
+
+func TestS3BlobStoreRejectsBadSignatureAndKeys(t *testing.T) {
+	store, _ := newTestS3Store(t, "wrong-secret")
+	ctx := context.Background()
+	err := store.Put(ctx, "a.txt", strings.NewReader("x"), 1, "text/plain")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Put with a wrong secret: %v, want a 403 error", err)
+	}
+
+	for _, key := range []string{"", "/etc/passwd", "../outside", "a/../../b", "a//b"} {
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("Put accepted invalid key %q", key)
+		}
+	}
+}
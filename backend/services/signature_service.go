@@ -0,0 +1,52 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+)
+
+// SignatureService defines the interface for signing and verifying approval records
+type SignatureService interface {
+	Sign(payload string) string
+	Verify(payload, signature string) bool
+}
+
+// HMACSignatureService implements SignatureService using HMAC-SHA256
+type HMACSignatureService struct {
+	secretKey []byte
+}
+
+// NewHMACSignatureService creates a new HMACSignatureService.
+// It uses SIGNATURE_SECRET_KEY and falls back to JWT_SECRET_KEY.
+func NewHMACSignatureService() (*HMACSignatureService, error) {
+	secretKey := os.Getenv("SIGNATURE_SECRET_KEY")
+	if secretKey == "" {
+		secretKey = os.Getenv("JWT_SECRET_KEY")
+	}
+	if secretKey == "" {
+		return nil, errors.New("neither SIGNATURE_SECRET_KEY nor JWT_SECRET_KEY environment variable is set")
+	}
+
+	return &HMACSignatureService{secretKey: []byte(secretKey)}, nil
+}
+
+// Sign returns the hex-encoded HMAC of the payload
+func (s *HMACSignatureService) Sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secretKey)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that the signature matches the payload
+func (s *HMACSignatureService) Verify(payload, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, s.secretKey)
+	mac.Write([]byte(payload))
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,124 @@
+// frontend/src/lib/attachments.ts
+// Calls to the attachments API: /api/attachments for listing, uploading and deleting, and
+// /api/files/{id} for downloading.
+import { PUBLIC_VITE_API_BASE_URL } from '$env/static/public';
+import { getAccessToken } from './authService';
+import type { Attachment } from './types';
+
+export type AttachmentEntityType = Attachment['entity_type'];
+
+export const attachmentEntityNames: Record<AttachmentEntityType, string> = {
+	requisition: 'Requisition',
+	tender: 'Tender',
+	bid: 'Bid',
+	purchase_order: 'Purchase Order',
+	invoice: 'Invoice'
+};
+
+function authHeaders(): Record<string, string> {
+	const token = getAccessToken();
+	if (!token) {
+		throw new Error('Authentication error. Please log in again.');
+	}
+	return { Authorization: `Bearer ${token}` };
+}
+
+// errorFrom reads the backend's {"error": "..."} body, falling back to the status text.
+async function errorFrom(response: Response, action: string): Promise<Error> {
+	try {
+		const body = await response.json();
+		return new Error(body.error || `Failed to ${action}: ${response.statusText}`);
+	} catch {
+		return new Error(`Failed to ${action}: ${response.statusText}`);
+	}
+}
+
+// listAttachments returns one entity's attachments when entityType and entityId are given;
+// otherwise every attachment the user may see (all of them for officers and admins, their own
+// uploads for everyone else).
+export async function listAttachments(
+	filters: { entityType?: AttachmentEntityType; entityId?: number; documentType?: string } = {}
+): Promise<Attachment[]> {
+	const params = new URLSearchParams();
+	if (filters.entityType && filters.entityId) {
+		params.set('entity_type', filters.entityType);
+		params.set('entity_id', String(filters.entityId));
+	}
+	if (filters.documentType) {
+		params.set('document_type', filters.documentType);
+	}
+	const query = params.toString();
+	const response = await fetch(`${PUBLIC_VITE_API_BASE_URL}/api/attachments${query ? `?${query}` : ''}`, {
+		headers: authHeaders()
+	});
+	if (!response.ok) {
+		throw await errorFrom(response, 'retrieve documents');
+	}
+	return (await response.json()) ?? [];
+}
+
+// uploadAttachments attaches files to an entity in one request; they share the document type.
+export async function uploadAttachments(
+	entityType: AttachmentEntityType,
+	entityId: number,
+	files: File[],
+	documentType?: string,
+	description?: string
+): Promise<Attachment[]> {
+	const form = new FormData();
+	form.append('entity_type', entityType);
+	form.append('entity_id', String(entityId));
+	if (documentType) form.append('document_type', documentType);
+	if (description) form.append('description', description);
+	for (const file of files) {
+		form.append('files', file);
+	}
+	const response = await fetch(`${PUBLIC_VITE_API_BASE_URL}/api/attachments`, {
+		method: 'POST',
+		headers: authHeaders(),
+		body: form
+	});
+	if (!response.ok) {
+		throw await errorFrom(response, 'upload documents');
+	}
+	return response.json();
+}
+
+export async function deleteAttachment(id: number): Promise<void> {
+	const response = await fetch(`${PUBLIC_VITE_API_BASE_URL}/api/attachments/${id}`, {
+		method: 'DELETE',
+		headers: authHeaders()
+	});
+	if (!response.ok) {
+		throw await errorFrom(response, 'delete document');
+	}
+}
+
+// downloadAttachment fetches a file with the user's token and hands it to the browser to save.
+export async function downloadAttachment(attachment: Attachment): Promise<void> {
+	const response = await fetch(`${PUBLIC_VITE_API_BASE_URL}/api/files/${attachment.id}`, {
+		headers: authHeaders()
+	});
+	if (!response.ok) {
+		throw await errorFrom(response, 'download document');
+	}
+	const url = URL.createObjectURL(await response.blob());
+	const link = document.createElement('a');
+	link.href = url;
+	link.download = attachment.file_name;
+	link.click();
+	// Released once the browser has started the download
+	setTimeout(() => URL.revokeObjectURL(url), 1000);
+}
+
+// attachmentEntityLink is the page of the entity an attachment belongs to, when it has one.
+export function attachmentEntityLink(attachment: Attachment): string | null {
+	switch (attachment.entity_type) {
+		case 'requisition':
+			return `/requisitions/${attachment.entity_id}`;
+		case 'tender':
+			return `/tenders/${attachment.entity_id}`;
+		default:
+			return null;
+	}
+}
@@ -6,13 +6,23 @@ export interface Supplier {
   contact_person?: string | null;
   email?: string | null;
   phone?: string | null;
+  registration_number?: string | null;
+  tax_id?: string | null;
+  address?: string | null;
+  status?: 'pending_verification' | 'active' | 'inactive' | 'rejected';
+  rejection_reason?: string | null;
+  bank_name?: string | null;
+  bank_branch?: string | null;
+  bank_account_name?: string | null;
+  bank_account_number?: string | null;
+  categories?: { supplier_id: number; category: string }[];
   // Add other fields from your Go model if they are exposed to the frontend
 }
 
 export interface Asset {
   id: number; // from int64
-  amr_id?: number | null; // from *int64
-  emr_id?: number | null; // from *int64
+  amr_id: string; // asset management register number
+  emr_id?: string | null; // from *string
   description: string;
   category?: string | null;
   status?: string | null;
@@ -52,14 +62,52 @@ export interface Requisition {
   title?: string;
   description?: string;
   // Add other fields from your backend model as needed
-  approver_one_id?: number | null;
-  approved_one_at?: string | null;
-  approver_two_id?: number | null;
-  approved_two_at?: string | null;
+  department?: string | null;
+  total_value?: number;
+  approval_chain_id?: number | null;
+  current_approval_level?: number; // Level awaiting a decision; 0 once decided
+  approval_chain?: ApprovalChain;
+  approval_steps?: ApprovalStep[];
+  can_decide?: boolean; // Whether the current user may approve or reject the current level
   rejection_reason?: string | null;
   items: RequisitionItem[];
 }
 
+export interface ApprovalChainLevel {
+  id: number;
+  chain_id: number;
+  level: number;
+  name?: string | null;
+  approver_role?: string | null;
+  approver_user_ids?: number[];
+}
+
+export interface ApprovalChain {
+  id: number;
+  name: string;
+  description?: string | null;
+  priority: number; // Lower priorities are tried first
+  requisition_type?: string | null;
+  department?: string | null;
+  min_total_value?: number | null;
+  max_total_value?: number | null;
+  is_active: boolean;
+  created_at: string;
+  updated_at: string;
+  levels: ApprovalChainLevel[];
+}
+
+export interface ApprovalStep {
+  id: number;
+  requisition_id: number;
+  level: number;
+  approver_id: number;
+  decision: 'approved' | 'rejected';
+  comment?: string | null;
+  decided_at: string;
+  approver?: User;
+}
+
 export interface RequisitionItem {
   id?: number; // Optional if it's a new item not yet saved
   description: string;
@@ -98,11 +146,17 @@ export interface Tender {
   description?: string | null;
   category?: string | null;
   budget?: number | null; // from *float64
-  status?: string | null; // e.g., 'draft', 'published', 'evaluation', 'awarded', 'cancelled'
+  status?: 'draft' | 'published' | 'closed' | 'evaluation' | 'awarded' | 'cancelled' | null; // Changed only through the transition endpoints
   published_date?: string | null; // from *time.Time
   closing_date?: string | null; // from *time.Time
   evaluation_method?: string | null;
   bid_opening_date?: string | null; // from *time.Time
+  clarification_deadline?: string | null; // Last moment for clarification questions; the closing date if unset
+  closed_at?: string | null; // from *time.Time
+  evaluation_started_at?: string | null; // from *time.Time
+  awarded_at?: string | null; // from *time.Time
+  cancelled_at?: string | null; // from *time.Time
+  cancellation_reason?: string | null;
   created_by_user_id?: number | null; // from *int64
   created_at?: string | null; // from *time.Time
   updated_at?: string | null; // from *time.Time
@@ -119,16 +173,42 @@ export interface Bid {
   submission_date: string; // from time.Time
   bid_amount?: number | null; // from *float64
   currency?: string | null; // e.g., 'USD', 'EUR'
-  status: string; // e.g., 'submitted', 'under_evaluation', 'shortlisted', 'rejected', 'awarded'
+  status: string; // e.g., 'submitted', 'under_evaluation', 'shortlisted', 'rejected', 'awarded', 'withdrawn', 'superseded'
+  version?: number; // 1 for a supplier's first bid on the tender, incremented by each resubmission
+  replaces_bid_id?: number | null; // Previous version this bid superseded
+  withdrawn_at?: string | null;
   notes?: string | null;
   validity_period_days?: number | null; // from *int
   items?: BidItem[]; // Array of bid items with detailed specifications
   created_at?: string | null; // from *time.Time
   updated_at?: string | null; // from *time.Time
+  supplier_scorecard?: SupplierScorecard; // Attached when listing a tender's bids
+  sealed?: boolean; // Amounts and item prices are withheld until bid opening
   // Potentially an array of bid documents
   // documents?: Array<{ name: string; url: string; type: string }>;
 }
 
+export interface SupplierScorecard {
+  supplier_id: number;
+  period_start: string;
+  period_end: string;
+  deliveries: number;
+  deliveries_with_due_date: number;
+  on_time_deliveries: number;
+  on_time_delivery_percent: number | null;
+  inspected_deliveries: number;
+  rejected_deliveries: number;
+  rejection_rate_percent: number | null;
+  invoices: number;
+  invoices_with_variance: number;
+  invoice_variance_percent: number | null;
+  ratings: number;
+  average_timeliness: number | null;
+  average_quality: number | null;
+  average_compliance: number | null;
+  average_rating: number | null;
+}
+
 export interface Evaluation {
   id: number; // from int64
   bid_id: number; // from int64
@@ -151,3 +231,87 @@ export interface User {
   created_at: string; // Or Date
   updated_at: string; // Or Date
 }
+
+export interface Attachment {
+  id: number;
+  entity_type: 'requisition' | 'tender' | 'bid' | 'purchase_order' | 'invoice';
+  entity_id: number;
+  file_name: string;
+  content_type: string;
+  size: number;
+  document_type?: string | null;
+  description?: string | null;
+  uploaded_by_user_id?: number | null;
+  upload_date: string;
+  sealed?: boolean; // Encrypted bid document; downloadable after bid opening
+}
+
+// Signed, expiring download link returned by POST /api/files/{id}/link; url is relative to the API base.
+export interface FileLink {
+  url: string;
+  expires_at: string;
+}
+
+// Formal opening of a tender's bids, from GET /api/tenders/{id}/opening.
+export interface BidOpening {
+  id: number;
+  tender_id: number;
+  evaluation_panel_id: number;
+  status: 'awaiting_quorum' | 'opened';
+  quorum: number; // Panel members that must join before the bids open
+  opened_at?: string | null;
+  register_digest?: string | null;
+  created_at: string;
+  attendees?: BidOpeningAttendee[]; // Not shown to suppliers
+  register?: BidOpeningRegisterEntry[];
+}
+
+export interface BidOpeningAttendee {
+  bid_opening_id: number;
+  user_id: number;
+  joined_at: string;
+}
+
+export interface BidOpeningRegisterEntry {
+  id: number;
+  bid_opening_id: number;
+  bid_id: number;
+  supplier_id: number;
+  supplier_name: string;
+  bid_amount: number;
+  received_at: string;
+  documents: string[]; // "document type: file name"
+}
+
+// Numbered change to a published tender, from GET /api/tenders/{id}/addenda.
+export interface TenderAddendum {
+  id: number;
+  tender_id: number;
+  number: number;
+  summary: string;
+  changes: TenderAddendumChange[];
+  issued_by_id: number;
+  issued_at: string;
+  acknowledged?: boolean; // Supplier users only: whether their organisation acknowledged it
+}
+
+export interface TenderAddendumChange {
+  field: string; // Tender field name, e.g. 'closing_date'
+  old_value: string | null;
+  new_value: string | null;
+}
+
+// Clarification question and answer, from GET /api/tenders/{id}/clarifications.
+// Suppliers never see who asked or answered; `own` marks their organisation's questions.
+export interface TenderClarification {
+  id: number;
+  tender_id: number;
+  question: string;
+  asked_by_supplier_id?: number | null;
+  asked_by_id?: number | null;
+  asked_at: string;
+  answer?: string | null;
+  answered_by_id?: number | null;
+  answered_at?: string | null;
+  own?: boolean;
+}